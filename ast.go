@@ -0,0 +1,246 @@
+package ticktickrules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Term is a node in the expression of a single field of a rule. It is one of WildcardTerm, ValueTerm, RangeTerm,
+// StepTerm, ListTerm, or LastTerm.
+type Term interface {
+	// String renders the term in standard cron syntax.
+	String() string
+	term()
+}
+
+// WildcardTerm matches any value, written as "*" or "?".
+type WildcardTerm struct{}
+
+// ValueTerm matches a single value. Names such as "JAN" are given as their number.
+type ValueTerm struct {
+	Value int
+}
+
+// RangeTerm matches the values from From to To inclusive, written as "From-To".
+type RangeTerm struct {
+	From, To int
+}
+
+// StepTerm matches every Every-th value of Base, written as "Base/Every". Base is a WildcardTerm, RangeTerm, or a
+// ValueTerm meaning the values from it to the end of the field.
+type StepTerm struct {
+	Base  Term
+	Every int
+}
+
+// ListTerm matches any of its terms. It is written with commas in standard syntax, and with "/" between single
+// values in the native syntax of this package.
+type ListTerm []Term
+
+// LastTerm matches Offset days before the last day of the month, written as "L" or "L-Offset". It is only used for
+// the day of month.
+type LastTerm struct {
+	Offset int
+}
+
+func (WildcardTerm) term() {}
+func (ValueTerm) term()    {}
+func (RangeTerm) term()    {}
+func (StepTerm) term()     {}
+func (ListTerm) term()     {}
+func (LastTerm) term()     {}
+
+func (WildcardTerm) String() string { return "*" }
+
+func (t ValueTerm) String() string { return strconv.Itoa(t.Value) }
+
+func (t RangeTerm) String() string { return strconv.Itoa(t.From) + "-" + strconv.Itoa(t.To) }
+
+func (t StepTerm) String() string { return t.Base.String() + "/" + strconv.Itoa(t.Every) }
+
+func (t LastTerm) String() string {
+	if t.Offset > 0 {
+		return "L-" + strconv.Itoa(t.Offset)
+	}
+	return "L"
+}
+
+func (t ListTerm) String() string {
+	parts := make([]string, len(t))
+	for i, term := range t {
+		parts[i] = term.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// FieldExpr is the parsed expression of a single field of a rule.
+type FieldExpr struct {
+	// Field is the name of the field, such as "Minute".
+	Field string
+	// Source is the item as it was given.
+	Source string
+	Expr   Term
+	// Nth is the occurrence within the month for a "N#K" day of week, or 0.
+	Nth int
+	// Last is set for a "NL" day of week, the last occurrence within the month.
+	Last bool
+}
+
+// String renders the expression in standard cron syntax.
+func (e FieldExpr) String() string {
+	if e.Last {
+		return e.Expr.String() + "L"
+	} else if e.Nth > 0 {
+		return e.Expr.String() + "#" + strconv.Itoa(e.Nth)
+	}
+	return e.Expr.String()
+}
+
+// AST returns the parsed expressions of the minute, hour, day of month, month, and day of week fields of the rule
+// in that order, so that tools can analyse or transform a rule without parsing its string themselves.
+func (r *Rule) AST() []FieldExpr {
+	items := []string{r.minuteRule, r.hourRule, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule}
+	out := make([]FieldExpr, len(items))
+	for i, f := range ruleFields {
+		e, err := parseFieldExpr(items[i], f)
+		if err != nil {
+			// rules made by NewRule always parse, but rules from other sources such as UnmarshalBinary are
+			// described by their values instead
+			e = FieldExpr{Field: f.name, Source: items[i], Expr: valuesTerm(expandField(r.fieldValues(i), f.min, f.max))}
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// fieldValues returns the expanded values of the i-th field.
+func (r *Rule) fieldValues(i int) []int {
+	return [][]int{r.minute, r.hour, r.dayOfMonth, r.month, r.dayOfWeek}[i]
+}
+
+// valuesTerm returns a term matching exactly the given values.
+func valuesTerm(values []int) Term {
+	if len(values) == 1 {
+		return ValueTerm{values[0]}
+	}
+	out := make(ListTerm, len(values))
+	for i, v := range values {
+		out[i] = ValueTerm{v}
+	}
+	return out
+}
+
+// parseFieldExpr parses an item into its expression. This accepts the forms of both the native and standard cron
+// syntax: comma separated lists, "a-b" ranges, steps of wildcards and ranges, and the native "N/M/O" lists of
+// single values. Values are checked to be numbers or names, but not that they are in range.
+func parseFieldExpr(item string, f field) (FieldExpr, error) {
+	out := FieldExpr{Field: f.name, Source: item}
+	body := item
+	if i := strings.Index(item, "#"); i >= 0 && f.name == dayOfWeekField.name {
+		nth, err := strconv.Atoi(item[i+1:])
+		if err != nil {
+			return out, &SyntaxError{Item: item, Reason: "must have an occurrence between 1 and 5"}
+		}
+		out.Nth = nth
+		body = item[:i]
+	} else if len(item) > 1 && strings.HasSuffix(item, "L") && f.name == dayOfWeekField.name {
+		out.Last = true
+		body = item[:len(item)-1]
+	}
+
+	if f.name == dayOfMonthField.name && (item == "L" || strings.HasPrefix(item, "L-")) {
+		var last LastTerm
+		if item != "L" {
+			v, err := strconv.Atoi(item[2:])
+			if err != nil {
+				return out, &SyntaxError{Item: item, Reason: "could not be parsed"}
+			}
+			last.Offset = v
+		}
+		out.Expr = last
+		return out, nil
+	}
+
+	var terms ListTerm
+	for _, part := range strings.Split(body, ",") {
+		t, err := parseTerm(part, item, f)
+		if err != nil {
+			return out, err
+		}
+		terms = append(terms, t)
+	}
+	out.Expr = terms
+	if len(terms) == 1 {
+		out.Expr = terms[0]
+	}
+	return out, nil
+}
+
+// parseTerm parses one comma separated part of an item.
+func parseTerm(part, item string, f field) (Term, error) {
+	if part == "*" || (part == "?" && f.question) {
+		return WildcardTerm{}, nil
+	}
+	slash := strings.Index(part, "/")
+	if slash < 0 {
+		return parseRange(part, item, f)
+	}
+
+	// a "/" after a wildcard or range is a step, otherwise it separates a native list of values
+	base := part[:slash]
+	if base == "*" || (base == "?" && f.question) || strings.Contains(base, "-") {
+		every, err := strconv.Atoi(part[slash+1:])
+		if err != nil {
+			return nil, &SyntaxError{Item: item, Reason: "has an invalid step"}
+		}
+		b, err := parseTerm(base, item, f)
+		if err != nil {
+			return nil, err
+		}
+		return StepTerm{Base: b, Every: every}, nil
+	}
+	var list ListTerm
+	for _, v := range strings.Split(part, "/") {
+		t, err := parseRange(v, item, f)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, nil
+}
+
+// parseRange parses a single value or an "a-b" range.
+func parseRange(part, item string, f field) (Term, error) {
+	if i := strings.Index(part, "-"); i > 0 {
+		from, err := parseName(part[:i], item, f)
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseName(part[i+1:], item, f)
+		if err != nil {
+			return nil, err
+		}
+		return RangeTerm{From: from, To: to}, nil
+	}
+	v, err := parseName(part, item, f)
+	if err != nil {
+		return nil, err
+	}
+	return ValueTerm{v}, nil
+}
+
+// parseName parses a number or a name of the field.
+func parseName(s, item string, f field) (int, error) {
+	if !isNumber(s) {
+		s = replaceNames(s, f.names, f.offset)
+	}
+	if !isNumber(s) {
+		return 0, &SyntaxError{Item: item, Reason: "could not be parsed"}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &SyntaxError{Item: item, Reason: "could not be parsed"}
+	}
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package ticktickrules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAST(t *testing.T) {
+	ast := MustParseRule("*/15 9/17 ? JAN/JUL MON#2").AST()
+	if len(ast) != 5 {
+		t.Fatalf("%d != 5", len(ast))
+	}
+
+	if s, ok := ast[0].Expr.(StepTerm); !ok || s.Every != 15 || s.Base != (WildcardTerm{}) {
+		t.Errorf("%#v Did not match!", ast[0].Expr)
+	}
+	if l, ok := ast[1].Expr.(ListTerm); !ok || len(l) != 2 || l[0] != (ValueTerm{9}) || l[1] != (ValueTerm{17}) {
+		t.Errorf("%#v Did not match!", ast[1].Expr)
+	}
+	if _, ok := ast[2].Expr.(WildcardTerm); !ok || ast[2].Source != "?" {
+		t.Errorf("%#v Did not match!", ast[2])
+	}
+	if l, ok := ast[3].Expr.(ListTerm); !ok || l.String() != "1,7" {
+		t.Errorf("%#v Did not match!", ast[3].Expr)
+	}
+	if ast[4].Expr != (ValueTerm{1}) || ast[4].Nth != 2 || ast[4].Field != "Day of Week" {
+		t.Errorf("%#v Did not match!", ast[4])
+	}
+
+	var parts []string
+	for _, e := range ast {
+		parts = append(parts, e.String())
+	}
+	if s := strings.Join(parts, " "); s != "*/15 9,17 * 1,7 1#2" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestParseFieldExpr(t *testing.T) {
+	cases := map[string]string{
+		"*":       "*",
+		"5":       "5",
+		"1-5":     "1-5",
+		"1-5/2":   "1-5/2",
+		"1,3,5":   "1,3,5",
+		"0/30":    "0,30",
+		"*/10,45": "*/10,45",
+		"MON-FRI": "1-5",
+		"SUN/SAT": "0,6",
+		"2#3":     "2#3",
+		"5L":      "5L",
+		"x":       "",
+		"1-x":     "",
+		"*/x":     "",
+		"1#x":     "",
+	}
+	for item, e := range cases {
+		expr, err := parseFieldExpr(item, dayOfWeekField)
+		if e == "" {
+			if err == nil {
+				t.Errorf("%s: should have failed", item)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", item, err)
+		} else if expr.String() != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", item, expr.String(), e)
+		}
+	}
+
+	if expr, err := parseFieldExpr("L-3", dayOfMonthField); err != nil || expr.Expr != (LastTerm{3}) {
+		t.Errorf("%#v %v", expr, err)
+	}
+}
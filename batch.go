@@ -0,0 +1,65 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// localMoment is a time broken down in one of the locations used by a batch of rules.
+type localMoment struct {
+	loc        *time.Location
+	moment     moment
+	secondPass bool
+}
+
+// MatchAny returns the indexes of the rules that match the given time, in order. The time is broken down once
+// for each distinct location the rules are bound to rather than once per rule, which makes this cheaper than
+// calling Matches on each rule when checking many rules every minute. A RuleSet can be passed directly.
+func MatchAny(rules []*Rule, t time.Time) []int {
+	var out []int
+	var locals []localMoment
+	for i, r := range rules {
+		loc := t.Location()
+		if r.location != nil {
+			loc = r.location
+		}
+
+		var local *localMoment
+		for j := range locals {
+			if locals[j].loc == loc {
+				local = &locals[j]
+				break
+			}
+		}
+		if local == nil {
+			lt := t.In(loc)
+			locals = append(locals, localMoment{loc: loc, moment: newMoment(lt), secondPass: isSecondPass(lt)})
+			local = &locals[len(locals)-1]
+		}
+
+		if r.matchesMoment(&local.moment, local.secondPass) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// NextAcross returns the earliest time after from at which any of the rules match, along with the indexes of all
+// of the rules that match at that time. If none of the rules ever match again, the zero time and no indexes are
+// returned. A RuleSet can be passed directly.
+func NextAcross(rules []*Rule, from time.Time) (time.Time, []int) {
+	next := farFuture
+	var out []int
+	for i, r := range rules {
+		n := r.NextAfter(from)
+		if n.Before(next) {
+			next, out = n, out[:0]
+		}
+		if n.Equal(next) && !n.Equal(farFuture) {
+			out = append(out, i)
+		}
+	}
+	if len(out) == 0 {
+		return time.Time{}, nil
+	}
+	return next, out
+}
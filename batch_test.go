@@ -0,0 +1,75 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMatchAny(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	rules := RuleSet{
+		MustParseRule("*/15 * * * *"),
+		MustParseRule("0 12 * * *"),
+		MustParseRule("0 7 * * *").In(loc),
+		MustParseRule("0 12 * * 0"),
+		MustParseRule("0 8 * * *").In(loc),
+	}
+	now := time.Date(2026, time.June, 3, 12, 0, 0, 0, time.UTC)
+	if s := fmt.Sprint(MatchAny(rules, now)); s != "[0 1 4]" {
+		t.Errorf("'%s' Did not match! '[0 1 4]'", s)
+	}
+	for i, r := range rules {
+		matched := false
+		for _, j := range MatchAny(rules, now) {
+			matched = matched || i == j
+		}
+		if matched != r.Matches(now) {
+			t.Errorf("%d: %v != %v", i, matched, r.Matches(now))
+		}
+	}
+	if m := MatchAny(rules, now.Add(time.Minute)); len(m) != 0 {
+		t.Errorf("%v Did not match!", m)
+	}
+	if m := MatchAny(nil, now); len(m) != 0 {
+		t.Errorf("%v Did not match!", m)
+	}
+}
+
+func TestNextAcross(t *testing.T) {
+	rules := []*Rule{
+		MustParseRule("0 12 * * *"),
+		MustParseRule("0 */6 * * *"),
+		MustParseRule("30 11 * * *"),
+		MustParseRule("0 0 30 2 *"),
+	}
+	from := time.Date(2026, time.June, 3, 11, 30, 0, 0, time.UTC)
+	next, which := NextAcross(rules, from)
+	if e := time.Date(2026, time.June, 3, 12, 0, 0, 0, time.UTC); !next.Equal(e) {
+		t.Errorf("%s != %s", next, e)
+	}
+	if s := fmt.Sprint(which); s != "[0 1]" {
+		t.Errorf("'%s' Did not match! '[0 1]'", s)
+	}
+
+	next, which = NextAcross(rules[3:], from)
+	if !next.IsZero() || which != nil {
+		t.Errorf("%s %v Did not match!", next, which)
+	}
+}
+
+func BenchmarkMatchAny(b *testing.B) {
+	var rules []*Rule
+	for i := 0; i < 1000; i++ {
+		rules = append(rules, MustParseRule(fmt.Sprintf("%d %d * * *", i%60, i%24)))
+	}
+	t := time.Date(2026, time.March, 4, 10, 15, 0, 0, time.UTC)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		MatchAny(rules, t)
+	}
+}
@@ -0,0 +1,89 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+// benchmarkRules range from a rule matching every minute to rules matching once a year or less, so that the cost
+// of the searches can be compared between dense and sparse schedules.
+var benchmarkRules = []struct {
+	name string
+	expr string
+}{
+	{"EveryMinute", "* * * * *"},
+	{"Hourly", "0 * * * *"},
+	{"WorkingHours", "*/15 9-17 * * MON-FRI"},
+	{"Monthly", "0 0 L * *"},
+	{"Yearly", "0 0 1 1 *"},
+	{"LeapDay", "0 0 29 2 *"},
+	{"FifthMonday", "30 2 * * MON#5"},
+}
+
+var benchmarkFrom = time.Date(2026, time.March, 4, 10, 15, 30, 0, time.UTC)
+
+func BenchmarkRuleParse(b *testing.B) {
+	for _, c := range benchmarkRules {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				ParseRule(c.expr)
+			}
+		})
+	}
+}
+
+func BenchmarkRuleMatches(b *testing.B) {
+	for _, c := range benchmarkRules {
+		r := MustParseRule(c.expr)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Matches(benchmarkFrom.Add(time.Duration(i%1440) * time.Minute))
+			}
+		})
+	}
+}
+
+func BenchmarkRuleNextAfter(b *testing.B) {
+	for _, c := range benchmarkRules {
+		r := MustParseRule(c.expr)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.NextAfter(benchmarkFrom.Add(time.Duration(i%1440) * time.Minute))
+			}
+		})
+	}
+}
+
+func BenchmarkRuleFloor(b *testing.B) {
+	for _, c := range benchmarkRules {
+		r := MustParseRule(c.expr)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.Floor(benchmarkFrom.Add(time.Duration(i%1440) * time.Minute))
+			}
+		})
+	}
+}
+
+// BenchmarkRuleNextAfterZone is like BenchmarkRuleNextAfter but for rules bound to a location with daylight
+// saving, starting shortly before the clocks change.
+func BenchmarkRuleNextAfterZone(b *testing.B) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		b.Skip(err)
+	}
+	from := time.Date(2026, time.March, 28, 23, 0, 0, 0, loc)
+	for _, c := range benchmarkRules {
+		r := MustParseRule(c.expr).In(loc)
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				r.NextAfter(from.Add(time.Duration(i%1440) * time.Minute))
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package ticktickrules
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// binaryVersion is written as the first byte of the binary encoding so that the format can change later. Version 2
+// added the "L-N" day of month, version 3 the day matching semantics and granularity, and version 4 the search
+// horizon. Encodings of the earlier versions can still be read.
+const binaryVersion = 4
+
+// binaryLast is stored in place of the occurrence for a "NL" day of week.
+const binaryLast = 0xff
+
+// binaryEitherDay is set in the flags byte for rules matching when either of the day fields match.
+const binaryEitherDay = 1 << 0
+
+// MarshalBinary implements encoding.BinaryMarshaler. The expanded fields are stored as bitmasks alongside the
+// original rule strings, so that rules can be loaded again without re-parsing. This also makes Rule usable with
+// encoding/gob. An error is returned for rules with a calendar or the other restrictions added with the With
+// methods, which cannot be encoded.
+func (r *Rule) MarshalBinary() ([]byte, error) {
+	if s := r.restriction(); s != "" {
+		return nil, fmt.Errorf("Rule '%s' has %s, which cannot be encoded", r, s)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(binaryVersion)
+	for _, v := range []interface{}{
+		uint64(bitmask(r.minute)),
+		uint32(bitmask(r.hour)),
+		uint32(bitmask(r.dayOfMonth)),
+		uint16(bitmask(r.month)),
+		uint8(bitmask(r.dayOfWeek)),
+		nthByte(r),
+		uint8(r.fallBack),
+		uint8(r.springForward),
+		lastDayByte(r),
+		flagsByte(r),
+		uint8(r.granularity),
+		int64(r.searchHorizon),
+	} {
+		binary.Write(&buf, binary.BigEndian, v)
+	}
+	for _, s := range []string{r.minuteRule, r.hourRule, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule} {
+		writeString(&buf, s)
+	}
+
+	// locations are stored by name, along with their offset when they never change their clocks so that fixed
+	// zones can be recreated
+	if r.location == nil {
+		writeString(&buf, "")
+	} else {
+		writeString(&buf, r.location.String())
+		start, end := time.Now().In(r.location).ZoneBounds()
+		_, offset := time.Now().In(r.location).Zone()
+		if start.IsZero() && end.IsZero() {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+		binary.Write(&buf, binary.BigEndian, int32(offset))
+	}
+	return buf.Bytes(), nil
+}
+
+// nthByte returns the byte stored for the occurrence of the day of week within the month.
+func nthByte(r *Rule) uint8 {
+	if r.dayOfWeekLast {
+		return binaryLast
+	}
+	return uint8(r.dayOfWeekNth)
+}
+
+// lastDayByte returns the byte stored for a "L-N" day of month, which is N+1, or 0 if the rule has none.
+func lastDayByte(r *Rule) uint8 {
+	if r.dayOfMonthLast {
+		return uint8(r.dayOfMonthOffset + 1)
+	}
+	return 0
+}
+
+// flagsByte returns the byte stored for the boolean settings of the rule.
+func flagsByte(r *Rule) uint8 {
+	var out uint8
+	if r.eitherDay {
+		out |= binaryEitherDay
+	}
+	return out
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, replacing the rule with one written by MarshalBinary. An
+// error is returned for values out of range, so that a corrupted encoding is not loaded as a rule that never matches.
+func (r *Rule) UnmarshalBinary(data []byte) error {
+	buf := bytes.NewReader(data)
+	version, err := buf.ReadByte()
+	if err != nil {
+		return err
+	} else if version < 1 || version > binaryVersion {
+		return fmt.Errorf("Unsupported binary rule version %d", version)
+	}
+
+	var minute uint64
+	var hour, dayOfMonth uint32
+	var month uint16
+	var dayOfWeek, dayOfWeekNth, fallBack, springForward, lastDay, flags, granularity uint8
+	var searchHorizon int64
+	fields := []interface{}{&minute, &hour, &dayOfMonth, &month, &dayOfWeek, &dayOfWeekNth, &fallBack, &springForward}
+	if version >= 2 {
+		fields = append(fields, &lastDay)
+	}
+	if version >= 3 {
+		fields = append(fields, &flags, &granularity)
+	}
+	if version >= 4 {
+		fields = append(fields, &searchHorizon)
+	}
+	for _, v := range fields {
+		if err := binary.Read(buf, binary.BigEndian, v); err != nil {
+			return err
+		}
+	}
+
+	var out Rule
+	out.minute = fromBitmask(minute)
+	out.hour = fromBitmask(uint64(hour))
+	out.dayOfMonth = fromBitmask(uint64(dayOfMonth))
+	out.month = fromBitmask(uint64(month))
+	out.dayOfWeek = fromBitmask(uint64(dayOfWeek))
+	if dayOfWeekNth == binaryLast {
+		out.dayOfWeekLast = true
+	} else {
+		out.dayOfWeekNth = int(dayOfWeekNth)
+	}
+	if lastDay > 0 {
+		out.dayOfMonthLast, out.dayOfMonthOffset = true, int(lastDay)-1
+	}
+	out.fallBack = FallBackPolicy(fallBack)
+	out.springForward = SpringForwardPolicy(springForward)
+	out.eitherDay = flags&binaryEitherDay != 0
+	out.granularity = Granularity(granularity)
+	out.searchHorizon = time.Duration(searchHorizon)
+	if err := checkDecoded(&out, flags); err != nil {
+		return err
+	}
+	out.buildMasks()
+	for _, s := range []*string{&out.minuteRule, &out.hourRule, &out.dayOfMonthRule, &out.monthRule, &out.dayOfWeekRule} {
+		if *s, err = readString(buf); err != nil {
+			return err
+		}
+	}
+
+	name, err := readString(buf)
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		fixed, err := buf.ReadByte()
+		if err != nil {
+			return err
+		}
+		var offset int32
+		if err := binary.Read(buf, binary.BigEndian, &offset); err != nil {
+			return err
+		}
+		if out.location, err = time.LoadLocation(name); err != nil {
+			if fixed == 0 {
+				return err
+			}
+			out.location = time.FixedZone(name, int(offset))
+		}
+	}
+
+	*r = out
+	return nil
+}
+
+// checkDecoded returns an error if any of the values decoded by UnmarshalBinary are out of range.
+func checkDecoded(r *Rule, flags uint8) error {
+	for _, fv := range []struct {
+		f      field
+		values []int
+	}{
+		{minuteField, r.minute},
+		{hourField, r.hour},
+		{dayOfMonthField, r.dayOfMonth},
+		{monthField, r.month},
+		{dayOfWeekField, r.dayOfWeek},
+	} {
+		for _, v := range fv.values {
+			if v < fv.f.min || v > fv.f.max {
+				return fmt.Errorf("Binary rule has %s %d out of range %d-%d", fv.f.name, v, fv.f.min, fv.f.max)
+			}
+		}
+	}
+	switch {
+	case r.dayOfWeekNth > 5:
+		return fmt.Errorf("Binary rule has day of week occurrence %d out of range 1-5", r.dayOfWeekNth)
+	case r.dayOfMonthOffset > dayOfMonthField.max-1:
+		return fmt.Errorf("Binary rule has day of month offset %d out of range 0-%d", r.dayOfMonthOffset, dayOfMonthField.max-1)
+	case r.fallBack < FireEarliest || r.fallBack > FireTwice:
+		return fmt.Errorf("Binary rule has unknown fall back policy %d", r.fallBack)
+	case r.springForward < SkipMissing || r.springForward > ShiftMissing:
+		return fmt.Errorf("Binary rule has unknown spring forward policy %d", r.springForward)
+	case r.granularity < MinuteGranularity || r.granularity > SecondGranularity:
+		return fmt.Errorf("Binary rule has unknown granularity %d", r.granularity)
+	case flags&^binaryEitherDay != 0:
+		return fmt.Errorf("Binary rule has unknown flags %#x", flags)
+	}
+	return nil
+}
+
+// bitmask returns the values as a set of bits. An empty set of values, which matches anything, has no bits set.
+func bitmask(values []int) uint64 {
+	var out uint64
+	for _, v := range values {
+		out |= 1 << uint(v)
+	}
+	return out
+}
+
+// fromBitmask returns the values set in the bitmask in ascending order.
+func fromBitmask(mask uint64) []int {
+	var out []int
+	for i := 0; i < 64; i++ {
+		if mask&(1<<uint(i)) != 0 {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	var length [binary.MaxVarintLen64]byte
+	buf.Write(length[:binary.PutUvarint(length[:], uint64(len(s)))])
+	buf.WriteString(s)
+}
+
+func readString(buf *bytes.Reader) (string, error) {
+	length, err := binary.ReadUvarint(buf)
+	if err != nil {
+		return "", err
+	}
+	if length > uint64(buf.Len()) {
+		return "", io.ErrUnexpectedEOF
+	}
+	out := make([]byte, length)
+	if _, err := io.ReadFull(buf, out); err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
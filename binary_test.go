@@ -0,0 +1,175 @@
+package ticktickrules
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+	"time"
+)
+
+func TestMarshalBinary(t *testing.T) {
+	for _, r := range []*Rule{
+		MustParseRule("* * * * *"),
+		MustParseRule("10/20/30 */5 1/15 JAN/JUL MON#2"),
+		MustParseRule("0 17 * * FRIL"),
+		MustParseRule("0 0 L-3 * *"),
+		MustParseRule("CRON_TZ=Europe/London 0 9 * * 1/5").WithFallBackPolicy(FireTwice),
+		MustParseRule("0 9 * * *").In(time.FixedZone("India", 5*3600+30*60)),
+		MustParseRule("30 * * * *").WithGranularity(SecondGranularity),
+		MustParseRule("0 0 29 2 *").WithSearchHorizon(48 * time.Hour),
+	} {
+		data, err := r.MarshalBinary()
+		if err != nil {
+			t.Error(err.Error())
+			continue
+		}
+		out := new(Rule)
+		if err := out.UnmarshalBinary(data); err != nil {
+			t.Errorf("%s: %s", r, err.Error())
+			continue
+		}
+		if out.String() != r.String() || out.Fingerprint() != r.Fingerprint() {
+			t.Errorf("'%s' != '%s'", out.StringNormalized(), r.StringNormalized())
+		}
+		if out.SearchHorizon() != r.SearchHorizon() {
+			t.Errorf("%s horizon %s != %s", r, out.SearchHorizon(), r.SearchHorizon())
+		}
+		from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+		if !out.NextAfter(from).Equal(r.NextAfter(from)) {
+			t.Errorf("%s next %s != %s", r, out.NextAfter(from), r.NextAfter(from))
+		}
+	}
+}
+
+func TestMarshalBinaryEitherDay(t *testing.T) {
+	// Kubernetes fires on the 13th or on a Friday, rather than only on Friday the 13th
+	r, err := ParseKubernetes("0 0 13 * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := r.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := new(Rule)
+	if err := out.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if n := out.NextAfter(from); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if out.Fingerprint() != r.Fingerprint() {
+		t.Errorf("%s != %s", out.Fingerprint(), r.Fingerprint())
+	}
+}
+
+func TestUnmarshalBinaryVersion2(t *testing.T) {
+	r := MustParseRule("0 0 L-3 * MON")
+	data, _ := r.MarshalBinary()
+
+	// version 2 had no flags, granularity or search horizon after the "L-N" day of month
+	old := append([]byte{2}, data[1:24]...)
+	old = append(old, data[34:]...)
+	out := new(Rule)
+	if err := out.UnmarshalBinary(old); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != r.String() || out.Fingerprint() != r.Fingerprint() {
+		t.Errorf("'%s' != '%s'", out.StringNormalized(), r.StringNormalized())
+	}
+}
+
+func TestUnmarshalBinaryVersion3(t *testing.T) {
+	r, err := ParseKubernetes("0 0 13 * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := r.MarshalBinary()
+
+	// version 3 had no search horizon after the granularity
+	old := append([]byte{3}, data[1:26]...)
+	old = append(old, data[34:]...)
+	out := new(Rule)
+	if err := out.UnmarshalBinary(old); err != nil {
+		t.Fatal(err)
+	}
+	if out.Fingerprint() != r.Fingerprint() || out.SearchHorizon() != DefaultSearchHorizon {
+		t.Errorf("'%s' != '%s'", out.StringNormalized(), r.StringNormalized())
+	}
+}
+
+func TestMarshalBinaryRestrictions(t *testing.T) {
+	r := MustParseRule("0 0 * * *")
+	for _, x := range []*Rule{
+		r.WithCalendar(NewHolidays()),
+		mustRule(r.WithISOWeeks(1)),
+		mustRule(r.WithWeeksOfMonth(2)),
+		mustRule(r.WithBusinessDay(1)),
+	} {
+		if _, err := x.MarshalBinary(); err == nil {
+			t.Errorf("%s should not have been encoded", x)
+		}
+	}
+}
+
+func TestUnmarshalBinaryOutOfRange(t *testing.T) {
+	data, err := MustParseRule("0 0 * * MON").MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// offsets into the encoding and a corrupt value for the byte there
+	for _, c := range []struct {
+		name   string
+		offset int
+		value  byte
+	}{
+		{"minute bit 63", 1, 0x80},
+		{"hour bit 24", 9, 0x01},
+		{"day of month bit 0", 16, 0x01},
+		{"month bit 0", 18, 0x03},
+		{"day of week bit 7", 19, 0x82},
+		{"nth", 20, 9},
+		{"fall back", 21, 9},
+		{"spring forward", 22, 9},
+		{"day of month offset", 23, 32},
+		{"flags", 24, 0x80},
+		{"granularity", 25, 9},
+	} {
+		corrupt := append([]byte(nil), data...)
+		corrupt[c.offset] = c.value
+		if err := new(Rule).UnmarshalBinary(corrupt); err == nil {
+			t.Errorf("%s should not have been decoded", c.name)
+		}
+	}
+}
+
+func TestUnmarshalBinaryTruncated(t *testing.T) {
+	data, _ := MustParseRule("CRON_TZ=Europe/London 0 9 * * *").MarshalBinary()
+	for i := 0; i < len(data); i++ {
+		if err := new(Rule).UnmarshalBinary(data[:i]); err == nil {
+			t.Errorf("%d bytes should have failed", i)
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	type job struct {
+		Name string
+		Rule *Rule
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(job{Name: "backup", Rule: MustParseRule("0 2 * * *")}); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	var out job
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if out.Name != "backup" || out.Rule.String() != "0 2 * * *" {
+		t.Errorf("%+v Did not match!", out)
+	}
+}
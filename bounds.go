@@ -0,0 +1,80 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// FirstInMonth returns the first occurrence of the rule in the given month, in the location the rule is bound to
+// or UTC. Only the days allowed by the day of month field are checked, rather than stepping through the month.
+// False is returned if the rule does not fire in the month.
+func (r *Rule) FirstInMonth(year int, month time.Month) (time.Time, bool) {
+	return r.boundInMonth(year, month, false)
+}
+
+// LastInMonth is like FirstInMonth but returns the last occurrence in the month.
+func (r *Rule) LastInMonth(year int, month time.Month) (time.Time, bool) {
+	return r.boundInMonth(year, month, true)
+}
+
+// FirstInYear returns the first occurrence of the rule in the given year, checking only the months allowed by the
+// month field. False is returned if the rule does not fire in the year.
+func (r *Rule) FirstInYear(year int) (time.Time, bool) {
+	for _, m := range expandField(r.month, monthField.min, monthField.max) {
+		if t, ok := r.FirstInMonth(year, time.Month(m)); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LastInYear is like FirstInYear but returns the last occurrence in the year.
+func (r *Rule) LastInYear(year int) (time.Time, bool) {
+	months := expandField(r.month, monthField.min, monthField.max)
+	for i := len(months) - 1; i >= 0; i-- {
+		if t, ok := r.LastInMonth(year, time.Month(months[i])); ok {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// boundInMonth returns the first or last occurrence in the month.
+func (r *Rule) boundInMonth(year int, month time.Month, last bool) (time.Time, bool) {
+	if !hasBit(r.masks.month, int(month)) {
+		return time.Time{}, false
+	}
+	loc := r.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+	days := expandField(r.dayOfMonth, dayOfMonthField.min, civilDate(year, month+1, 0).Day())
+	if r.eitherDay && len(r.dayOfMonth) > 0 {
+		// days matching only the day of week are also allowed
+		days = expandField(nil, dayOfMonthField.min, civilDate(year, month+1, 0).Day())
+	}
+
+	for i := range days {
+		if last {
+			i = len(days) - 1 - i
+		}
+		date := civilDate(year, month, days[i])
+		if date.Month() != month || !r.matchesDay(date) {
+			continue
+		}
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
+		}
+		instants := r.dayInstants(date, loc, hours, minutes)
+		if len(instants) == 0 {
+			continue
+		}
+		if last {
+			return instants[len(instants)-1], true
+		}
+		return instants[0], true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,58 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFirstAndLastInMonth(t *testing.T) {
+	cases := []struct {
+		rule        string
+		month       time.Month
+		first, last string
+	}{
+		{"0 9 * * 1/2/3/4/5", time.March, "2026-03-02T09:00:00Z", "2026-03-31T09:00:00Z"},
+		{"*/15 8/17 * * *", time.February, "2026-02-01T08:00:00Z", "2026-02-28T17:45:00Z"},
+		{"0 0 31 * *", time.April, "", ""},
+		{"0 0 29/30/31 * *", time.February, "", ""},
+		{"0 12 * JAN *", time.March, "", ""},
+		{"30 6 * * 5#2", time.October, "2026-10-09T06:30:00Z", "2026-10-09T06:30:00Z"},
+		{"CRON_TZ=Europe/London 30 1 29 3 *", time.March, "", ""},
+		{"CRON_TZ=Europe/London 0 12 1/31 * *", time.March, "2026-03-01T12:00:00Z", "2026-03-31T11:00:00Z"},
+	}
+	for _, c := range cases {
+		r := MustParseRule(c.rule)
+		for i, fn := range []func(int, time.Month) (time.Time, bool){r.FirstInMonth, r.LastInMonth} {
+			e := []string{c.first, c.last}[i]
+			n, ok := fn(2026, c.month)
+			if ok != (e != "") {
+				t.Errorf("%s %d: %v Did not match! %v", c.rule, i, ok, e != "")
+			} else if ok && n.UTC().Format(time.RFC3339) != e {
+				t.Errorf("%s %d: '%s' Did not match! '%s'", c.rule, i, n.UTC().Format(time.RFC3339), e)
+			}
+		}
+	}
+}
+
+func TestFirstAndLastInYear(t *testing.T) {
+	r := MustParseRule("0 0 13 * 5")
+	first, ok := r.FirstInYear(2026)
+	if e := time.Date(2026, time.February, 13, 0, 0, 0, 0, time.UTC); !ok || !first.Equal(e) {
+		t.Errorf("%s != %s", first, e)
+	}
+	last, ok := r.LastInYear(2026)
+	if e := time.Date(2026, time.November, 13, 0, 0, 0, 0, time.UTC); !ok || !last.Equal(e) {
+		t.Errorf("%s != %s", last, e)
+	}
+	if _, ok := MustParseRule("0 0 30 2 *").FirstInYear(2026); ok {
+		t.Error("should not have found an occurrence")
+	}
+	if _, ok := MustParseRule("0 0 30 2 *").LastInYear(2026); ok {
+		t.Error("should not have found an occurrence")
+	}
+
+	k, _ := ParseKubernetes("0 0 13 * 5")
+	if first, _ := k.FirstInYear(2026); !first.Equal(time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s Did not match!", first)
+	}
+}
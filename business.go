@@ -0,0 +1,54 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"time"
+)
+
+// LastBusinessDay can be passed to WithBusinessDay to match the last business day of the month.
+const LastBusinessDay = -1
+
+// maxBusinessDays is the most weekdays there can be in a month.
+const maxBusinessDays = 23
+
+// WithBusinessDay returns a copy of the rule that only fires on the n-th business day of the month. Business days
+// are Monday to Friday, excluding any days excluded by the rule's calendar. Negative values count back from the
+// end of the month, so LastBusinessDay (-1) is the last business day and -2 the one before it. Zero removes the
+// restriction. An error is returned if n is beyond the 23 weekdays a month can have.
+func (r *Rule) WithBusinessDay(n int) (*Rule, error) {
+	if n < -maxBusinessDays || n > maxBusinessDays {
+		return nil, fmt.Errorf("Business day %d is out of range -%d to %d", n, maxBusinessDays, maxBusinessDays)
+	}
+	out := *r
+	out.businessDay = n
+	return &out, nil
+}
+
+// isBusinessDay returns whether the date of t is a weekday that is not excluded by the rule's calendar.
+func (r *Rule) isBusinessDay(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return r.calendar == nil || !r.calendar.IsExcluded(t)
+}
+
+// matchesBusinessDay returns whether the date of t is the business day of the month selected by the rule.
+func (r *Rule) matchesBusinessDay(t time.Time) bool {
+	if !r.isBusinessDay(t) {
+		return false
+	}
+	year, month, day := t.Date()
+	step, want := -1, r.businessDay
+	if want < 0 {
+		step, want = 1, -want
+	}
+
+	// count the business days between this one and the start or end of the month
+	n := 1
+	for d := civilDate(year, month, day+step); d.Month() == month; d = d.AddDate(0, 0, step) {
+		if r.isBusinessDay(d) {
+			n++
+		}
+	}
+	return n == want
+}
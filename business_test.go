@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithBusinessDayLast(t *testing.T) {
+	r := mustRule(MustParseRule("0 18 * * *").WithBusinessDay(LastBusinessDay))
+	from := time.Date(2000, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	expected := []time.Time{
+		// april 30th is a sunday
+		time.Date(2000, 4, 28, 18, 0, 0, 0, time.UTC),
+		time.Date(2000, 5, 31, 18, 0, 0, 0, time.UTC),
+		time.Date(2000, 6, 30, 18, 0, 0, 0, time.UTC),
+		// july 31st is a monday
+		time.Date(2000, 7, 31, 18, 0, 0, 0, time.UTC),
+	}
+	for i, e := range expected {
+		from = r.NextAfter(from)
+		if from != e {
+			t.Errorf("%d) %s != %s", i, from, e)
+		}
+	}
+}
+
+func TestWithBusinessDayHolidays(t *testing.T) {
+	holidays := NewHolidays(
+		time.Date(2000, 12, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC),
+	)
+	last := mustRule(MustParseRule("0 9 * * *").WithCalendar(holidays).WithBusinessDay(LastBusinessDay))
+	first := mustRule(last.WithBusinessDay(1))
+	third := mustRule(last.WithBusinessDay(3))
+	from := time.Date(2000, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	n := last.NextAfter(from)
+	e := time.Date(2000, 12, 28, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = first.NextAfter(n)
+	e = time.Date(2001, 1, 2, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	n = third.NextAfter(n)
+	e = time.Date(2001, 1, 4, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("3) %s != %s", n, e)
+	}
+}
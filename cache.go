@@ -0,0 +1,79 @@
+package ticktickrules
+
+import (
+	"sync"
+)
+
+// DefaultCacheSize is the number of expressions held by the cache used by Compile.
+const DefaultCacheSize = 1024
+
+// RuleCache holds parsed rules keyed by their expression, so that hot paths parsing the same expressions
+// repeatedly only parse each of them once. It is safe for concurrent use. Rules are never modified once parsed, as
+// the With methods all return copies, so the same rule can be shared between callers.
+type RuleCache struct {
+	mu    sync.RWMutex
+	size  int
+	rules map[string]*Rule
+}
+
+// NewRuleCache returns a cache holding at most size expressions. Once full, an arbitrary expression is evicted to
+// make room for each new one.
+func NewRuleCache(size int) *RuleCache {
+	if size < 1 {
+		size = 1
+	}
+	return &RuleCache{size: size, rules: make(map[string]*Rule)}
+}
+
+// Compile returns the rule for the expression, parsing it with ParseRule if it is not already cached. Invalid
+// expressions are not cached.
+func (c *RuleCache) Compile(expr string) (*Rule, error) {
+	c.mu.RLock()
+	r, ok := c.rules[expr]
+	c.mu.RUnlock()
+	if ok {
+		return r, nil
+	}
+
+	r, err := ParseRule(expr)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if existing, ok := c.rules[expr]; ok {
+		return existing, nil
+	}
+	if len(c.rules) >= c.size {
+		for k := range c.rules {
+			delete(c.rules, k)
+			break
+		}
+	}
+	c.rules[expr] = r
+	return r, nil
+}
+
+// Len returns the number of cached expressions.
+func (c *RuleCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.rules)
+}
+
+var defaultCache = NewRuleCache(DefaultCacheSize)
+
+// Compile is like ParseRule but caches the parsed rules in a package level cache of DefaultCacheSize
+// expressions. The returned rule may be shared with other callers.
+func Compile(expr string) (*Rule, error) {
+	return defaultCache.Compile(expr)
+}
+
+// MustCompile is like Compile but panics if there is an error parsing the expression.
+func MustCompile(expr string) *Rule {
+	r, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
@@ -0,0 +1,70 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestRuleCache(t *testing.T) {
+	c := NewRuleCache(2)
+	a, err := c.Compile("0 12 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := c.Compile("0 12 * * *"); a != b {
+		t.Error("expected the cached rule to be returned")
+	}
+	if _, err := c.Compile("0 12 * *"); err == nil {
+		t.Error("expected an error")
+	}
+	if c.Len() != 1 {
+		t.Errorf("%d != 1", c.Len())
+	}
+	c.Compile("0 13 * * *")
+	c.Compile("0 14 * * *")
+	if c.Len() != 2 {
+		t.Errorf("%d != 2", c.Len())
+	}
+}
+
+func TestRuleCacheConcurrent(t *testing.T) {
+	c := NewRuleCache(10)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				expr := fmt.Sprintf("%d * * * *", (i+j)%20)
+				if r, err := c.Compile(expr); err != nil || r.String() != expr {
+					t.Errorf("%s: %v %v", expr, r, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if c.Len() > 10 {
+		t.Errorf("%d > 10", c.Len())
+	}
+}
+
+func TestCompile(t *testing.T) {
+	if MustCompile("*/5 * * * *") != MustCompile("*/5 * * * *") {
+		t.Error("expected the cached rule to be returned")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic")
+		}
+	}()
+	MustCompile("bad")
+}
+
+func BenchmarkCompile(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Compile("*/5 9/10/11/12 * * 1/2/3/4/5")
+	}
+}
@@ -0,0 +1,45 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Calendar decides which days a rule is allowed to fire on, such as skipping public holidays. Only the date
+// returned by t.Date() is meaningful; the time of day and location should be ignored.
+type Calendar interface {
+	IsExcluded(t time.Time) bool
+}
+
+// CalendarFunc adapts an ordinary function to the Calendar interface.
+type CalendarFunc func(t time.Time) bool
+
+// IsExcluded calls f(t).
+func (f CalendarFunc) IsExcluded(t time.Time) bool {
+	return f(t)
+}
+
+// Holidays is a Calendar excluding a fixed set of dates.
+type Holidays map[time.Time]bool
+
+// NewHolidays returns a Calendar excluding the dates of each of the given times.
+func NewHolidays(dates ...time.Time) Holidays {
+	out := make(Holidays, len(dates))
+	for _, d := range dates {
+		out[civilDate(d.Date())] = true
+	}
+	return out
+}
+
+// IsExcluded returns whether the date of t is one of the holidays.
+func (h Holidays) IsExcluded(t time.Time) bool {
+	return h[civilDate(t.Date())]
+}
+
+// WithCalendar returns a copy of the rule that does not fire on any days excluded by the calendar. The calendar is
+// not included in the String form of the rule, and the binary, proto, and dialect forms return an error for rules
+// with a calendar. See Fingerprint for how calendars are told apart.
+func (r *Rule) WithCalendar(cal Calendar) *Rule {
+	out := *r
+	out.calendar = cal
+	return &out
+}
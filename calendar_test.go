@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithCalendar(t *testing.T) {
+	holidays := NewHolidays(
+		time.Date(2000, 12, 25, 0, 0, 0, 0, time.UTC),
+		time.Date(2000, 12, 26, 0, 0, 0, 0, time.UTC),
+	)
+	r := MustParseRule("0 9 * * 1/2/3/4/5").WithCalendar(holidays)
+
+	n := r.NextAfter(time.Date(2000, 12, 22, 10, 0, 0, 0, time.UTC))
+	e := time.Date(2000, 12, 27, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+	if r.Matches(time.Date(2000, 12, 25, 9, 0, 0, 0, time.UTC)) {
+		t.Error("Christmas should not match")
+	}
+
+	p := r.Floor(time.Date(2000, 12, 26, 12, 0, 0, 0, time.UTC))
+	e = time.Date(2000, 12, 22, 9, 0, 0, 0, time.UTC)
+	if p != e {
+		t.Errorf("%s != %s", p, e)
+	}
+
+	c := r.CountBetween(time.Date(2000, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC))
+	if c != 19 {
+		t.Errorf("%d != 19", c)
+	}
+}
+
+func TestCalendarFunc(t *testing.T) {
+	// exclude the first week of every month
+	cal := CalendarFunc(func(t time.Time) bool {
+		return t.Day() <= 7
+	})
+	r := MustParseRule("0 0 * * 1").In(mustLoadLocation(t, "America/New_York")).WithCalendar(cal)
+
+	n := r.NextAfter(time.Date(2000, 4, 28, 0, 0, 0, 0, time.UTC))
+	e := time.Date(2000, 5, 8, 4, 0, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+
+	// the original rule is untouched
+	if r.WithCalendar(nil).NextAfter(time.Date(2000, 4, 28, 0, 0, 0, 0, time.UTC)).Day() != 1 {
+		t.Error("Rule without calendar should fire on the 1st")
+	}
+}
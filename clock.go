@@ -0,0 +1,170 @@
+package ticktickrules
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time used by a Scheduler. It can be replaced to test schedules without sleeping.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTimer returns a timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is a single use timer created by a Clock.
+type Timer interface {
+	// C returns the channel the time is sent on when the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, and returns false if it already fired or was stopped.
+	Stop() bool
+}
+
+// SystemClock is the Clock backed by the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+// WaitNext blocks until the next occurrence of the schedule according to the clock, and returns it. Like the
+// Scheduler, it rechecks the wall clock at least every DefaultMaxSleep so that clock jumps are noticed. It returns
+// early with the context's error if ctx is cancelled.
+func WaitNext(ctx context.Context, c Clock, s Schedule) (time.Time, error) {
+	next := s.NextAfter(wallClock(c.Now()))
+	for {
+		sleep := next.Sub(wallClock(c.Now()))
+		if sleep <= 0 {
+			return next, nil
+		}
+		if sleep > DefaultMaxSleep {
+			sleep = DefaultMaxSleep
+		}
+		timer := c.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return time.Time{}, ctx.Err()
+		case <-timer.C():
+		}
+	}
+}
+
+// FakeClock is a Clock that only moves when told to, for testing code that uses schedules without sleeping.
+type FakeClock struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	c     chan time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTimer returns a timer that fires when the clock has been advanced by d.
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{clock: c, at: c.now.Add(d), c: make(chan time.Time, 1)}
+	c.timers = append(c.timers, t)
+	c.fire()
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance moves the clock forwards by d, firing any timers that become due.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// Step moves the wall clock by d without firing any timers, as happens when the clock is corrected by NTP or the
+// machine wakes from suspend.
+func (c *FakeClock) Step(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for _, t := range c.timers {
+		t.at = t.at.Add(d)
+	}
+}
+
+// BlockUntil waits until at least n timers are waiting to fire. This lets a test wait for the code under test to
+// go to sleep before advancing the clock.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fire sends on the channels of any timers that are due and forgets them.
+func (c *FakeClock) fire() {
+	n := 0
+	for _, t := range c.timers {
+		if !t.at.After(c.now) {
+			t.c <- c.now
+		} else {
+			c.timers[n] = t
+			n++
+		}
+	}
+	c.timers = c.timers[:n]
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, other := range c.timers {
+		if other == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
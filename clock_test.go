@@ -0,0 +1,62 @@
+package ticktickrules
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var (
+	_ Clock = SystemClock
+	_ Clock = new(FakeClock)
+)
+
+func TestNextUTCWith(t *testing.T) {
+	loc := mustLoadLocation(t, "America/New_York")
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, loc))
+	r := MustParseRule("0 * * * *")
+
+	n := r.NextUTCWith(c)
+	e := time.Date(2000, 4, 28, 19, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+	if d := r.UntilNextWith(c); d != 31*time.Minute+18*time.Second {
+		t.Errorf("%s != 31m18s", d)
+	}
+}
+
+func TestWaitNext(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	done := make(chan time.Time)
+	go func() {
+		n, err := WaitNext(context.Background(), c, MustParseRule("30 14 * * *"))
+		if err != nil {
+			t.Error(err.Error())
+		}
+		done <- n
+	}()
+
+	for i := 0; i < 2; i++ {
+		c.BlockUntil(1)
+		c.Advance(time.Minute)
+	}
+	select {
+	case n := <-done:
+		e := time.Date(2000, 4, 28, 14, 30, 0, 0, time.UTC)
+		if n != e {
+			t.Errorf("%s != %s", n, e)
+		}
+	case <-time.After(time.Second):
+		t.Error("WaitNext did not return")
+	}
+}
+
+func TestWaitNextCancelled(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := WaitNext(ctx, c, MustParseRule("30 14 * * *")); err != context.Canceled {
+		t.Errorf("%v != %v", err, context.Canceled)
+	}
+}
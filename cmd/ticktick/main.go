@@ -0,0 +1,137 @@
+// Command ticktick evaluates cron-like expressions using the ticktickrules package. It is intended for testing
+// schedules from the command line without writing any Go.
+//
+// Usage:
+//
+//	ticktick next "<expression>" [-n count] [-tz zone] [-from time]
+//	ticktick match "<expression>" [-tz zone] [-at time]
+//	ticktick describe "<expression>" [-tz zone]
+//	ticktick validate "<expression>"
+//
+// Times are given and printed in RFC3339 format.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/AstromechZA/ticktickrules"
+)
+
+const usage = `Usage:
+  ticktick next "<expression>" [-n count] [-tz zone] [-from time]
+  ticktick match "<expression>" [-tz zone] [-at time]
+  ticktick describe "<expression>" [-tz zone]
+  ticktick validate "<expression>"
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// command holds the flags shared by the subcommands.
+type command struct {
+	flags *flag.FlagSet
+	count int
+	zone  string
+	at    string
+	expr  string
+}
+
+func newCommand(name string, stderr io.Writer) *command {
+	c := &command{flags: flag.NewFlagSet(name, flag.ContinueOnError)}
+	c.flags.SetOutput(stderr)
+	c.flags.IntVar(&c.count, "n", 5, "number of occurrences to print")
+	c.flags.StringVar(&c.zone, "tz", "UTC", "time zone to evaluate the expression in")
+	c.flags.StringVar(&c.at, "at", "", "time to evaluate at in RFC3339 format (default now)")
+	c.flags.StringVar(&c.at, "from", "", "alias for -at")
+	return c
+}
+
+// parse reads the flags and the single expression argument, which may appear before or after the flags.
+func (c *command) parse(args []string) error {
+	if err := c.flags.Parse(args); err != nil {
+		return err
+	}
+	if c.flags.NArg() > 0 {
+		c.expr = c.flags.Arg(0)
+		if err := c.flags.Parse(c.flags.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if c.expr == "" || c.flags.NArg() > 0 {
+		return fmt.Errorf("expected exactly one expression argument")
+	}
+	return nil
+}
+
+func (c *command) time() (time.Time, error) {
+	loc, err := time.LoadLocation(c.zone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if c.at == "" {
+		return time.Now().In(loc), nil
+	}
+	t, err := time.Parse(time.RFC3339, c.at)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.In(loc), nil
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	c := newCommand(args[0], stderr)
+	if err := c.parse(args[1:]); err != nil {
+		fmt.Fprintf(stderr, "%s\n%s", err, usage)
+		return 2
+	}
+
+	rule, err := ticktickrules.ParseRule(c.expr, ticktickrules.Lenient())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		for _, s := range ticktickrules.SuggestCorrections(c.expr) {
+			fmt.Fprintf(stderr, "hint: %s\n", s)
+		}
+		return 1
+	}
+	now, err := c.time()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	switch args[0] {
+	case "next":
+		t := now
+		for i := 0; i < c.count; i++ {
+			t = rule.NextAfter(t)
+			fmt.Fprintln(stdout, t.Format(time.RFC3339))
+		}
+	case "match":
+		matches := rule.Matches(now)
+		fmt.Fprintln(stdout, matches)
+		if !matches {
+			return 1
+		}
+	case "describe":
+		f := rule.Frequency()
+		fmt.Fprintf(stdout, "expression: %s\n", rule)
+		fmt.Fprintf(stdout, "next:       %s\n", rule.NextAfter(now).Format(time.RFC3339))
+		fmt.Fprintf(stdout, "interval:   %s (min %s, max %s)\n", f.Typical, f.Min, f.Max)
+	case "validate":
+		fmt.Fprintln(stdout, "valid")
+	default:
+		fmt.Fprintf(stderr, "unknown command '%s'\n%s", args[0], usage)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunNext(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"next", "*/5 * * * *", "-n", "2", "-tz", "UTC", "-from", "2000-04-28T14:28:42Z"}, &stdout, &stderr)
+	if code != 0 {
+		t.Errorf("exit code %d: %s", code, stderr.String())
+		return
+	}
+	e := "2000-04-28T14:30:00Z\n2000-04-28T14:35:00Z\n"
+	if stdout.String() != e {
+		t.Errorf("'%s' != '%s'", stdout.String(), e)
+	}
+}
+
+func TestRunMatch(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"match", "-at", "2000-04-28T14:30:00Z", "30 14 * * *"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit code %d: %s", code, stderr.String())
+	}
+	if code := run([]string{"match", "-at", "2000-04-28T14:31:00Z", "30 14 * * *"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d should be 1", code)
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"validate", "0 0 * * *"}, &stdout, &stderr); code != 0 {
+		t.Errorf("exit code %d: %s", code, stderr.String())
+	}
+	if code := run([]string{"validate", "60 0 * * *"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d should be 1", code)
+	}
+	if code := run([]string{"validate"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code %d should be 2", code)
+	}
+
+	stderr.Reset()
+	if code := run([]string{"validate", "0 0 9 * * *"}, &stdout, &stderr); code != 1 {
+		t.Errorf("exit code %d should be 1", code)
+	}
+	if !strings.Contains(stderr.String(), "hint: 6 fields given, did you mean to drop the seconds field: '0 9 * * *'") {
+		t.Errorf("'%s' Did not match!", stderr.String())
+	}
+}
@@ -0,0 +1,106 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// combineMaxIterations bounds how many candidate times are tried when searching a combined schedule.
+const combineMaxIterations = 100000
+
+// ceilSchedule returns t if the schedule matches it, otherwise the next time the schedule matches after t.
+func ceilSchedule(s Schedule, t time.Time) time.Time {
+	if s.Matches(t) {
+		return t
+	}
+	return s.NextAfter(t)
+}
+
+type orSchedule []Schedule
+
+// Or returns a schedule matching whenever any of the given schedules match.
+func Or(schedules ...Schedule) Schedule {
+	return orSchedule(schedules)
+}
+
+func (o orSchedule) NextAfter(from time.Time) time.Time {
+	next := farFuture
+	for _, s := range o {
+		if n := s.NextAfter(from); n.Before(next) {
+			next = n
+		}
+	}
+	return next
+}
+
+func (o orSchedule) Matches(t time.Time) bool {
+	for _, s := range o {
+		if s.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+type andSchedule []Schedule
+
+// And returns a schedule matching only when all of the given schedules match, for example
+// And(weekdays, Not(holidays)).
+func And(schedules ...Schedule) Schedule {
+	return andSchedule(schedules)
+}
+
+func (a andSchedule) NextAfter(from time.Time) time.Time {
+	if len(a) == 0 {
+		return farFuture
+	}
+
+	// leapfrog the schedules forwards until they all agree on a candidate
+	candidate := a[0].NextAfter(from)
+	for i := 0; i < combineMaxIterations && candidate.Before(farFuture); i++ {
+		next := candidate
+		for _, s := range a {
+			if n := ceilSchedule(s, candidate); n.After(next) {
+				next = n
+			}
+		}
+		if next.Equal(candidate) {
+			return candidate
+		}
+		candidate = next
+	}
+	return farFuture
+}
+
+func (a andSchedule) Matches(t time.Time) bool {
+	for _, s := range a {
+		if !s.Matches(t) {
+			return false
+		}
+	}
+	return len(a) > 0
+}
+
+type notSchedule struct {
+	schedule Schedule
+}
+
+// Not returns a schedule matching every minute that the given schedule does not match. It is most useful combined
+// with And to exclude blackout periods from another schedule.
+func Not(s Schedule) Schedule {
+	return notSchedule{schedule: s}
+}
+
+func (n notSchedule) NextAfter(from time.Time) time.Time {
+	t := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), from.Minute()+1, 0, 0, from.Location())
+	for i := 0; i < combineMaxIterations; i++ {
+		if !n.schedule.Matches(t) {
+			return t
+		}
+		t = t.Add(time.Minute)
+	}
+	return farFuture
+}
+
+func (n notSchedule) Matches(t time.Time) bool {
+	return !n.schedule.Matches(t)
+}
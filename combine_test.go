@@ -0,0 +1,59 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOr(t *testing.T) {
+	s := Or(MustParseRule("0 9 * * *"), At(time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC)))
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+
+	n := s.NextAfter(from)
+	e := time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = s.NextAfter(n)
+	e = time.Date(2000, 4, 29, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+}
+
+func TestAndNot(t *testing.T) {
+	// every 15 minutes on weekdays, except during the 09:00 hour
+	s := And(MustParseRule("*/15 * * * 1/2/3/4/5"), Not(MustParseRule("* 9 * * *")))
+
+	n := s.NextAfter(time.Date(2000, 4, 28, 8, 50, 0, 0, time.UTC))
+	e := time.Date(2000, 4, 28, 10, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	if !s.Matches(e) {
+		t.Errorf("%s should match", e)
+	}
+
+	// friday evening rolls over to monday
+	n = s.NextAfter(time.Date(2000, 4, 28, 23, 50, 0, 0, time.UTC))
+	e = time.Date(2000, 5, 1, 0, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+}
+
+func TestAndNever(t *testing.T) {
+	s := And(MustParseRule("0 * * * *"), MustParseRule("30 * * * *"))
+	if n := s.NextAfter(time.Now()); n.Year() < 3000 {
+		t.Errorf("%s should be far in the future", n)
+	}
+}
+
+func TestNot(t *testing.T) {
+	s := Not(MustParseRule("* 9 * * *"))
+	n := s.NextAfter(time.Date(2000, 4, 28, 9, 10, 30, 0, time.UTC))
+	e := time.Date(2000, 4, 28, 10, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+}
@@ -0,0 +1,166 @@
+// Package config loads a document of named schedules, so that a service can keep its schedules in a file rather
+// than in code. Documents are JSON:
+//
+//	{
+//	  "schedules": [
+//	    {"name": "backup", "expression": "30 2 * * *", "timezone": "Europe/London", "jitter": "10m"},
+//	    {"name": "invoices", "expression": "0 9 1 * *", "exclusions": ["2026-01-01"]}
+//	  ]
+//	}
+//
+// LoadYAML reads the same document written in YAML. The fields of Document decode from their lower case names
+// without struct tags, so documents in other formats can be decoded into a Document and passed to Build.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/AstromechZA/ticktickrules"
+)
+
+// Document is a set of named schedules.
+type Document struct {
+	Schedules []Schedule
+}
+
+// Schedule describes a single named schedule.
+type Schedule struct {
+	Name string
+	// Expression is parsed with ticktickrules.ParseRule and may have a "CRON_TZ=" prefix unless Timezone is given.
+	Expression string
+	// Timezone is the name of the location to evaluate the expression in, such as "Europe/London".
+	Timezone string
+	// Jitter is a duration such as "5m". Every occurrence is delayed by a fixed amount less than it, chosen from the
+	// name of the schedule, so that schedules sharing an expression are spread out while every instance of a
+	// service still agrees on when each one runs.
+	Jitter string
+	// Exclusions are dates in the form "2006-01-02" on which the schedule does not run.
+	Exclusions []string
+}
+
+// Errors lists every invalid schedule in a document.
+type Errors []error
+
+func (e Errors) Error() string {
+	parts := make([]string, len(e))
+	for i, err := range e {
+		parts[i] = err.Error()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Load decodes a JSON document from r and builds its schedules as Build does. Unknown fields are rejected so that
+// misspelt options are not silently ignored.
+func Load(r io.Reader) (map[string]ticktickrules.Schedule, error) {
+	var doc Document
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("Invalid schedule document: %s", err)
+	}
+	return Build(doc)
+}
+
+// LoadFile is like Load but reads the document from the named file.
+func LoadFile(path string) (map[string]ticktickrules.Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Build validates every schedule in the document and returns them by name. If any are invalid, an Errors listing
+// all of the problems is returned rather than only the first.
+func Build(doc Document) (map[string]ticktickrules.Schedule, error) {
+	out := make(map[string]ticktickrules.Schedule, len(doc.Schedules))
+	var errs Errors
+	for i, s := range doc.Schedules {
+		name := s.Name
+		if name == "" {
+			errs = append(errs, fmt.Errorf("Schedule %d has no name", i+1))
+			continue
+		}
+		if _, ok := out[name]; ok {
+			errs = append(errs, fmt.Errorf("Schedule '%s' is defined more than once", name))
+			continue
+		}
+		schedule, err := build(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Schedule '%s': %s", name, err))
+			continue
+		}
+		out[name] = schedule
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return out, nil
+}
+
+// build converts a single schedule.
+func build(s Schedule) (ticktickrules.Schedule, error) {
+	rule, err := ticktickrules.ParseRule(s.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if s.Timezone != "" {
+		if rule.Location() != nil {
+			return nil, fmt.Errorf("the time zone is given by both the expression and timezone")
+		}
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, err
+		}
+		rule = rule.In(loc)
+	}
+	if len(s.Exclusions) > 0 {
+		dates := make([]time.Time, len(s.Exclusions))
+		for i, d := range s.Exclusions {
+			if dates[i], err = time.Parse("2006-01-02", d); err != nil {
+				return nil, fmt.Errorf("exclusion '%s' is not a date in the form 2006-01-02", d)
+			}
+		}
+		rule = rule.WithCalendar(ticktickrules.NewHolidays(dates...))
+	}
+	if s.Jitter == "" {
+		return rule, nil
+	}
+	jitter, err := time.ParseDuration(s.Jitter)
+	if err != nil || jitter < 0 {
+		return nil, fmt.Errorf("jitter '%s' is not a positive duration", s.Jitter)
+	}
+	return jittered{Schedule: rule, offset: jitterOffset(s.Name, jitter)}, nil
+}
+
+// jitterOffset chooses a whole number of seconds less than jitter from a hash of the name.
+func jitterOffset(name string, jitter time.Duration) time.Duration {
+	seconds := int64(jitter / time.Second)
+	if seconds <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return time.Duration(h.Sum64()%uint64(seconds)) * time.Second
+}
+
+// jittered delays every occurrence of a schedule by a fixed offset.
+type jittered struct {
+	ticktickrules.Schedule
+	offset time.Duration
+}
+
+func (j jittered) NextAfter(from time.Time) time.Time {
+	return j.Schedule.NextAfter(from.Add(-j.offset)).Add(j.offset)
+}
+
+func (j jittered) Matches(t time.Time) bool {
+	return j.Schedule.Matches(t.Add(-j.offset))
+}
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	doc := `{
+		"schedules": [
+			{"name": "backup", "expression": "30 2 * * *", "timezone": "Europe/London"},
+			{"name": "invoices", "expression": "0 9 1 * *", "exclusions": ["2026-04-01"]},
+			{"name": "report", "expression": "0 * * * *", "jitter": "10m"}
+		]
+	}`
+	schedules, err := Load(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 3 {
+		t.Fatalf("%d != 3", len(schedules))
+	}
+
+	from := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	if n := schedules["backup"].NextAfter(from); !n.Equal(time.Date(2026, 3, 3, 2, 30, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2026-03-03T02:30:00Z", n)
+	}
+	if n := schedules["invoices"].NextAfter(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)); !n.Equal(time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2026-05-01T09:00:00Z", n)
+	}
+
+	// the jitter is the same every time and within the limit
+	report := schedules["report"]
+	offset := jitterOffset("report", 10*time.Minute)
+	n := report.NextAfter(from)
+	if e := from.Add(offset); offset == 0 || !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if offset >= 10*time.Minute || !report.Matches(n) || report.Matches(from) {
+		t.Errorf("%s is not a valid jitter", offset)
+	}
+	if next := report.NextAfter(n); !next.Equal(n.Add(time.Hour)) {
+		t.Errorf("%s != %s", next, n.Add(time.Hour))
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	doc := `{
+		"schedules": [
+			{"name": "ok", "expression": "0 * * * *"},
+			{"name": "bad", "expression": "0 25 * * *"},
+			{"expression": "0 * * * *"},
+			{"name": "ok", "expression": "0 * * * *"},
+			{"name": "zone", "expression": "CRON_TZ=UTC 0 * * * *", "timezone": "Europe/London"},
+			{"name": "jitter", "expression": "0 * * * *", "jitter": "soon"},
+			{"name": "holiday", "expression": "0 * * * *", "exclusions": ["Christmas"]}
+		]
+	}`
+	_, err := Load(strings.NewReader(doc))
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 6 {
+		t.Fatalf("%v should list 6 errors", err)
+	}
+	for i, e := range []string{"'bad'", "Schedule 3 has no name", "'ok' is defined more than once", "'zone'", "'jitter'", "'holiday'"} {
+		if !strings.Contains(errs[i].Error(), e) {
+			t.Errorf("'%s' Did not contain '%s'", errs[i], e)
+		}
+	}
+
+	if _, err := Load(strings.NewReader(`{"schedules": [{"name": "x", "expresion": "* * * * *"}]}`)); err == nil {
+		t.Error("expected an error for an unknown field")
+	}
+}
+
+func TestLoadYAML(t *testing.T) {
+	doc := `# schedules for the billing service
+schedules:
+  - name: backup
+    expression: "30 2 * * *"   # nightly
+    timezone: Europe/London
+  - name: 'invoices'
+    expression: 0 9 1 * *
+    exclusions: ["2026-04-01"]
+  -
+    name: report
+    expression: "0 * * * *"
+    jitter: 10m
+    exclusions:
+    - 2026-12-25
+    - "2026-12-26"
+`
+	schedules, err := LoadYAML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 3 {
+		t.Fatalf("%d != 3", len(schedules))
+	}
+	from := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	if n := schedules["backup"].NextAfter(from); !n.Equal(time.Date(2026, 3, 3, 2, 30, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2026-03-03T02:30:00Z", n)
+	}
+	if n := schedules["invoices"].NextAfter(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)); !n.Equal(time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2026-05-01T09:00:00Z", n)
+	}
+	report := schedules["report"].NextAfter(time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC))
+	if report.Before(time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s should be after the exclusions", report)
+	}
+
+	for _, bad := range []string{
+		"schedules:\n  - name: x\n    expresion: '* * * * *'\n",
+		"schedule:\n  - name: x\n",
+		"schedules:\n  - name: x\n    expression: *alias\n",
+		"schedules:\n  - name: x\n   expression: '* * * * *'\n",
+		"schedules:\n\t- name: x\n",
+		"schedules:\n  - name: [x]\n",
+		"schedules: x\n",
+	} {
+		if _, err := LoadYAML(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+
+	// every invalid schedule is listed, as with JSON
+	_, err = LoadYAML(strings.NewReader("schedules:\n  - name: a\n    expression: 0 25 * * *\n  - expression: 0 * * * *\n"))
+	if errs, ok := err.(Errors); !ok || len(errs) != 2 {
+		t.Errorf("%v should list 2 errors", err)
+	}
+}
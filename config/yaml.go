@@ -0,0 +1,357 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/AstromechZA/ticktickrules"
+)
+
+// LoadYAML is like Load but decodes a YAML document of the same shape:
+//
+//	schedules:
+//	  - name: backup
+//	    expression: "30 2 * * *"
+//	    timezone: Europe/London
+//	    jitter: 10m
+//	  - name: invoices
+//	    expression: "0 9 1 * *"
+//	    exclusions: ["2026-01-01"]
+//
+// Only the subset of YAML needed for schedule documents is supported: block mappings and sequences, flow sequences
+// of scalars, plain and quoted scalars, and comments. Anchors, tags, and multi-line scalars are rejected.
+func LoadYAML(r io.Reader) (map[string]ticktickrules.Schedule, error) {
+	lines, err := yamlLines(r)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid schedule document: %s", err)
+	}
+	var doc Document
+	if len(lines) > 0 {
+		p := &yamlParser{lines: lines}
+		v, err := p.block(lines[0].indent)
+		if err == nil && p.pos < len(lines) {
+			err = fmt.Errorf("line %d is not indented consistently", lines[p.pos].number)
+		}
+		if err == nil {
+			doc, err = yamlDocument(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Invalid schedule document: %s", err)
+		}
+	}
+	return Build(doc)
+}
+
+// LoadYAMLFile is like LoadYAML but reads the document from the named file.
+func LoadYAMLFile(path string) (map[string]ticktickrules.Schedule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadYAML(f)
+}
+
+// yamlLine is a line of a YAML document with its indentation and comment removed.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlLines reads the lines of a document that hold content.
+func yamlLines(r io.Reader) ([]yamlLine, error) {
+	var out []yamlLine
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := scanner.Text()
+		text := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d is indented with a tab", number)
+		}
+		text = strings.TrimSpace(stripYAMLComment(text))
+		if text == "" || (len(out) == 0 && text == "---") {
+			continue
+		}
+		if strings.ContainsAny(text[:1], "&*!|>{") {
+			return nil, fmt.Errorf("line %d uses unsupported YAML syntax", number)
+		}
+		out = append(out, yamlLine{number: number, indent: len(line) - len(strings.TrimLeft(line, " ")), text: text})
+	}
+	return out, scanner.Err()
+}
+
+// stripYAMLComment removes a comment, which starts with "#" at the start of the text or after a space, outside of
+// quotes.
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// yamlParser builds maps, slices, and strings from the lines of a document.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// block parses the mapping or sequence starting at the current line, which is indented by indent.
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if p.lines[p.pos].text == "-" || strings.HasPrefix(p.lines[p.pos].text, "- ") {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+// sequence parses the items of a block sequence indented by indent.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	var out []interface{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if line.text != "-" && !strings.HasPrefix(line.text, "- ") {
+			break
+		}
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		switch {
+		case item == "":
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		case isYAMLKey(item):
+			// a mapping starting on the same line as the dash continues at the indentation of its first key
+			inner := indent + len(line.text) - len(item)
+			p.lines[p.pos] = yamlLine{number: line.number, indent: inner, text: item}
+			v, err := p.mapping(inner)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		default:
+			v, err := yamlValue(item, line.number)
+			if err != nil {
+				return nil, err
+			}
+			p.pos++
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
+
+// mapping parses the keys of a block mapping indented by indent.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	out := make(map[string]interface{})
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if !isYAMLKey(line.text) {
+			return nil, fmt.Errorf("line %d is not a key and value", line.number)
+		}
+		i := strings.Index(line.text+" ", ": ")
+		key, err := yamlScalar(strings.TrimSpace(line.text[:i]), line.number)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := out[key]; ok {
+			return nil, fmt.Errorf("line %d repeats the key '%s'", line.number, key)
+		}
+		value := strings.TrimSpace(line.text[i+1:])
+		p.pos++
+		if value != "" {
+			v, err := yamlValue(value, line.number)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = v
+			continue
+		}
+		// a sequence under a key may be indented by the same amount as the key
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && strings.HasPrefix(p.lines[p.pos].text, "-") {
+			v, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = v
+			continue
+		}
+		v, err := p.nested(indent)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = v
+	}
+	return out, nil
+}
+
+// nested parses the block indented further than indent at the current line, or returns nil if there is none.
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+// isYAMLKey returns whether the text starts with a key followed by a colon.
+func isYAMLKey(text string) bool {
+	if text[0] == '"' || text[0] == '\'' || text[0] == '[' {
+		return false
+	}
+	return strings.Contains(text, ": ") || strings.HasSuffix(text, ":")
+}
+
+// yamlValue parses a scalar or a flow sequence of scalars.
+func yamlValue(text string, number int) (interface{}, error) {
+	if !strings.HasPrefix(text, "[") {
+		return yamlScalar(text, number)
+	}
+	if !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("line %d has an unterminated sequence", number)
+	}
+	out := []interface{}{}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if inner == "" {
+		return out, nil
+	}
+	var quote byte
+	start := 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) {
+			if c := inner[i]; quote != 0 {
+				if c == quote {
+					quote = 0
+				}
+				continue
+			} else if c == '"' || c == '\'' {
+				quote = c
+				continue
+			} else if c != ',' {
+				continue
+			}
+		}
+		v, err := yamlScalar(strings.TrimSpace(inner[start:i]), number)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+		start = i + 1
+	}
+	return out, nil
+}
+
+// yamlScalar parses a plain, single quoted, or double quoted scalar.
+func yamlScalar(text string, number int) (string, error) {
+	if text == "" || text == "~" || text == "null" {
+		return "", nil
+	}
+	switch text[0] {
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return "", fmt.Errorf("line %d has an invalid quoted string", number)
+		}
+		return s, nil
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return "", fmt.Errorf("line %d has an invalid quoted string", number)
+		}
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+	case '[', ']', '{', '}', '&', '*', '!', '|', '>':
+		return "", fmt.Errorf("line %d uses unsupported YAML syntax", number)
+	}
+	return text, nil
+}
+
+// yamlDocument converts a parsed document into a Document, rejecting unknown keys as Load does.
+func yamlDocument(v interface{}) (Document, error) {
+	var doc Document
+	top, ok := v.(map[string]interface{})
+	if !ok {
+		return doc, fmt.Errorf("the document is not a mapping")
+	}
+	for key, value := range top {
+		if key != "schedules" {
+			return doc, fmt.Errorf("unknown key '%s'", key)
+		}
+		if value == nil {
+			continue
+		}
+		items, ok := value.([]interface{})
+		if !ok {
+			return doc, fmt.Errorf("schedules is not a sequence")
+		}
+		for i, item := range items {
+			s, err := yamlSchedule(item)
+			if err != nil {
+				return doc, fmt.Errorf("schedule %d: %s", i+1, err)
+			}
+			doc.Schedules = append(doc.Schedules, s)
+		}
+	}
+	return doc, nil
+}
+
+// yamlSchedule converts a parsed schedule mapping.
+func yamlSchedule(v interface{}) (Schedule, error) {
+	var s Schedule
+	fields, ok := v.(map[string]interface{})
+	if !ok {
+		return s, fmt.Errorf("not a mapping")
+	}
+	for key, value := range fields {
+		var target *string
+		switch key {
+		case "name":
+			target = &s.Name
+		case "expression":
+			target = &s.Expression
+		case "timezone":
+			target = &s.Timezone
+		case "jitter":
+			target = &s.Jitter
+		case "exclusions":
+			if value == nil {
+				continue
+			}
+			dates, ok := value.([]interface{})
+			if !ok {
+				return s, fmt.Errorf("exclusions is not a sequence")
+			}
+			for _, d := range dates {
+				date, ok := d.(string)
+				if !ok {
+					return s, fmt.Errorf("exclusions must be dates")
+				}
+				s.Exclusions = append(s.Exclusions, date)
+			}
+			continue
+		default:
+			return s, fmt.Errorf("unknown key '%s'", key)
+		}
+		if value == nil {
+			continue
+		}
+		str, ok := value.(string)
+		if !ok {
+			return s, fmt.Errorf("%s is not a string", key)
+		}
+		*target = str
+	}
+	return s, nil
+}
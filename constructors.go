@@ -0,0 +1,85 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DailyAt returns a rule matching every day at the given hour and minute.
+func DailyAt(hour, minute int) (*Rule, error) {
+	return NewRule(strconv.Itoa(minute), strconv.Itoa(hour), "*", "*", "*")
+}
+
+// WeeklyOn returns a rule matching the given weekday of every week at the given hour and minute.
+func WeeklyOn(weekday time.Weekday, hour, minute int) (*Rule, error) {
+	return NewRule(strconv.Itoa(minute), strconv.Itoa(hour), "*", "*", strconv.Itoa(int(weekday)))
+}
+
+// MonthlyOn returns a rule matching the given day of every month at the given hour and minute. Months without
+// that day, such as February for day 30, are skipped.
+func MonthlyOn(day, hour, minute int) (*Rule, error) {
+	return NewRule(strconv.Itoa(minute), strconv.Itoa(hour), strconv.Itoa(day), "*", "*")
+}
+
+// EveryNMinutes returns a rule matching every n minutes of every hour, starting offset minutes past the hour. For
+// example EveryNMinutes(15, 5) matches at 5, 20, 35, and 50 minutes past. n must be between 1 and 59 and offset
+// between 0 and n-1. Intervals that do not divide 60 restart at the offset each hour, so the gap across the hour
+// is shorter.
+func EveryNMinutes(n, offset int) (*Rule, error) {
+	item, err := stepItem(n, offset, minuteField)
+	if err != nil {
+		return nil, err
+	}
+	return NewRule(item, "*", "*", "*", "*")
+}
+
+// EveryNHours returns a rule matching on the hour every n hours of every day, starting offset hours after
+// midnight. For example EveryNHours(6, 2) matches at 02:00, 08:00, 14:00, and 20:00. n must be between 1 and 23
+// and offset between 0 and n-1.
+func EveryNHours(n, offset int) (*Rule, error) {
+	item, err := stepItem(n, offset, hourField)
+	if err != nil {
+		return nil, err
+	}
+	return NewRule("0", item, "*", "*", "*")
+}
+
+// stepItem returns the rule item matching every n values of the field starting from offset.
+func stepItem(n, offset int, f field) (string, error) {
+	if n < 1 || n > f.max {
+		return "", fmt.Errorf("%s interval %d must be between 1 and %d", f.name, n, f.max)
+	}
+	if offset < 0 || offset >= n {
+		return "", fmt.Errorf("%s offset %d must be between 0 and %d", f.name, offset, n-1)
+	}
+	if offset == 0 {
+		return "*/" + strconv.Itoa(n), nil
+	}
+	var values []int
+	for v := offset; v <= f.max; v += n {
+		values = append(values, v)
+	}
+	return FieldValues(values).ruleItem(), nil
+}
+
+// NthWeekdayOfMonth returns a rule matching the n-th given weekday of every month at the given hour and minute.
+// For example NthWeekdayOfMonth(2, time.Tuesday, 9, 0) matches 09:00 on the second Tuesday of each month. n must
+// be between 1 and 5.
+func NthWeekdayOfMonth(n int, weekday time.Weekday, hour, minute int) (*Rule, error) {
+	return NewRule(strconv.Itoa(minute), strconv.Itoa(hour), "*", "*", fmt.Sprintf("%d#%d", weekday, n))
+}
+
+// BusinessDayOfMonth returns a rule matching the n-th business day of every month at the given hour and minute,
+// using cal to exclude holidays. cal may be nil. See WithBusinessDay for how n is interpreted; an error is returned
+// if n is 0 or out of range.
+func BusinessDayOfMonth(n int, cal Calendar, hour, minute int) (*Rule, error) {
+	if n == 0 {
+		return nil, fmt.Errorf("Business day must not be 0")
+	}
+	r, err := DailyAt(hour, minute)
+	if err != nil {
+		return nil, err
+	}
+	return r.WithCalendar(cal).WithBusinessDay(n)
+}
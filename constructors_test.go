@@ -0,0 +1,127 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimpleConstructors(t *testing.T) {
+	r, err := DailyAt(9, 30)
+	if err != nil || r.String() != "30 9 * * *" {
+		t.Errorf("'%v' Did not match! %v", r, err)
+	}
+	r, err = WeeklyOn(time.Sunday, 23, 0)
+	if err != nil || r.String() != "0 23 * * 0" {
+		t.Errorf("'%v' Did not match! %v", r, err)
+	}
+	r, err = MonthlyOn(15, 0, 5)
+	if err != nil || r.String() != "5 0 15 * *" {
+		t.Errorf("'%v' Did not match! %v", r, err)
+	}
+
+	if _, err = DailyAt(24, 0); err == nil {
+		t.Error("should have failed")
+	}
+	if _, err = WeeklyOn(time.Monday, 0, 60); err == nil {
+		t.Error("should have failed")
+	}
+	if _, err = MonthlyOn(32, 0, 0); err == nil {
+		t.Error("should have failed")
+	}
+	if _, err = MonthlyOn(-1, 0, 0); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestEveryNMinutes(t *testing.T) {
+	cases := map[[2]int]string{
+		{15, 0}: "*/15 * * * *",
+		{15, 5}: "5/20/35/50 * * * *",
+		{25, 7}: "7/32/57 * * * *",
+		{1, 0}:  "*/1 * * * *",
+		{59, 3}: "3 * * * *",
+	}
+	for args, e := range cases {
+		r, err := EveryNMinutes(args[0], args[1])
+		if err != nil {
+			t.Error(err.Error())
+		} else if r.String() != e {
+			t.Errorf("'%s' Did not match! '%s'", r.String(), e)
+		}
+	}
+	for _, args := range [][2]int{{0, 0}, {60, 0}, {15, 15}, {15, -1}} {
+		if _, err := EveryNMinutes(args[0], args[1]); err == nil {
+			t.Errorf("%v should have failed", args)
+		}
+	}
+}
+
+func TestEveryNHours(t *testing.T) {
+	r, err := EveryNHours(6, 2)
+	if err != nil || r.String() != "0 2/8/14/20 * * *" {
+		t.Errorf("'%v' Did not match! %v", r, err)
+	}
+	r, err = EveryNHours(4, 0)
+	if err != nil || r.StringNormalized() != "0 0,4,8,12,16,20 * * *" {
+		t.Errorf("'%v' Did not match! %v", r, err)
+	}
+	if _, err = EveryNHours(24, 0); err == nil {
+		t.Error("should have failed")
+	}
+	if _, err = EveryNHours(3, 3); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestNthWeekdayOfMonth(t *testing.T) {
+	r, err := NthWeekdayOfMonth(2, time.Tuesday, 9, 0)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "0 9 * * 2#2" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+
+	n := r.NextAfter(time.Date(2000, 4, 1, 0, 0, 0, 0, time.UTC))
+	e := time.Date(2000, 4, 11, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = r.NextAfter(n)
+	e = time.Date(2000, 5, 9, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+
+	if _, err = NthWeekdayOfMonth(6, time.Tuesday, 9, 0); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestBusinessDayOfMonth(t *testing.T) {
+	r, err := BusinessDayOfMonth(2, nil, 8, 30)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+
+	// april 1st 2000 is a saturday
+	n := r.NextAfter(time.Date(2000, 4, 1, 0, 0, 0, 0, time.UTC))
+	e := time.Date(2000, 4, 4, 8, 30, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+
+	if _, err = BusinessDayOfMonth(1, nil, 25, 0); err == nil {
+		t.Error("should have failed")
+	}
+	for _, n := range []int{0, 24, -24} {
+		if _, err = BusinessDayOfMonth(n, nil, 8, 30); err == nil {
+			t.Errorf("business day %d should have failed", n)
+		}
+	}
+	if _, err = BusinessDayOfMonth(-23, nil, 8, 30); err != nil {
+		t.Error(err)
+	}
+}
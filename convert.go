@@ -0,0 +1,143 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"time"
+)
+
+// convertHorizon is how far ahead ConvertTo checks that the offset between two locations stays the same.
+const convertHorizon = 366 * 24 * time.Hour
+
+// ConvertTo returns a copy of the rule bound to loc, with its fields rewritten so that it fires at the same
+// instants as the original. The rule must be bound to a location. An error is returned if the mapping isn't exact,
+// for example because the two locations change their clocks on different days over the coming year, or because the
+// shifted hours and minutes cannot be expressed as a rule.
+func (r *Rule) ConvertTo(loc *time.Location) (*Rule, error) {
+	return r.convertAt(loc, time.Now())
+}
+
+func (r *Rule) convertAt(loc *time.Location, from time.Time) (*Rule, error) {
+	if r.location == nil {
+		return nil, fmt.Errorf("Rule '%s' is not bound to a location", r)
+	}
+
+	// walk the zone transitions of both locations to check that the offset between them never changes
+	_, srcOffset := from.In(r.location).Zone()
+	_, dstOffset := from.In(loc).Zone()
+	difference := dstOffset - srcOffset
+	end := from.Add(convertHorizon)
+	for t := from; t.Before(end); {
+		_, srcOffset = t.In(r.location).Zone()
+		_, dstOffset = t.In(loc).Zone()
+		if dstOffset-srcOffset != difference {
+			return nil, fmt.Errorf("Rule '%s' cannot be converted to %s: the offset between them changes at %s", r, loc, t)
+		}
+		_, srcEnd := t.In(r.location).ZoneBounds()
+		_, dstEnd := t.In(loc).ZoneBounds()
+		if srcEnd.IsZero() && dstEnd.IsZero() {
+			break
+		} else if srcEnd.IsZero() || (!dstEnd.IsZero() && dstEnd.Before(srcEnd)) {
+			t = dstEnd
+		} else {
+			t = srcEnd
+		}
+	}
+
+	out, err := r.shifted(time.Duration(difference) * time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("Rule '%s' cannot be converted to %s: %s", r, loc, err.Error())
+	}
+	out.location = loc
+	return out, nil
+}
+
+// Shifted returns a copy of the rule that fires d later on the wall clock, or earlier if d is negative, so that a
+// dependent job can copy the schedule of another with a stagger such as 15 minutes. The location and other
+// settings of the rule are kept.
+//
+// d must be a whole number of minutes. An error is returned if the shifted hours and minutes cannot be written as
+// a rule, such as shifting "0/30 9/17 * * *" by 45 minutes, or if some occurrences move onto another day and the
+// day fields cannot follow them. Occurrences can only move onto another day when the day of week is the only day
+// field restricted.
+func (r *Rule) Shifted(d time.Duration) (*Rule, error) {
+	out, err := r.shifted(d)
+	if err != nil {
+		return nil, fmt.Errorf("Rule '%s' cannot be shifted by %s: %s", r, d, err.Error())
+	}
+	return out, nil
+}
+
+// shifted returns a copy of the rule that fires d later on the wall clock. d must be a whole number of minutes. An
+// error is returned if the shifted hours and minutes are not a simple combination of each other, or if the shift
+// moves some occurrences onto another day and the day fields cannot follow.
+func (r *Rule) shifted(d time.Duration) (*Rule, error) {
+	if d%time.Minute != 0 {
+		return nil, fmt.Errorf("shift %s is not a whole number of minutes", d)
+	}
+	if r.timeOfDay != nil {
+		return nil, fmt.Errorf("the time of day is resolved when the rule is evaluated")
+	}
+	shift := int(d / time.Minute)
+
+	type hourMinute struct {
+		hour, minute int
+	}
+	dayShifts := make(map[hourMinute]int)
+	hourSet := make(map[int]bool)
+	minuteSet := make(map[int]bool)
+	distinctDayShifts := make(map[int]bool)
+	for _, h := range expandField(r.hour, 0, 23) {
+		for _, m := range expandField(r.minute, 0, 59) {
+			total := h*60 + m + shift
+			days := total / (24 * 60)
+			if total < 0 && total%(24*60) != 0 {
+				days--
+			}
+			total -= days * 24 * 60
+			dayShifts[hourMinute{total / 60, total % 60}] = days
+			hourSet[total/60] = true
+			minuteSet[total%60] = true
+			distinctDayShifts[days] = true
+		}
+	}
+	if len(dayShifts) != len(hourSet)*len(minuteSet) {
+		return nil, fmt.Errorf("shifted hours and minutes cannot be expressed as a rule")
+	}
+
+	dayOfWeekRule := r.dayOfWeekRule
+	if len(distinctDayShifts) > 1 || !distinctDayShifts[0] {
+		if len(r.dayOfMonth) > 0 || len(r.month) > 0 || r.hasOccurrence() || r.dayOfMonthLast {
+			return nil, fmt.Errorf("shift moves occurrences onto other days of the month")
+		}
+		if r.calendar != nil || len(r.isoWeeks) > 0 || len(r.weeksOfMonth) > 0 || r.businessDay != 0 {
+			return nil, fmt.Errorf("shift moves occurrences onto days the other restrictions do not follow")
+		}
+		if len(r.dayOfWeek) > 0 {
+			if len(distinctDayShifts) > 1 {
+				return nil, fmt.Errorf("shift moves occurrences onto different days of the week")
+			}
+			var days []int
+			for v := range distinctDayShifts {
+				for _, dow := range r.dayOfWeek {
+					days = append(days, ((dow+v)%7+7)%7)
+				}
+			}
+			dayOfWeekRule = normalizeField(days, 0, 6).ruleItem()
+		}
+	}
+
+	parsed, err := NewRule(setItems(minuteSet, 0, 59), setItems(hourSet, 0, 23), r.dayOfMonthRule, r.monthRule, dayOfWeekRule)
+	if err != nil {
+		return nil, err
+	}
+	return r.withFields(parsed), nil
+}
+
+// setItems renders a set of field values as a rule item.
+func setItems(set map[int]bool, min, max int) string {
+	var values []int
+	for v := range set {
+		values = append(values, v)
+	}
+	return normalizeField(values, min, max).ruleItem()
+}
@@ -0,0 +1,114 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConvertTo(t *testing.T) {
+	paris := mustLoadLocation(t, "Europe/Paris")
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	r := MustParseRule("CRON_TZ=Europe/London 30 23 * * 1/5")
+	c, err := r.convertAt(paris, from)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if c.String() != "CRON_TZ=Europe/Paris 30 0 * * 2/6" {
+		t.Errorf("'%s' Did not match!", c.String())
+	}
+
+	// both rules fire at the same instants
+	a, b := from, from
+	for i := 0; i < 50; i++ {
+		a, b = r.NextAfter(a), c.NextAfter(b)
+		if !a.Equal(b) {
+			t.Errorf("%s != %s", a, b)
+			return
+		}
+	}
+
+	// occurrences on either side of midnight cannot follow the day of week
+	r = MustParseRule("CRON_TZ=Europe/London 30 9/23 * * 1/5")
+	if _, err = r.convertAt(paris, from); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestConvertToFixed(t *testing.T) {
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := MustNewRule("*/20", "*", "*", "*", "*").In(time.UTC)
+	c, err := r.convertAt(time.FixedZone("India", 5*3600+30*60), from)
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if c.StringNormalized() != "CRON_TZ=India 10,30,50 * * * *" {
+		t.Errorf("'%s' Did not match!", c.StringNormalized())
+	}
+}
+
+func TestConvertToNotExact(t *testing.T) {
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// the clocks change on different days
+	r := MustParseRule("CRON_TZ=Europe/London 0 9 * * *")
+	if _, err := r.convertAt(newYork, from); err == nil {
+		t.Error("should have failed")
+	}
+
+	// some occurrences move onto the next day of the month
+	r = MustNewRule("0", "12/23", "1", "*", "*").In(time.UTC)
+	if _, err := r.convertAt(time.FixedZone("+2", 2*3600), from); err == nil {
+		t.Error("should have failed")
+	}
+
+	// unbound rules have no instants to preserve
+	if _, err := MustNewRule("0", "0", "*", "*", "*").convertAt(newYork, from); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestShifted(t *testing.T) {
+	cases := []struct {
+		expr     string
+		d        time.Duration
+		expected string
+	}{
+		{"0 9 * * *", 15 * time.Minute, "15 9 * * *"},
+		{"*/20 9 * * 1/2/3/4/5", 2 * time.Hour, "0/20/40 11 * * 1/2/3/4/5"},
+		{"30 23 * * 1/5", 45 * time.Minute, "15 0 * * 2/6"},
+		{"0 0 1 * *", -time.Hour, ""},
+		{"0/30 9/17 * * *", 45 * time.Minute, ""},
+		{"0 9 * * *", 30 * time.Second, ""},
+	}
+	for _, c := range cases {
+		r, err := MustParseRule(c.expr).Shifted(c.d)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error but got '%s'", c.expr, r)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", c.expr, err)
+		} else if r.StringNormalized() != MustParseRule(c.expected).StringNormalized() {
+			t.Errorf("%s: '%s' Did not match! '%s'", c.expr, r.StringNormalized(), c.expected)
+		}
+	}
+
+	// settings other than the fields are kept
+	r := MustParseRule("CRON_TZ=Europe/London 0 9 * * *").WithFallBackPolicy(FireTwice).WithGranularity(SecondGranularity)
+	s, err := r.Shifted(15 * time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.String() != "CRON_TZ=Europe/London 15 9 * * *" || s.fallBack != FireTwice || s.Granularity() != SecondGranularity {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if _, err := mustRule(MustParseRule("0 23 * * *").WithWeeksOfMonth(1)).Shifted(2 * time.Hour); err == nil {
+		t.Error("expected an error for weeks of the month that cannot follow the shift")
+	}
+}
@@ -0,0 +1,93 @@
+package ticktickrules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Entry is a single scheduled command read from a crontab file.
+type Entry struct {
+	// Rule is the schedule of the command.
+	Rule *Rule
+	// Command is the remainder of the line after the 5 schedule fields.
+	Command string
+	// Env holds the environment variable assignments in effect at this entry, such as MAILTO or TZ.
+	Env map[string]string
+	// Line is the 1-based line number of the entry within the crontab.
+	Line int
+}
+
+// envAssignment matches crontab lines of the form NAME=value.
+var envAssignment = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// ParseCrontab reads a crontab file and returns its entries in order. Blank lines and lines starting with "#"
+// are skipped. Environment variable assignments apply to all of the entries that follow them. An error is
+// returned for the first line that cannot be parsed.
+func ParseCrontab(r io.Reader) ([]Entry, error) {
+	var out []Entry
+	env := make(map[string]string)
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if m := envAssignment.FindStringSubmatch(text); m != nil {
+			env[m[1]] = unquote(strings.TrimSpace(m[2]))
+			continue
+		}
+
+		fields, command := splitFields(text, 5)
+		if len(fields) != 5 || command == "" {
+			return nil, fmt.Errorf("Crontab line %d: expected 5 fields and a command", line)
+		}
+		rule, err := ParseRule(strings.Join(fields, " "), AllowMixedCaseNames())
+		if err != nil {
+			return nil, fmt.Errorf("Crontab line %d: %s", line, err.Error())
+		}
+
+		entryEnv := make(map[string]string, len(env))
+		for k, v := range env {
+			entryEnv[k] = v
+		}
+		out = append(out, Entry{Rule: rule, Command: command, Env: entryEnv, Line: line})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// splitFields returns up to n whitespace separated fields from the start of s, and the remainder of s with its
+// original spacing intact.
+func splitFields(s string, n int) ([]string, string) {
+	var fields []string
+	for len(fields) < n {
+		s = strings.TrimLeft(s, " \t")
+		if s == "" {
+			break
+		}
+		i := strings.IndexAny(s, " \t")
+		if i < 0 {
+			i = len(s)
+		}
+		fields = append(fields, s[:i])
+		s = s[i:]
+	}
+	return fields, strings.TrimSpace(s)
+}
+
+// unquote removes a single pair of matching surrounding quotes from s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package ticktickrules
+
+import (
+	"strings"
+	"testing"
+)
+
+const exampleCrontab = `# example crontab
+MAILTO=ops@example.com
+SHELL = "/bin/bash"
+
+*/5 * * * * /usr/bin/check  --quiet
+# nightly backups
+TZ='Europe/London'
+0 2 * * Mon/Fri /usr/bin/backup > /dev/null 2>&1
+`
+
+func TestParseCrontab(t *testing.T) {
+	entries, err := ParseCrontab(strings.NewReader(exampleCrontab))
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if len(entries) != 2 {
+		t.Errorf("%d entries should be 2", len(entries))
+		return
+	}
+
+	e := entries[0]
+	if e.Rule.String() != "*/5 * * * *" || e.Command != "/usr/bin/check  --quiet" || e.Line != 5 {
+		t.Errorf("first entry %s '%s' %d Did not match!", e.Rule, e.Command, e.Line)
+	}
+	if e.Env["MAILTO"] != "ops@example.com" || e.Env["SHELL"] != "/bin/bash" {
+		t.Errorf("first entry env %v Did not match!", e.Env)
+	}
+	if _, ok := e.Env["TZ"]; ok {
+		t.Error("first entry should not have TZ")
+	}
+
+	e = entries[1]
+	if e.Rule.String() != "0 2 * * MON/FRI" || e.Command != "/usr/bin/backup > /dev/null 2>&1" || e.Line != 8 {
+		t.Errorf("second entry %s '%s' %d Did not match!", e.Rule, e.Command, e.Line)
+	}
+	if e.Env["TZ"] != "Europe/London" {
+		t.Errorf("second entry env %v Did not match!", e.Env)
+	}
+}
+
+func TestParseCrontabBadLines(t *testing.T) {
+	if _, err := ParseCrontab(strings.NewReader("0 2 * * *\n")); err == nil {
+		t.Error("missing command should have failed")
+	}
+	if _, err := ParseCrontab(strings.NewReader("\n61 2 * * * /bin/true\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("bad rule should have failed on line 2: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Dialect is a flavour of cron expression used by another system.
+type Dialect int
+
+const (
+	// Standard is the POSIX crontab syntax with five fields, comma separated lists, ranges, and steps, as used by
+	// Kubernetes CronJobs. Days of the week are 0-7 where both 0 and 7 are Sunday. Like cron, a rule restricting
+	// both the day of month and day of week fires when either of them matches.
+	Standard Dialect = iota
+	// Quartz is the syntax of the Quartz scheduler, with a leading seconds field and an optional trailing year
+	// field. Days of the week are 1-7 starting on Sunday, and one of day of month and day of week must be "?". A
+	// "*" seconds field fires every second and is parsed as SecondGranularity.
+	Quartz
+	// AWS is the syntax of Amazon EventBridge cron expressions, with a trailing year field and optionally wrapped
+	// in "cron(...)". Days of the week are 1-7 starting on Sunday, and one of day of month and day of week must
+	// be "?".
+	AWS
+	// Kubernetes is the syntax of Kubernetes CronJob schedules, which is the same as Standard except that the
+	// "@hourly" style shorthands are accepted and a rule restricting both the day of month and day of week fires
+	// when either of them matches. See ParseKubernetes.
+	Kubernetes
+)
+
+func (d Dialect) String() string {
+	switch d {
+	case Standard:
+		return "standard"
+	case Quartz:
+		return "quartz"
+	case AWS:
+		return "aws"
+	case Kubernetes:
+		return "kubernetes"
+	}
+	return "Dialect(" + strconv.Itoa(int(d)) + ")"
+}
+
+// ParseDialect parses an expression written in the given dialect. Only features that can be represented by a Rule
+// are supported: seconds must be 0, or "*" in Quartz, and years must be "*".
+func ParseDialect(expr string, dialect Dialect) (*Rule, error) {
+	parts := strings.Fields(expr)
+	granularity := MinuteGranularity
+	switch dialect {
+	case Kubernetes:
+		return ParseKubernetes(expr)
+	case Standard:
+		if len(parts) != 5 {
+			return nil, fmt.Errorf("Expression '%s' must have 5 fields but has %d", expr, len(parts))
+		}
+	case Quartz:
+		if len(parts) != 6 && len(parts) != 7 {
+			return nil, fmt.Errorf("Expression '%s' must have 6 or 7 fields but has %d", expr, len(parts))
+		}
+		if parts[0] == "*" {
+			granularity = SecondGranularity
+		} else if parts[0] != "0" {
+			return nil, fmt.Errorf("Expression '%s' must have 0 or * in the seconds field", expr)
+		}
+		if len(parts) == 7 && parts[6] != "*" {
+			return nil, fmt.Errorf("Expression '%s' must have * in the year field", expr)
+		}
+		parts = parts[1:6]
+	case AWS:
+		if strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")") {
+			parts = strings.Fields(expr[len("cron(") : len(expr)-1])
+		}
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("Expression '%s' must have 6 fields but has %d", expr, len(parts))
+		}
+		if parts[5] != "*" {
+			return nil, fmt.Errorf("Expression '%s' must have * in the year field", expr)
+		}
+		parts = parts[:5]
+	default:
+		return nil, fmt.Errorf("Unsupported dialect %s", dialect)
+	}
+
+	if dialect != Standard && (parts[2] == "?") == (parts[4] == "?") {
+		return nil, fmt.Errorf("Expression '%s' must have ? in exactly one of the day of month and day of week fields", expr)
+	}
+
+	items := make([]string, 5)
+	for i, f := range ruleFields {
+		item, err := translateItem(parts[i], f, dialect != Standard && f.name == dayOfWeekField.name)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	either := dialect == Standard && vixieDays(parts, items)
+	r, err := NewRule(items[0], items[1], items[2], items[3], items[4])
+	if err != nil {
+		return nil, err
+	}
+	r.eitherDay = either
+	return r.WithGranularity(granularity), nil
+}
+
+// translateItem converts an item using lists, ranges, and steps into the form accepted by NewRule. oneBased is set
+// for days of the week numbered 1-7 from Sunday.
+func translateItem(item string, f field, oneBased bool) (string, error) {
+	if item == "*" || item == "?" {
+		return "*", nil
+	}
+	if i := strings.Index(item, "#"); i >= 0 && f.name == dayOfWeekField.name {
+		v, err := dialectValue(item[:i], item, f, oneBased)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(v%7) + item[i:], nil
+	}
+	if f.name == dayOfMonthField.name && (item == "L" || strings.HasPrefix(item, "L-")) {
+		return item, nil
+	}
+	if len(item) > 1 && strings.HasSuffix(item, "L") && f.name == dayOfWeekField.name {
+		v, err := dialectValue(item[:len(item)-1], item, f, oneBased)
+		if err != nil {
+			return "", err
+		}
+		return strconv.Itoa(v%7) + "L", nil
+	}
+
+	var values []int
+	for _, part := range strings.Split(item, ",") {
+		base, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return "", &SyntaxError{Item: item, Reason: "has an invalid step"}
+			}
+			base, step = part[:i], s
+		}
+
+		lo, hi := f.min, f.max
+		if i := strings.Index(base, "-"); i > 0 {
+			var err error
+			if lo, err = dialectValue(base[:i], item, f, oneBased); err != nil {
+				return "", err
+			}
+			if hi, err = dialectValue(base[i+1:], item, f, oneBased); err != nil {
+				return "", err
+			}
+			if hi < lo {
+				return "", &SyntaxError{Item: item, Reason: "has a range that wraps around"}
+			}
+		} else if base != "*" {
+			v, err := dialectValue(base, item, f, oneBased)
+			if err != nil {
+				return "", err
+			}
+			lo, hi = v, v
+			if step > 1 && v < f.max {
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			// Sunday may have been written as 7
+			if f.alias > 0 && v == f.alias {
+				values = append(values, f.min)
+			} else {
+				values = append(values, v)
+			}
+		}
+	}
+	return normalizeField(values, f.min, f.max).ruleItem(), nil
+}
+
+// dialectValue parses a single number or name. Days of the week numbered from 1 are converted to start from 0.
+func dialectValue(s, item string, f field, oneBased bool) (int, error) {
+	if named := replaceNames(strings.ToUpper(s), f.names, f.offset); named != strings.ToUpper(s) {
+		return strconv.Atoi(named)
+	}
+	if oneBased {
+		v, err := parseValue(s, item, f, 1, 7)
+		return v - 1, err
+	}
+	upper := f.max
+	if f.alias > upper {
+		upper = f.alias
+	}
+	return parseValue(s, item, f, f.min, upper)
+}
+
+// Format renders the rule in the given dialect. An error is returned if the rule cannot be represented exactly,
+// such as rules restricting both the day of month and day of week in the Quartz and AWS dialects, which only allow
+// one, or rules with a calendar or the other restrictions added with the With methods. Only Quartz has a seconds
+// field to express SecondGranularity. The location of the rule is not included, since these systems configure
+// the time zone separately from the expression.
+func (r *Rule) Format(dialect Dialect) (string, error) {
+	if dialect != Standard && dialect != Kubernetes && dialect != Quartz && dialect != AWS {
+		return "", fmt.Errorf("Unsupported dialect %s", dialect)
+	}
+	if s := r.restriction(); s != "" {
+		return "", fmt.Errorf("Rule '%s' has %s, which cannot be expressed in the %s dialect", r, s, dialect)
+	}
+	if r.granularity == SecondGranularity && dialect != Quartz {
+		return "", fmt.Errorf("Rule '%s' fires every second, which cannot be expressed in the %s dialect", r, dialect)
+	}
+	if dialect == Standard {
+		// standard cron fires when either day field matches if both are restricted
+		if r.restrictsBothDays() && !r.eitherDay {
+			return "", fmt.Errorf("Rule '%s' requires both the day of month and day of week to match, which cannot be expressed in the %s dialect", r, dialect)
+		}
+		return r.ToStandardCron(), nil
+	}
+	if dialect == Kubernetes {
+		return r.kubernetesSchedule()
+	}
+
+	dom := r.dayOfMonthString(compactField(r.DaysOfMonth(), dayOfMonthField.min, dayOfMonthField.max))
+	dow := "?"
+	if days := r.DaysOfWeek(); len(days) > 0 || r.hasOccurrence() {
+		if dom != "*" {
+			return "", fmt.Errorf("Rule '%s' restricts both the day of month and day of week", r)
+		}
+		dom = "?"
+		oneBased := make(FieldValues, len(days))
+		for i, v := range days {
+			oneBased[i] = v + 1
+		}
+		dow = compactField(oneBased, 1, 7) + r.occurrenceSuffix()
+	}
+	fields := []string{
+		compactField(r.Minutes(), minuteField.min, minuteField.max),
+		compactField(r.Hours(), hourField.min, hourField.max),
+		dom,
+		compactField(r.Months(), monthField.min, monthField.max),
+		dow,
+	}
+	if dialect == Quartz {
+		seconds := "0"
+		if r.granularity == SecondGranularity {
+			seconds = "*"
+		}
+		return seconds + " " + strings.Join(fields, " "), nil
+	}
+	return strings.Join(fields, " ") + " *", nil
+}
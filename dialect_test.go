@@ -0,0 +1,167 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDialect(t *testing.T) {
+	cases := []struct {
+		expr     string
+		dialect  Dialect
+		expected string
+	}{
+		{"*/15 9-17 * * 1-5", Standard, "0,15,30,45 9,10,11,12,13,14,15,16,17 * * 1,2,3,4,5"},
+		{"0 0 1,15 JAN-MAR SUN,7", Standard, "0 0 1,15 1,2,3 0"},
+		{"0 12 * * 5#3", Standard, "0 12 * * 5#3"},
+		{"0 0 12 ? * MON-FRI", Quartz, "0 12 * * 1,2,3,4,5"},
+		{"0 30 6 ? * 2-6 *", Quartz, "30 6 * * 1,2,3,4,5"},
+		{"0 0 0 ? * 1,7", Quartz, "0 0 * * 0,6"},
+		{"0 0 0 ? * 6#3", Quartz, "0 0 * * 5#3"},
+		{"0 0 17 ? * 6L", Quartz, "0 17 * * 5L"},
+		{"0 0 12 L-2 * ?", Quartz, "0 12 L-2 * *"},
+		{"0 0 0 L/10 * ?", Quartz, ""},
+		{"0/30 8-10 1 * ? *", AWS, "0,30 8,9,10 1 * *"},
+		{"cron(0 18 ? * MON-FRI *)", AWS, "0 18 * * 1,2,3,4,5"},
+		{"0 18 ? * 1 *", AWS, "0 18 * * 0"},
+		{"0 18 ? * 1 2026", AWS, ""},
+		{"1 0 0 * * ?", Quartz, ""},
+		{"0 0 * * *", Quartz, ""},
+		{"0 0 12 * * MON", Quartz, ""},
+		{"0 0 12 ? * ?", Quartz, ""},
+		{"0 18 1 * 1 *", AWS, ""},
+		{"0 0 * * 8", Standard, ""},
+		{"0 0 ? * 0 *", AWS, ""},
+		{"0 10-5 * * *", Standard, ""},
+	}
+	for _, c := range cases {
+		r, err := ParseDialect(c.expr, c.dialect)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("%s (%s): expected an error", c.expr, c.dialect)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s (%s): %s", c.expr, c.dialect, err)
+			continue
+		}
+		if s := r.StringNormalized(); s != c.expected {
+			t.Errorf("%s (%s): '%s' Did not match! '%s'", c.expr, c.dialect, s, c.expected)
+		}
+	}
+}
+
+func TestFormatDialect(t *testing.T) {
+	cases := []struct {
+		expr     string
+		dialect  Dialect
+		expected string
+	}{
+		{"0/15/30/45 9 * * 1/2/3/4/5", Standard, "*/15 9 * * 1-5"},
+		{"0/15/30/45 9 * * 1/2/3/4/5", Quartz, "0 */15 9 ? * 2-6"},
+		{"0/15/30/45 9 * * 1/2/3/4/5", AWS, "*/15 9 ? * 2-6 *"},
+		{"0 0 1 * *", Quartz, "0 0 0 1 * ?"},
+		{"0 0 * * *", AWS, "0 0 * * ? *"},
+		{"0 0 * * 0/6", Quartz, "0 0 0 ? * 1,7"},
+		{"0 0 * * 5#3", AWS, "0 0 ? * 6#3 *"},
+		{"0 0 * * 5L", Quartz, "0 0 0 ? * 6L"},
+	}
+	for _, c := range cases {
+		s, err := MustParseRule(c.expr).Format(c.dialect)
+		if err != nil {
+			t.Errorf("%s (%s): %s", c.expr, c.dialect, err)
+		} else if s != c.expected {
+			t.Errorf("%s (%s): '%s' Did not match! '%s'", c.expr, c.dialect, s, c.expected)
+		}
+	}
+
+	if _, err := MustParseRule("0 0 1 * 1").Format(Quartz); err == nil {
+		t.Error("expected an error when both days are restricted")
+	}
+	if _, err := MustParseRule("0 0 1 * 1").Format(Standard); err == nil {
+		t.Error("expected an error when both days must match in standard cron")
+	}
+	k, err := ParseKubernetes("0 0 1 * 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := k.Format(Standard); err != nil {
+		t.Error(err)
+	} else if s != "0 0 1 * 1" {
+		t.Errorf("'%s' Did not match! '0 0 1 * 1'", s)
+	}
+
+	r := MustParseRule("0 9 * * *")
+	restricted := []*Rule{
+		r.WithCalendar(NewHolidays(time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC))),
+		mustRule(r.WithISOWeeks(1)),
+		mustRule(r.WithWeeksOfMonth(2)),
+		mustRule(r.WithBusinessDay(1)),
+	}
+	for _, rr := range restricted {
+		for _, d := range []Dialect{Standard, Kubernetes, Quartz, AWS} {
+			if s, err := rr.Format(d); err == nil {
+				t.Errorf("expected an error formatting a restricted rule in %s, got '%s'", d, s)
+			}
+		}
+	}
+
+	secs := r.WithGranularity(SecondGranularity)
+	if s, err := secs.Format(Quartz); err != nil {
+		t.Error(err)
+	} else if s != "* 0 9 * * ?" {
+		t.Errorf("'%s' Did not match! '* 0 9 * * ?'", s)
+	}
+	if _, err := secs.Format(Standard); err == nil {
+		t.Error("expected an error formatting a second granularity rule in standard cron")
+	}
+}
+
+func TestDialectRoundTrip(t *testing.T) {
+	for _, expr := range []string{"*/5 * * * *", "0 9/17 * * 1/2/3/4/5", "30 2 1/15 * *", "0 0 * JAN/JUL 0#1"} {
+		r := MustParseRule(expr)
+		for _, d := range []Dialect{Standard, Quartz, AWS} {
+			s, err := r.Format(d)
+			if err != nil {
+				t.Errorf("%s (%s): %s", expr, d, err)
+				continue
+			}
+			back, err := ParseDialect(s, d)
+			if err != nil {
+				t.Errorf("%s (%s): %s", s, d, err)
+				continue
+			}
+			if back.StringNormalized() != r.StringNormalized() {
+				t.Errorf("%s (%s): '%s' Did not match! '%s'", expr, d, back.StringNormalized(), r.StringNormalized())
+			}
+		}
+	}
+
+	// standard cron fires on every Friday and every 13th
+	r, err := ParseDialect("0 0 13 * 5", Standard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	if n := r.NextAfter(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); !n.Equal(expected) {
+		t.Errorf("%s != %s", n, expected)
+	}
+	if s, err := r.Format(Standard); err != nil {
+		t.Error(err)
+	} else if back, err := ParseDialect(s, Standard); err != nil {
+		t.Error(err)
+	} else if back.Fingerprint() != r.Fingerprint() {
+		t.Errorf("%s did not round trip through '%s'", r, s)
+	}
+
+	// a "*" seconds field in Quartz fires every second
+	secs := MustParseRule("0 9 * * *").WithGranularity(SecondGranularity)
+	if s, err := secs.Format(Quartz); err != nil {
+		t.Error(err)
+	} else if back, err := ParseDialect(s, Quartz); err != nil {
+		t.Error(err)
+	} else if back.Fingerprint() != secs.Fingerprint() {
+		t.Errorf("%s did not round trip through '%s'", secs, s)
+	}
+}
@@ -0,0 +1,91 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FieldChange describes how a single field differs between two rules.
+type FieldChange struct {
+	// Field is the name of the field, such as "Hour" or "Location"
+	Field string
+	// From and To are the rendered values of the field in the old and new rule
+	From string
+	To   string
+	// Added and Removed are the values matched by only the new or only the old rule
+	Added   FieldValues
+	Removed FieldValues
+}
+
+// String renders the change, for example "Hour changed from 2 to 2,14".
+func (c FieldChange) String() string {
+	return fmt.Sprintf("%s changed from %s to %s", c.Field, c.From, c.To)
+}
+
+// FieldDiff is the list of fields that differ between two rules, in the order they appear in the rule.
+type FieldDiff []FieldChange
+
+// String renders every change separated by "; ", or "no changes" if the rules are the same.
+func (d FieldDiff) String() string {
+	if len(d) == 0 {
+		return "no changes"
+	}
+	parts := make([]string, len(d))
+	for i, c := range d {
+		parts[i] = c.String()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Diff compares the values matched by each field of a and b. Fields written differently but matching the same
+// values, such as "*/30" and "0/30", are not reported.
+func Diff(a, b *Rule) FieldDiff {
+	var out FieldDiff
+	out = diffField(out, minuteField, a.Minutes(), b.Minutes(), a.Minutes().String(), b.Minutes().String())
+	out = diffField(out, hourField, a.Hours(), b.Hours(), a.Hours().String(), b.Hours().String())
+	out = diffField(out, dayOfMonthField, a.DaysOfMonth(), b.DaysOfMonth(),
+		a.dayOfMonthString(a.DaysOfMonth().String()), b.dayOfMonthString(b.DaysOfMonth().String()))
+	out = diffField(out, monthField, a.Months(), b.Months(), a.Months().String(), b.Months().String())
+	out = diffField(out, dayOfWeekField, a.DaysOfWeek(), b.DaysOfWeek(), a.dayOfWeekString(nil), b.dayOfWeekString(nil))
+
+	if from, to := locationName(a.Location()), locationName(b.Location()); from != to {
+		out = append(out, FieldChange{Field: "Location", From: from, To: to})
+	}
+	return out
+}
+
+// diffField appends a change to out if the rendered values differ.
+func diffField(out FieldDiff, f field, from, to FieldValues, fromString, toString string) FieldDiff {
+	if fromString == toString {
+		return out
+	}
+	fromAll := expandField(from, f.min, f.max)
+	toAll := expandField(to, f.min, f.max)
+	return append(out, FieldChange{
+		Field:   f.name,
+		From:    fromString,
+		To:      toString,
+		Added:   missingFrom(toAll, fromAll),
+		Removed: missingFrom(fromAll, toAll),
+	})
+}
+
+// missingFrom returns the values in a that are not in b.
+func missingFrom(a, b []int) FieldValues {
+	var out FieldValues
+	for _, v := range a {
+		if !doesMatch(v, b) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// locationName returns the name of loc, or "none" for rules that are not bound to a location.
+func locationName(loc *time.Location) string {
+	if loc == nil {
+		return "none"
+	}
+	return loc.String()
+}
@@ -0,0 +1,43 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDiff(t *testing.T) {
+	a := MustParseRule("*/30 2 * * *")
+	b := MustParseRule("0/30 2/14 * * 1#2")
+
+	d := Diff(a, b)
+	if len(d) != 2 {
+		t.Errorf("%d != 2", len(d))
+		return
+	}
+	e := "Hour changed from 2 to 2,14; Day of Week changed from * to 1#2"
+	if d.String() != e {
+		t.Errorf("'%s' Did not match!", d.String())
+	}
+	if d[0].Added.String() != "14" || len(d[0].Removed) != 0 {
+		t.Errorf("'%s' '%s' Did not match!", d[0].Added, d[0].Removed)
+	}
+	if d[1].Removed.String() != "0,2,3,4,5,6" || len(d[1].Added) != 0 {
+		t.Errorf("'%s' '%s' Did not match!", d[1].Added, d[1].Removed)
+	}
+}
+
+func TestDiffNone(t *testing.T) {
+	a := MustParseRule("0 0 * JAN/FEB 7")
+	b := MustParseRule("0 0 * 1/2 0")
+	if d := Diff(a, b); d.String() != "no changes" {
+		t.Errorf("'%s' Did not match!", d.String())
+	}
+}
+
+func TestDiffLocation(t *testing.T) {
+	a := MustParseRule("0 0 * * *")
+	b := a.In(time.UTC)
+	if d := Diff(a, b); d.String() != "Location changed from none to UTC" {
+		t.Errorf("'%s' Did not match!", d.String())
+	}
+}
@@ -0,0 +1,78 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// DriftKind classifies an observed run of a scheduled job.
+type DriftKind int
+
+const (
+	// OnSchedule runs started within the minute of their occurrence.
+	OnSchedule DriftKind = iota
+	// Late runs started a minute or more after their occurrence.
+	Late
+	// Early runs started before their occurrence.
+	Early
+	// Unscheduled runs were for a time that is not an occurrence of the rule, so should not have happened.
+	Unscheduled
+)
+
+func (k DriftKind) String() string {
+	switch k {
+	case OnSchedule:
+		return "on schedule"
+	case Late:
+		return "late"
+	case Early:
+		return "early"
+	case Unscheduled:
+		return "unscheduled"
+	}
+	return "DriftKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// DriftReport is the result of Rule.Drift.
+type DriftReport struct {
+	Kind     DriftKind
+	Expected time.Time
+	Actual   time.Time
+	// Lateness is how long after the expected occurrence the run started. It is negative for early runs.
+	Lateness time.Duration
+}
+
+func (d DriftReport) String() string {
+	switch d.Kind {
+	case Late:
+		return fmt.Sprintf("%s by %s", d.Kind, d.Lateness)
+	case Early:
+		return fmt.Sprintf("%s by %s", d.Kind, -d.Lateness)
+	case Unscheduled:
+		return fmt.Sprintf("%s run at %s", d.Kind, d.Actual.Format(time.RFC3339))
+	}
+	return d.Kind.String()
+}
+
+// Drift classifies a run of a job observed at actual that was meant to be for the occurrence at expected, for
+// example from the logs of a scheduler, so that monitoring can score how healthy the scheduler is. If expected is
+// the zero time, the most recent occurrence at or before actual is assumed. Runs for a time that is not an
+// occurrence of the rule, or with no occurrence before them, are Unscheduled.
+func (r *Rule) Drift(expected, actual time.Time) DriftReport {
+	if expected.IsZero() {
+		expected = r.Floor(actual)
+	}
+	out := DriftReport{Expected: expected, Actual: actual, Lateness: actual.Sub(expected)}
+	switch {
+	case expected.Equal(farPast) || expected.Second() != 0 || expected.Nanosecond() != 0 || !r.Matches(expected):
+		out.Kind = Unscheduled
+	case out.Lateness < 0:
+		out.Kind = Early
+	case out.Lateness >= time.Minute:
+		out.Kind = Late
+	default:
+		out.Kind = OnSchedule
+	}
+	return out
+}
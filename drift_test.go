@@ -0,0 +1,35 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDrift(t *testing.T) {
+	r := MustParseRule("0 9 * * 1/2/3/4/5")
+	nine := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.UTC)
+	cases := []struct {
+		expected, actual time.Time
+		kind             DriftKind
+		lateness         time.Duration
+		str              string
+	}{
+		{nine, nine.Add(2 * time.Second), OnSchedule, 2 * time.Second, "on schedule"},
+		{nine, nine.Add(90 * time.Second), Late, 90 * time.Second, "late by 1m30s"},
+		{nine, nine.Add(-time.Second), Early, -time.Second, "early by 1s"},
+		{time.Time{}, nine.Add(3 * time.Hour), Late, 3 * time.Hour, "late by 3h0m0s"},
+		{time.Time{}, nine.Add(30 * time.Second), OnSchedule, 30 * time.Second, "on schedule"},
+		{nine.Add(time.Hour), nine.Add(time.Hour), Unscheduled, 0, "unscheduled run at 2026-03-04T10:00:00Z"},
+		{nine.Add(time.Second), nine.Add(time.Second), Unscheduled, 0, "unscheduled run at 2026-03-04T09:00:01Z"},
+	}
+	for i, c := range cases {
+		d := r.Drift(c.expected, c.actual)
+		if d.Kind != c.kind || d.Lateness != c.lateness || d.String() != c.str {
+			t.Errorf("%d) %s %s '%s' Did not match! %s %s '%s'", i, d.Kind, d.Lateness, d, c.kind, c.lateness, c.str)
+		}
+	}
+
+	if d := MustParseRule("0 0 30 2 *").Drift(time.Time{}, nine); d.Kind != Unscheduled {
+		t.Errorf("%s != %s", d.Kind, Unscheduled)
+	}
+}
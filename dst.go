@@ -0,0 +1,189 @@
+package ticktickrules
+
+import (
+	"sort"
+	"time"
+)
+
+// FallBackPolicy controls how a rule treats wall clock times that occur twice when the clocks go back at the end
+// of daylight saving time, for example 01:30 in Europe/London on the last Sunday of October.
+type FallBackPolicy int
+
+const (
+	// FireEarliest fires only on the first occurrence of a repeated wall clock time. This is the default.
+	FireEarliest FallBackPolicy = iota
+	// FireOnce fires only on the first occurrence for rules with specific hours, but on both occurrences for rules
+	// that match every hour. This is what most cron daemons do.
+	FireOnce
+	// FireTwice fires on both occurrences of a repeated wall clock time.
+	FireTwice
+)
+
+// SpringForwardPolicy controls how a rule treats wall clock times that are skipped when the clocks go forward at
+// the start of daylight saving time, for example 01:30 in Europe/London on the last Sunday of March.
+type SpringForwardPolicy int
+
+const (
+	// SkipMissing does not fire for skipped wall clock times. This is the default.
+	SkipMissing SpringForwardPolicy = iota
+	// ShiftMissing fires once at the instant the clocks go forward for any skipped wall clock times that match.
+	ShiftMissing
+)
+
+// WithFallBackPolicy returns a copy of the rule using the given policy for repeated wall clock times.
+func (r *Rule) WithFallBackPolicy(p FallBackPolicy) *Rule {
+	out := *r
+	out.fallBack = p
+	return &out
+}
+
+// WithSpringForwardPolicy returns a copy of the rule using the given policy for skipped wall clock times.
+func (r *Rule) WithSpringForwardPolicy(p SpringForwardPolicy) *Rule {
+	out := *r
+	out.springForward = p
+	return &out
+}
+
+// In returns a copy of the rule bound to the given location, as if it had been parsed with a CRON_TZ prefix.
+func (r *Rule) In(loc *time.Location) *Rule {
+	out := *r
+	out.location = loc
+	return &out
+}
+
+// firesTwice returns whether the rule fires on both occurrences of a repeated wall clock time.
+func (r *Rule) firesTwice() bool {
+	return r.fallBack == FireTwice || (r.fallBack == FireOnce && len(r.hour) == 0 && r.timeOfDay == nil)
+}
+
+// sameWallClock returns whether a and b show the same date, hour, and minute.
+func sameWallClock(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd && a.Hour() == b.Hour() && a.Minute() == b.Minute()
+}
+
+// isSecondPass returns whether the wall clock time of t already occurred once before because the clocks went back.
+func isSecondPass(t time.Time) bool {
+	start, _ := t.ZoneBounds()
+	if start.IsZero() {
+		return false
+	}
+	change := zoneOffsetChange(t)
+	return change > 0 && sameWallClock(t, t.Add(-time.Duration(change)*time.Second))
+}
+
+// secondPass returns the later instant showing the same wall clock time as t, if the clocks go back after t.
+func secondPass(t time.Time) (time.Time, bool) {
+	_, end := t.ZoneBounds()
+	if end.IsZero() {
+		return time.Time{}, false
+	}
+	_, offset := t.Zone()
+	_, next := end.Zone()
+	if next >= offset {
+		return time.Time{}, false
+	}
+	later := t.Add(time.Duration(offset-next) * time.Second)
+	return later, sameWallClock(t, later)
+}
+
+// instants returns the instants at which the wall clock in loc shows the given date, hour, and minute, earliest
+// first, filtered by the daylight saving policies of the rule. Wall clock times that are skipped when the clocks
+// go forward have no instants.
+func (r *Rule) instants(date time.Time, hour, minute int, loc *time.Location) []time.Time {
+	t := time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, loc)
+	if t.Hour() != hour || t.Minute() != minute {
+		if r.springForward == ShiftMissing {
+			return []time.Time{gapEnd(t)}
+		}
+		return nil
+	}
+	if isSecondPass(t) {
+		first := t.Add(-time.Duration(zoneOffsetChange(t)) * time.Second)
+		if r.firesTwice() {
+			return []time.Time{first, t}
+		}
+		return []time.Time{first}
+	}
+	if later, ok := secondPass(t); ok && r.firesTwice() {
+		return []time.Time{t, later}
+	}
+	return []time.Time{t}
+}
+
+// gapEnd returns the instant the clocks went forward, given a time normalised from a skipped wall clock time.
+func gapEnd(t time.Time) time.Time {
+	start, end := t.ZoneBounds()
+	_, offset := t.Zone()
+	if !end.IsZero() {
+		if _, next := end.Zone(); next > offset {
+			return end
+		}
+	}
+	return start
+}
+
+// matchesGap returns whether t falls within the first minute after the clocks went forward and any of the skipped
+// wall clock times are matched by the rule.
+func (r *Rule) matchesGap(t time.Time) bool {
+	start, _ := t.ZoneBounds()
+	if start.IsZero() || t.Before(start) || t.Sub(start) >= time.Minute {
+		return false
+	}
+	skipped := -zoneOffsetChange(t) / 60
+	for i := 1; i <= skipped; i++ {
+		wall := time.Date(start.Year(), start.Month(), start.Day(), start.Hour(), start.Minute()-i, 0, 0, time.UTC)
+		if r.matchesWallClock(wall) {
+			return true
+		}
+	}
+	return false
+}
+
+// zoneOffsetChange returns how many seconds the clocks went back at the start of the zone period containing t. It
+// is negative if the clocks went forward.
+func zoneOffsetChange(t time.Time) int {
+	start, _ := t.ZoneBounds()
+	_, offset := t.Zone()
+	_, previous := start.Add(-time.Nanosecond).Zone()
+	return previous - offset
+}
+
+// hasTransition returns whether the clocks in loc may change on or around the given date. Such days can be
+// longer or shorter than 24 hours and need their occurrences resolved one by one.
+func hasTransition(date time.Time, loc *time.Location) bool {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+	_, end := start.Add(-3 * time.Hour).ZoneBounds()
+	return !end.IsZero() && end.Before(start.Add(27*time.Hour))
+}
+
+// dayInstants returns every occurrence of the rule on the given date in loc in order, assuming the date itself
+// matches the rule.
+func (r *Rule) dayInstants(date time.Time, loc *time.Location, hours, minutes []int) []time.Time {
+	var out []time.Time
+	for _, h := range hours {
+		for _, m := range minutes {
+			out = append(out, r.instants(date, h, m, loc)...)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Before(out[j])
+	})
+
+	// skipped wall clock times may have been shifted onto the same instant
+	n := 0
+	for i, t := range out {
+		if i == 0 || !t.Equal(out[n-1]) {
+			out[n] = t
+			n++
+		}
+	}
+	return out[:n]
+}
+
+// civilDate returns midnight UTC on the given date. Dates are stepped in UTC so that days are always 24 hours
+// long regardless of the location the rule is evaluated in.
+func civilDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
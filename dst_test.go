@@ -0,0 +1,138 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skip(err.Error())
+	}
+	return loc
+}
+
+// clocks go back from 02:00 BST to 01:00 GMT on 2000-10-29 in Europe/London
+var londonFallBack = time.Date(2000, 10, 29, 1, 0, 0, 0, time.UTC)
+
+func TestFallBackFireEarliest(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/London")
+	r := MustNewRule("30", "1", "*", "*", "*").In(loc)
+
+	n := r.NextAfter(time.Date(2000, 10, 29, 0, 0, 0, 0, loc))
+	e := londonFallBack.Add(-30 * time.Minute)
+	if !n.Equal(e) {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = r.NextAfter(n)
+	e = time.Date(2000, 10, 30, 1, 30, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	if r.Matches(londonFallBack.Add(30 * time.Minute)) {
+		t.Error("second 01:30 should not match")
+	}
+
+	// the same applies in zones where time.Date picks the later of the two instants
+	loc = mustLoadLocation(t, "America/New_York")
+	r = MustNewRule("30", "1", "*", "*", "*").In(loc)
+	n = r.NextAfter(time.Date(2000, 10, 29, 0, 0, 0, 0, loc))
+	e = time.Date(2000, 10, 29, 5, 30, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("3) %s != %s", n, e)
+	}
+}
+
+func TestFallBackFireTwice(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/London")
+	r := MustNewRule("30", "1", "*", "*", "*").In(loc).WithFallBackPolicy(FireTwice)
+
+	n := r.NextAfter(time.Date(2000, 10, 29, 0, 0, 0, 0, loc))
+	e := londonFallBack.Add(-30 * time.Minute)
+	if !n.Equal(e) {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = r.NextAfter(n)
+	e = londonFallBack.Add(30 * time.Minute)
+	if !n.Equal(e) {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	if !r.Matches(n) {
+		t.Errorf("%s should match", n)
+	}
+	if f := r.Floor(londonFallBack.Add(45 * time.Minute)); !f.Equal(e) {
+		t.Errorf("3) %s != %s", f, e)
+	}
+
+	// a later wall clock time in the first pass comes before the repeat of an earlier one
+	r = MustNewRule("30/50", "1", "*", "*", "*").In(loc).WithFallBackPolicy(FireTwice)
+	n = r.NextAfter(londonFallBack.Add(-20 * time.Minute))
+	e = londonFallBack.Add(-10 * time.Minute)
+	if !n.Equal(e) {
+		t.Errorf("4) %s != %s", n, e)
+	}
+}
+
+func TestFallBackFireOnce(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/London")
+
+	r := MustNewRule("30", "1", "*", "*", "*").In(loc).WithFallBackPolicy(FireOnce)
+	n := r.NextAfter(londonFallBack.Add(-30 * time.Minute))
+	e := time.Date(2000, 10, 30, 1, 30, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("1) %s != %s", n, e)
+	}
+
+	r = MustNewRule("*/30", "*", "*", "*", "*").In(loc).WithFallBackPolicy(FireOnce)
+	n = r.NextAfter(londonFallBack.Add(-30 * time.Minute))
+	if !n.Equal(londonFallBack) {
+		t.Errorf("2) %s != %s", n, londonFallBack)
+	}
+}
+
+// clocks go forward from 01:00 GMT to 02:00 BST on 2000-03-26 in Europe/London
+var londonSpringForward = time.Date(2000, 3, 26, 1, 0, 0, 0, time.UTC)
+
+func TestSpringForwardSkipMissing(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/London")
+	r := MustNewRule("30", "1", "*", "*", "*").In(loc)
+
+	n := r.NextAfter(time.Date(2000, 3, 25, 12, 0, 0, 0, loc))
+	e := time.Date(2000, 3, 27, 0, 30, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+}
+
+func TestSpringForwardShiftMissing(t *testing.T) {
+	loc := mustLoadLocation(t, "Europe/London")
+	r := MustNewRule("*/15", "1", "*", "*", "*").In(loc).WithSpringForwardPolicy(ShiftMissing)
+
+	n := r.NextAfter(time.Date(2000, 3, 25, 12, 0, 0, 0, loc))
+	if !n.Equal(londonSpringForward) {
+		t.Errorf("1) %s != %s", n, londonSpringForward)
+	}
+	if !r.Matches(n) {
+		t.Errorf("%s should match", n)
+	}
+
+	// all of the skipped times are shifted onto the same instant
+	n = r.NextAfter(n)
+	e := time.Date(2000, 3, 27, 0, 0, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	if c := r.CountBetween(time.Date(2000, 3, 26, 0, 0, 0, 0, loc), e); c != 1 {
+		t.Errorf("%d != 1", c)
+	}
+
+	// the same applies in zones where time.Date normalises skipped times backwards
+	loc = mustLoadLocation(t, "America/New_York")
+	r = MustNewRule("30", "2", "*", "*", "*").In(loc).WithSpringForwardPolicy(ShiftMissing)
+	n = r.NextAfter(time.Date(2000, 4, 2, 0, 0, 0, 0, loc))
+	e = time.Date(2000, 4, 2, 7, 0, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("3) %s != %s", n, e)
+	}
+}
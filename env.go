@@ -0,0 +1,72 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FromEnv parses the expression in the environment variable key, or fallback if the variable is unset or blank.
+// Errors name the variable, or say that the fallback was used, so that a misconfigured service reports where the
+// bad schedule came from.
+func FromEnv(key, fallback string, opts ...ParseOption) (*Rule, error) {
+	expr, ok := os.LookupEnv(key)
+	if !ok || strings.TrimSpace(expr) == "" {
+		r, err := ParseRule(fallback, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("Default schedule for %s is invalid: %s", key, err)
+		}
+		return r, nil
+	}
+	r, err := ParseRule(expr, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("Environment variable %s is invalid: %s", key, err)
+	}
+	return r, nil
+}
+
+// RuleValue implements flag.Value so that a rule can be given as a command line flag:
+//
+//	var schedule ticktickrules.RuleValue
+//	flag.Var(&schedule, "schedule", "cron expression to run the job on")
+//
+// The expression is parsed with ParseRule when the flag is set, so invalid expressions are reported by the flag
+// package along with the usage. Rule is nil until the flag is set, or may be given a default beforehand. RuleValue
+// also has the Type method needed to be used with github.com/spf13/pflag.
+type RuleValue struct {
+	Rule *Rule
+	// Options are passed to ParseRule, for example to allow extra whitespace.
+	Options []ParseOption
+}
+
+// Set parses the expression and stores the rule. The error includes the most complete suggestion from
+// SuggestCorrections, if there is one.
+func (v *RuleValue) Set(expr string) error {
+	r, err := ParseRule(expr, v.Options...)
+	if err != nil {
+		if s := SuggestCorrections(expr); len(s) > 0 {
+			return fmt.Errorf("%s (hint: %s)", err, s[len(s)-1])
+		}
+		return err
+	}
+	v.Rule = r
+	return nil
+}
+
+// Get returns the rule, implementing flag.Getter.
+func (v *RuleValue) Get() interface{} {
+	return v.Rule
+}
+
+// Type names the type of the flag value in pflag usage messages.
+func (v *RuleValue) Type() string {
+	return "cron"
+}
+
+// String returns the expression of the rule, or "" if it has not been set.
+func (v *RuleValue) String() string {
+	if v == nil || v.Rule == nil {
+		return ""
+	}
+	return v.Rule.String()
+}
@@ -0,0 +1,68 @@
+package ticktickrules
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TICKTICK_SCHEDULE", "0 9 * * MON")
+	if r, err := FromEnv("TICKTICK_SCHEDULE", "0 0 * * *"); err != nil || r.String() != "0 9 * * MON" {
+		t.Errorf("%v %v", r, err)
+	}
+	if r, err := FromEnv("TICKTICK_MISSING", "0 0 * * *"); err != nil || r.String() != "0 0 * * *" {
+		t.Errorf("%v %v", r, err)
+	}
+	t.Setenv("TICKTICK_BLANK", "  ")
+	if r, err := FromEnv("TICKTICK_BLANK", "0 0 * * *"); err != nil || r.String() != "0 0 * * *" {
+		t.Errorf("%v %v", r, err)
+	}
+	t.Setenv("TICKTICK_SPACED", "0  9 * * *")
+	if r, err := FromEnv("TICKTICK_SPACED", "", AllowExtraWhitespace()); err != nil || r.StringNormalized() != "0 9 * * *" {
+		t.Errorf("%v %v", r, err)
+	}
+
+	t.Setenv("TICKTICK_BAD", "0 25 * * *")
+	if _, err := FromEnv("TICKTICK_BAD", "0 0 * * *"); err == nil || !strings.Contains(err.Error(), "Environment variable TICKTICK_BAD") {
+		t.Errorf("%v should name the variable", err)
+	}
+	if _, err := FromEnv("TICKTICK_MISSING", "bad"); err == nil || !strings.Contains(err.Error(), "Default schedule for TICKTICK_MISSING") {
+		t.Errorf("%v should mention the default", err)
+	}
+}
+
+func TestRuleValue(t *testing.T) {
+	var v RuleValue
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Var(&v, "schedule", "")
+	if v.String() != "" {
+		t.Errorf("'%s' should be empty", v.String())
+	}
+	if err := fs.Parse([]string{"-schedule", "*/5 * * * *"}); err != nil {
+		t.Fatal(err)
+	}
+	if v.Rule == nil || v.String() != "*/5 * * * *" {
+		t.Errorf("'%s' Did not match!", v.String())
+	}
+	if err := fs.Parse([]string{"-schedule", "*/5 * * *"}); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+	if r, ok := fs.Lookup("schedule").Value.(flag.Getter).Get().(*Rule); !ok || r != v.Rule {
+		t.Errorf("%v Did not match!", r)
+	}
+	if v.Type() != "cron" {
+		t.Errorf("'%s' Did not match!", v.Type())
+	}
+
+	err := v.Set("0 0 9 * * MON")
+	if err == nil || !strings.HasSuffix(err.Error(), "(hint: 6 fields given, did you mean to drop the seconds field: '0 9 * * MON')") {
+		t.Errorf("%v should include a hint", err)
+	}
+	v.Options = []ParseOption{AllowExtraWhitespace()}
+	if err := v.Set(" 0 9 * * MON "); err != nil {
+		t.Error(err)
+	}
+}
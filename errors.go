@@ -0,0 +1,84 @@
+package ticktickrules
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoMatchInHorizon is matched by errors.Is for the *HorizonError returned when a rule has no occurrence within
+// its search horizon.
+var ErrNoMatchInHorizon = errors.New("No occurrence within the search horizon")
+
+// SyntaxError is returned when an item of a rule is not in one of the supported forms.
+type SyntaxError struct {
+	// Item is the rule item as it was given.
+	Item string
+	// Reason describes what is wrong with the item.
+	Reason string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("Rule item '%s' %s", e.Item, e.Reason)
+}
+
+// RangeError is returned when a number in a rule item is outside of the values allowed for it. Numbers too large
+// to be represented are also reported as a RangeError.
+type RangeError struct {
+	// Field is the name of the field the item belongs to, such as "Minute".
+	Field string
+	// Item is the rule item as it was given.
+	Item string
+	// Value is the offending number as it was written.
+	Value string
+	// Min and Max are the bounds of the allowed values.
+	Min, Max int
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("%s rule invalid: %s in '%s' is outside of %d-%d", e.Field, e.Value, e.Item, e.Min, e.Max)
+}
+
+// HorizonError is returned by Rule.Next when a rule has no occurrence within its search horizon. It wraps
+// ErrNoMatchInHorizon.
+type HorizonError struct {
+	// Rule is the rule as it was given.
+	Rule string
+	// From is the time the search started from.
+	From time.Time
+	// Horizon is how far the search looked.
+	Horizon time.Duration
+	// NeverMatches is set if the rule has no occurrence on any date, such as "0 0 30 2 *", rather than none
+	// within the horizon.
+	NeverMatches bool
+}
+
+func (e *HorizonError) Error() string {
+	if e.NeverMatches {
+		return fmt.Sprintf("Rule '%s' never matches", e.Rule)
+	}
+	return fmt.Sprintf("Rule '%s' has no occurrence within %s of %s", e.Rule, e.Horizon, e.From.Format(time.RFC3339))
+}
+
+func (e *HorizonError) Unwrap() error {
+	return ErrNoMatchInHorizon
+}
+
+// IsNeverMatches returns whether err is a *HorizonError for a rule that has no occurrence on any date, so that
+// callers can tell a rule that is wrong from one that does not occur within the horizon they asked for.
+func IsNeverMatches(err error) bool {
+	var he *HorizonError
+	return errors.As(err, &he) && he.NeverMatches
+}
+
+// PanicError is the error reported by a Scheduler when a job panics.
+type PanicError struct {
+	// Value is the value the job panicked with.
+	Value interface{}
+	// Stack is the stack trace of the goroutine at the time of the panic.
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("Job panicked: %v", e.Value)
+}
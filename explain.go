@@ -0,0 +1,96 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FieldMatch describes whether a single field of a rule matched a time.
+type FieldMatch struct {
+	// Field is the name of the field, such as "Hour"
+	Field string
+	// Value is the value of the field for the time being explained
+	Value int
+	// Rule is the part of the rule the value was checked against
+	Rule string
+	// Matched is whether the value was allowed
+	Matched bool
+}
+
+// MatchReport explains why a rule did or did not match a time.
+type MatchReport struct {
+	// Time is the time being explained, in the location the rule was evaluated in
+	Time time.Time
+	// Matched is the same as the result of Matches
+	Matched bool
+	// Fields lists every field and restriction of the rule in order
+	Fields []FieldMatch
+	// Note explains results that do not follow from the fields alone, such as daylight saving time adjustments
+	Note string
+}
+
+// String renders the report as one line per field, for example "Hour: 3 does not match 2".
+func (m MatchReport) String() string {
+	var sb strings.Builder
+	verdict := "matches"
+	if !m.Matched {
+		verdict = "does not match"
+	}
+	fmt.Fprintf(&sb, "%s %s\n", m.Time.Format(time.RFC3339), verdict)
+	for _, f := range m.Fields {
+		verdict = "matches"
+		if !f.Matched {
+			verdict = "does not match"
+		}
+		fmt.Fprintf(&sb, "  %s: %d %s %s\n", f.Field, f.Value, verdict, f.Rule)
+	}
+	if m.Note != "" {
+		fmt.Fprintf(&sb, "  %s\n", m.Note)
+	}
+	return sb.String()
+}
+
+// Explain checks t against each field of the rule individually, to help answer why a rule did or did not fire at a
+// particular time.
+func (r *Rule) Explain(t time.Time) MatchReport {
+	t = r.localize(t)
+	out := MatchReport{Time: t, Matched: r.Matches(t)}
+
+	add := func(name string, value int, rule string, matched bool) {
+		out.Fields = append(out.Fields, FieldMatch{Field: name, Value: value, Rule: rule, Matched: matched})
+	}
+	m := newMoment(t)
+	if r.timeOfDay != nil {
+		add("Time of Day", t.Hour()*100+t.Minute(), "@"+r.timeOfDayName, r.matchesClock(&m))
+	} else {
+		add(minuteField.name, t.Minute(), r.minuteRule, len(r.minute) == 0 || doesMatch(t.Minute(), r.minute))
+		add(hourField.name, t.Hour(), r.hourRule, len(r.hour) == 0 || doesMatch(t.Hour(), r.hour))
+	}
+	add(dayOfMonthField.name, t.Day(), r.dayOfMonthRule, r.matchesDayOfMonth(&m))
+	add(monthField.name, int(t.Month()), r.monthRule, len(r.month) == 0 || doesMatch(int(t.Month()), r.month))
+	add(dayOfWeekField.name, int(t.Weekday()), r.dayOfWeekRule,
+		(len(r.dayOfWeek) == 0 || doesMatch(int(t.Weekday()), r.dayOfWeek)) && r.matchesOccurrence(&m))
+
+	if len(r.weeksOfMonth) > 0 {
+		week := (t.Day()-1)/7 + 1
+		add("Week of Month", week, r.WeeksOfMonth().String(), doesMatch(week, r.weeksOfMonth))
+	}
+	if len(r.isoWeeks) > 0 {
+		_, week := t.ISOWeek()
+		add("ISO Week", week, r.ISOWeeks().String(), doesMatch(week, r.isoWeeks))
+	}
+	if r.calendar != nil {
+		add("Calendar", t.Day(), "not excluded", !r.calendar.IsExcluded(t))
+	}
+	if r.businessDay != 0 {
+		add("Business Day", t.Day(), fmt.Sprintf("business day %d", r.businessDay), r.matchesBusinessDay(t))
+	}
+
+	if r.matchesWallClock(t) && !out.Matched {
+		out.Note = "the wall clock time was repeated when the clocks went back and the fall back policy only fires once"
+	} else if !r.matchesWallClock(t) && out.Matched {
+		out.Note = "a wall clock time skipped when the clocks went forward was shifted to this time"
+	}
+	return out
+}
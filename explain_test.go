@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	r := MustParseRule("0 3 * * 1/2/3/4/5")
+	m := r.Explain(time.Date(2000, 4, 29, 3, 0, 0, 0, time.UTC))
+	if m.Matched {
+		t.Error("Saturday should not match")
+	}
+	if len(m.Fields) != 5 {
+		t.Errorf("%d != 5", len(m.Fields))
+		return
+	}
+	for i, f := range m.Fields {
+		if f.Matched != (f.Field != "Day of Week") {
+			t.Errorf("%d) %s matched=%v", i, f.Field, f.Matched)
+		}
+	}
+	if m.Fields[4].Value != 6 || m.Fields[4].Rule != "1/2/3/4/5" {
+		t.Errorf("%d '%s' Did not match!", m.Fields[4].Value, m.Fields[4].Rule)
+	}
+	if !strings.Contains(m.String(), "Day of Week: 6 does not match 1/2/3/4/5") {
+		t.Errorf("'%s' Did not match!", m.String())
+	}
+}
+
+func TestExplainRestrictions(t *testing.T) {
+	r := mustRule(mustRule(MustParseRule("0 9 * * *").WithISOWeeks(2)).WithBusinessDay(1))
+	m := r.Explain(time.Date(2001, 1, 1, 9, 0, 0, 0, time.UTC))
+	if m.Matched || len(m.Fields) != 7 {
+		t.Errorf("%v %d", m.Matched, len(m.Fields))
+		return
+	}
+	if m.Fields[5].Field != "ISO Week" || m.Fields[5].Value != 1 || m.Fields[5].Matched {
+		t.Errorf("%+v", m.Fields[5])
+	}
+	if m.Fields[6].Field != "Business Day" || !m.Fields[6].Matched {
+		t.Errorf("%+v", m.Fields[6])
+	}
+}
+
+func TestExplainFallBack(t *testing.T) {
+	r := MustParseRule("30 1 * * *").In(mustLoadLocation(t, "Europe/London"))
+	m := r.Explain(londonFallBack.Add(30 * time.Minute))
+	if m.Matched || m.Note == "" {
+		t.Errorf("%v '%s'", m.Matched, m.Note)
+	}
+}
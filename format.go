@@ -0,0 +1,220 @@
+package ticktickrules
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FieldValues is the expanded set of values matched by a single field of a rule. An empty set matches any value.
+type FieldValues []int
+
+// String renders the values as a sorted comma separated list, or "*" if any value is matched.
+func (f FieldValues) String() string {
+	return f.format(nil, 0)
+}
+
+// ruleItem renders the values in the form accepted by NewRule, such as "*" or "0/15/30/45".
+func (f FieldValues) ruleItem() string {
+	return strings.Replace(f.String(), ",", "/", -1)
+}
+
+// format renders the values using the given names, indexed from offset, where available.
+func (f FieldValues) format(names []string, offset int) string {
+	if len(f) == 0 {
+		return "*"
+	}
+	parts := make([]string, len(f))
+	for i, v := range f {
+		if names != nil && v-offset >= 0 && v-offset < len(names) {
+			parts[i] = names[v-offset]
+		} else {
+			parts[i] = strconv.Itoa(v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+var monthNames = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+var dayOfWeekNames = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT", "SUN"}
+
+// normalizeField returns a sorted, de-duplicated copy of the values. The empty set is returned when the values
+// cover the whole range from min to max.
+func normalizeField(values []int, min, max int) FieldValues {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(FieldValues, len(values))
+	copy(out, values)
+	out = sortValues(out)
+	if len(out) == max-min+1 && out[0] == min && out[len(out)-1] == max {
+		return nil
+	}
+	return out
+}
+
+// Minutes returns the minutes matched by the rule.
+func (r *Rule) Minutes() FieldValues {
+	return normalizeField(r.minute, minuteField.min, minuteField.max)
+}
+
+// Hours returns the hours matched by the rule.
+func (r *Rule) Hours() FieldValues {
+	return normalizeField(r.hour, hourField.min, hourField.max)
+}
+
+// DaysOfMonth returns the days of the month matched by the rule.
+func (r *Rule) DaysOfMonth() FieldValues {
+	return normalizeField(r.dayOfMonth, dayOfMonthField.min, dayOfMonthField.max)
+}
+
+// Months returns the months matched by the rule.
+func (r *Rule) Months() FieldValues {
+	return normalizeField(r.month, monthField.min, monthField.max)
+}
+
+// DaysOfWeek returns the days of the week matched by the rule.
+func (r *Rule) DaysOfWeek() FieldValues {
+	return normalizeField(r.dayOfWeek, dayOfWeekField.min, dayOfWeekField.max)
+}
+
+// StringNormalized renders the rule in its canonical expanded form, for example "*/20 1/2 * * *" becomes
+// "0,20,40 1,2 * * *". This shows what the rule actually matches regardless of how it was written.
+func (r *Rule) StringNormalized() string {
+	return r.locationPrefix() + strings.Join([]string{
+		r.clockString(),
+		r.dayOfMonthString(r.DaysOfMonth().String()),
+		r.Months().String(),
+		r.dayOfWeekString(nil),
+	}, " ")
+}
+
+// StringNormalizedNames is like StringNormalized but renders months and days of the week by their three letter
+// names, for example "0 9 * JAN,JUL MON".
+func (r *Rule) StringNormalizedNames() string {
+	return r.locationPrefix() + strings.Join([]string{
+		r.clockString(),
+		r.dayOfMonthString(r.DaysOfMonth().String()),
+		r.Months().format(monthNames, 1),
+		r.dayOfWeekString(dayOfWeekNames),
+	}, " ")
+}
+
+// Fingerprint returns a stable hex encoded SHA-256 hash of the normalized rule. Rules that match exactly the same
+// times have the same fingerprint regardless of how they were written, so it can be used to key leases and dedupe
+// identical schedules across nodes.
+//
+// The restrictions added with the With methods are included. A Holidays calendar is included by its dates; other
+// calendars are included by their type, and by their String method if they have one, so they should implement
+// fmt.Stringer for differently configured calendars of the same type to be told apart.
+func (r *Rule) Fingerprint() string {
+	key := fmt.Sprintf("%s fallback=%d springforward=%d", r.StringNormalized(), r.fallBack, r.springForward)
+	if r.eitherDay {
+		key += " eitherday"
+	}
+	if r.granularity == SecondGranularity {
+		key += " granularity=second"
+	}
+	if len(r.isoWeeks) > 0 {
+		key += " isoweeks=" + r.ISOWeeks().String()
+	}
+	if len(r.weeksOfMonth) > 0 {
+		key += " weeksofmonth=" + r.WeeksOfMonth().String()
+	}
+	if r.businessDay != 0 {
+		key += " businessday=" + strconv.Itoa(r.businessDay)
+	}
+	if r.calendar != nil {
+		key += " calendar=" + calendarKey(r.calendar)
+	}
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// calendarKey returns a stable description of a calendar for use in a Fingerprint.
+func calendarKey(cal Calendar) string {
+	if h, ok := cal.(Holidays); ok {
+		dates := make([]string, 0, len(h))
+		for d, excluded := range h {
+			if excluded {
+				dates = append(dates, d.Format("2006-01-02"))
+			}
+		}
+		sort.Strings(dates)
+		return "holidays:" + strings.Join(dates, ",")
+	}
+	key := fmt.Sprintf("%T", cal)
+	if s, ok := cal.(fmt.Stringer); ok {
+		key += ":" + s.String()
+	}
+	return key
+}
+
+// restriction returns a description of the first setting of the rule that is not part of its five fields and is
+// lost when the rule is written in another form, such as its calendar, or "" if there is none.
+func (r *Rule) restriction() string {
+	switch {
+	case r.calendar != nil:
+		return "a calendar"
+	case len(r.isoWeeks) > 0:
+		return "ISO weeks"
+	case len(r.weeksOfMonth) > 0:
+		return "weeks of the month"
+	case r.businessDay != 0:
+		return "a business day"
+	case r.timeOfDay != nil:
+		return "a resolved time of day"
+	}
+	return ""
+}
+
+// clockString returns the normalized minute and hour fields, or "@name" if the rule has a TimeOfDay.
+func (r *Rule) clockString() string {
+	if r.timeOfDay != nil {
+		return "@" + r.timeOfDayName
+	}
+	return r.Minutes().String() + " " + r.Hours().String()
+}
+
+// restrictsBothDays returns whether both the day of month and the day of week fields are restricted.
+func (r *Rule) restrictsBothDays() bool {
+	return (len(r.dayOfMonth) > 0 || r.dayOfMonthLast) && (len(r.dayOfWeek) > 0 || r.hasOccurrence())
+}
+
+// dayOfMonthString returns "L" or "L-N" for rules counting from the end of the month, otherwise the given rendering
+// of the days of the month.
+func (r *Rule) dayOfMonthString(days string) string {
+	if !r.dayOfMonthLast {
+		return days
+	} else if r.dayOfMonthOffset > 0 {
+		return "L-" + strconv.Itoa(r.dayOfMonthOffset)
+	}
+	return "L"
+}
+
+// dayOfWeekString renders the day of week field including any occurrence within the month.
+func (r *Rule) dayOfWeekString(names []string) string {
+	return r.DaysOfWeek().format(names, 0) + r.occurrenceSuffix()
+}
+
+// occurrenceSuffix returns the "#K" or "L" suffix of the day of week field, or "".
+func (r *Rule) occurrenceSuffix() string {
+	if r.dayOfWeekLast {
+		return "L"
+	} else if r.dayOfWeekNth > 0 {
+		return "#" + strconv.Itoa(r.dayOfWeekNth)
+	}
+	return ""
+}
+
+// locationPrefix returns the CRON_TZ prefix for rules bound to a location.
+func (r *Rule) locationPrefix() string {
+	if r.location == nil {
+		return ""
+	}
+	return "CRON_TZ=" + r.location.String() + " "
+}
@@ -0,0 +1,85 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStringNormalized(t *testing.T) {
+	r := MustNewRule("*/20", "1/2", "*", "*", "*/1")
+	if s := r.StringNormalized(); s != "0,20,40 1,2 * * *" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+
+	r = MustNewRule("30/10", "0", "1", "1/7", "1/5")
+	if s := r.StringNormalized(); s != "10,30 0 1 1,7 1,5" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestStringNormalizedNames(t *testing.T) {
+	r := MustNewRule("0", "9", "*", "1/7", "1/5")
+	if s := r.StringNormalizedNames(); s != "0 9 * JAN,JUL MON,FRI" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestFieldValuesString(t *testing.T) {
+	r := MustNewRule("*/15", "*", "*", "*", "*")
+	if s := r.Minutes().String(); s != "0,15,30,45" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if s := r.Hours().String(); s != "*" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := MustParseRule("*/15 0/12 * * *")
+	b := MustParseRule("0/15/30/45 0/12 * * *")
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("%s != %s", a.Fingerprint(), b.Fingerprint())
+	}
+	if len(a.Fingerprint()) != 64 {
+		t.Errorf("'%s' should be 64 hex characters", a.Fingerprint())
+	}
+
+	c := MustParseRule("*/15 0/13 * * *")
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Error("different rules should have different fingerprints")
+	}
+	if a.Fingerprint() == a.WithFallBackPolicy(FireTwice).Fingerprint() {
+		t.Error("different policies should have different fingerprints")
+	}
+}
+
+func TestFingerprintRestrictions(t *testing.T) {
+	r := MustParseRule("0 0 * * *")
+	christmas := time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC)
+	restricted := []*Rule{
+		r,
+		mustRule(r.WithISOWeeks(1)),
+		mustRule(r.WithISOWeeks(2)),
+		mustRule(r.WithWeeksOfMonth(1)),
+		mustRule(r.WithBusinessDay(1)),
+		mustRule(r.WithBusinessDay(LastBusinessDay)),
+		r.WithCalendar(NewHolidays(christmas)),
+		r.WithCalendar(NewHolidays(christmas.AddDate(0, 0, 1))),
+	}
+	seen := make(map[string]int)
+	for i, x := range restricted {
+		if j, ok := seen[x.Fingerprint()]; ok {
+			t.Errorf("%d and %d should have different fingerprints", i, j)
+		}
+		seen[x.Fingerprint()] = i
+	}
+
+	// the same restrictions give the same fingerprint however they were added
+	if mustRule(r.WithISOWeeks(2, 1)).Fingerprint() != mustRule(r.WithISOWeeks(1, 2)).Fingerprint() {
+		t.Error("ISO weeks in another order should have the same fingerprint")
+	}
+	if r.WithCalendar(NewHolidays(christmas, christmas.AddDate(0, 0, 1))).Fingerprint() !=
+		r.WithCalendar(NewHolidays(christmas.AddDate(0, 0, 1), christmas)).Fingerprint() {
+		t.Error("holidays in another order should have the same fingerprint")
+	}
+}
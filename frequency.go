@@ -0,0 +1,66 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Frequency describes the gaps between consecutive occurrences of a rule.
+type Frequency struct {
+	// Min is the smallest gap seen between two occurrences.
+	Min time.Duration
+	// Max is the largest gap seen between two occurrences.
+	Max time.Duration
+	// Typical is the most common gap between two occurrences.
+	Typical time.Duration
+}
+
+// frequencyStart is the fixed point from which rules are analysed. It is the start of a leap year so that
+// rules pinned to the 29th of February are seen.
+var frequencyStart = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// frequencyHorizon is how far past frequencyStart occurrences are considered.
+const frequencyHorizon = 4*365*24*time.Hour + 24*time.Hour
+
+// frequencyMaxSamples caps the work done for very dense rules.
+const frequencyMaxSamples = 100000
+
+// Frequency analyses the occurrences of the rule over a few years and reports the minimum, maximum, and most
+// common gap between them. This is useful for deriving the expected heartbeat interval of a scheduled job.
+// The zero Frequency is returned if the rule matches fewer than twice in the analysed period.
+func (r *Rule) Frequency() Frequency {
+	end := frequencyStart.Add(frequencyHorizon)
+	counts := make(map[time.Duration]int)
+	var out Frequency
+
+	prev := r.Ceil(frequencyStart)
+	for samples := 0; samples < frequencyMaxSamples && !prev.After(end); samples++ {
+		next := r.NextAfter(prev)
+		if next.After(end) {
+			break
+		}
+		gap := next.Sub(prev)
+		if len(counts) == 0 || gap < out.Min {
+			out.Min = gap
+		}
+		if gap > out.Max {
+			out.Max = gap
+		}
+		counts[gap]++
+		prev = next
+	}
+
+	best := 0
+	for gap, c := range counts {
+		if c > best || (c == best && gap < out.Typical) {
+			best = c
+			out.Typical = gap
+		}
+	}
+	return out
+}
+
+// ApproxInterval returns the most common gap between occurrences of the rule, for example 15 minutes for
+// "*/15 * * * *". It returns 0 if the rule matches fewer than twice in the analysed period.
+func (r *Rule) ApproxInterval() time.Duration {
+	return r.Frequency().Typical
+}
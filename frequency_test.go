@@ -0,0 +1,47 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestApproxInterval(t *testing.T) {
+	r := MustNewRule("*/15", "*", "*", "*", "*")
+	if i := r.ApproxInterval(); i != 15*time.Minute {
+		t.Errorf("%s != 15m", i)
+	}
+}
+
+func TestFrequencyIrregular(t *testing.T) {
+	r := MustNewRule("0", "2/12", "*", "*", "*")
+	f := r.Frequency()
+	if f.Min != 10*time.Hour {
+		t.Errorf("min %s != 10h", f.Min)
+	}
+	if f.Max != 14*time.Hour {
+		t.Errorf("max %s != 14h", f.Max)
+	}
+	if f.Typical != 10*time.Hour {
+		t.Errorf("typical %s != 10h", f.Typical)
+	}
+}
+
+func TestFrequencyMonthly(t *testing.T) {
+	r := MustNewRule("0", "0", "31", "*", "*")
+	f := r.Frequency()
+	// jul 31 -> aug 31
+	if f.Min != 31*24*time.Hour {
+		t.Errorf("min %s != 31d", f.Min)
+	}
+	// mar 31 -> may 31
+	if f.Max != 61*24*time.Hour {
+		t.Errorf("max %s != 61d", f.Max)
+	}
+}
+
+func TestFrequencyNever(t *testing.T) {
+	r := MustNewRule("0", "0", "31", "2", "*")
+	if f := r.Frequency(); f != (Frequency{}) {
+		t.Errorf("%+v should be zero", f)
+	}
+}
@@ -0,0 +1,43 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+// FuzzParseRule checks that arbitrary expressions never cause a panic, and that any expression which parses can be
+// turned back into a string and parsed again to the same rule. Additional corpus entries can be added under
+// testdata/fuzz/FuzzParseRule.
+func FuzzParseRule(f *testing.F) {
+	for _, seed := range []string{
+		"* * * * *",
+		"*/5 * * * *",
+		"10/20/30 */5 1 2/3 *",
+		"0 9 * JAN/JUL MON/FRI",
+		"0 9 * * 5#3",
+		"CRON_TZ=Europe/London 0 9 * * *",
+		"0 2 * * * # nightly",
+		"99999999999999999999 * * * *",
+		"*/0 * * * *",
+		"-1 * * * *",
+	} {
+		f.Add(seed)
+	}
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+	f.Fuzz(func(t *testing.T, expr string) {
+		r, err := ParseRule(expr, Lenient())
+		if err != nil {
+			return
+		}
+		again, err := ParseRule(r.String())
+		if err != nil {
+			t.Fatalf("'%s' parsed from '%s' did not parse again: %s", r.String(), expr, err.Error())
+		}
+		if again.StringNormalized() != r.StringNormalized() {
+			t.Fatalf("'%s' != '%s'", again.StringNormalized(), r.StringNormalized())
+		}
+		r.Matches(from)
+		r.NextAfter(from)
+		r.Floor(from)
+	})
+}
@@ -0,0 +1,33 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// MaxGap is like MaxGapFrom starting from now.
+func (r *Rule) MaxGap(horizon time.Duration) (time.Duration, time.Time) {
+	return r.MaxGapFrom(SystemClock.Now(), horizon)
+}
+
+// MaxGapFrom returns the longest period within [from, from+horizon] without an occurrence of the rule, along with
+// when it starts, for checking that a heartbeat never goes silent for longer than an alert threshold. The periods
+// before the first occurrence and after the last one are included, so a rule that does not fire within the horizon
+// returns the whole horizon starting at from. The earliest gap is returned if several are the longest.
+func (r *Rule) MaxGapFrom(from time.Time, horizon time.Duration) (time.Duration, time.Time) {
+	end := from.Add(horizon)
+	longest, start := time.Duration(0), from
+	previous := from
+	for {
+		next := r.NextAfter(previous)
+		if next.After(end) {
+			next = end
+		}
+		if gap := next.Sub(previous); gap > longest {
+			longest, start = gap, previous
+		}
+		if !next.Before(end) {
+			return longest, start
+		}
+		previous = next
+	}
+}
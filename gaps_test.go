@@ -0,0 +1,33 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMaxGapFrom(t *testing.T) {
+	from := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		rule    string
+		horizon time.Duration
+		gap     time.Duration
+		start   time.Time
+	}{
+		{"*/5 * * * *", time.Hour, 5 * time.Minute, from},
+		{"0 9/17 * * *", 48 * time.Hour, 16 * time.Hour, from.Add(17 * time.Hour)},
+		{"0 9 * * 1/2/3/4/5", 14 * 24 * time.Hour, 72 * time.Hour, from.Add(4*24*time.Hour + 9*time.Hour)},
+		{"0 0 30 2 *", time.Hour, time.Hour, from},
+		{"30 * * * *", 10 * time.Minute, 10 * time.Minute, from},
+		{"5/10 * * * *", 2 * time.Hour, 55 * time.Minute, from.Add(10 * time.Minute)},
+	}
+	for _, c := range cases {
+		gap, start := MustParseRule(c.rule).In(time.UTC).MaxGapFrom(from, c.horizon)
+		if gap != c.gap || !start.Equal(c.start) {
+			t.Errorf("%s: %s %s Did not match! %s %s", c.rule, gap, start, c.gap, c.start)
+		}
+	}
+
+	if gap, _ := MustParseRule("* * * * *").MaxGap(time.Hour); gap > time.Minute {
+		t.Errorf("%s != %s", gap, time.Minute)
+	}
+}
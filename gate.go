@@ -0,0 +1,54 @@
+package ticktickrules
+
+import (
+	"sync"
+	"time"
+)
+
+// OccurrenceGate answers whether a job should fire now, at most once per occurrence of a rule. The time of the
+// last occurrence fired should be persisted by the caller and passed back in after a restart so that occurrences
+// are not repeated. Clocks stepping backwards never cause an occurrence to fire twice.
+type OccurrenceGate struct {
+	rule        *Rule
+	maxLateness time.Duration
+
+	mu        sync.Mutex
+	lastFired time.Time
+}
+
+// NewOccurrenceGate returns a gate for the rule that has already fired the occurrence at lastFired, which may be
+// the zero time. Occurrences more than maxLateness old are skipped rather than fired late; a maxLateness of 0
+// fires the most recent occurrence however late it is.
+func NewOccurrenceGate(r *Rule, lastFired time.Time, maxLateness time.Duration) *OccurrenceGate {
+	return &OccurrenceGate{rule: r, lastFired: lastFired, maxLateness: maxLateness}
+}
+
+// ShouldFire returns true if the most recent occurrence at or before now has not been fired yet, and records it
+// as fired.
+func (g *OccurrenceGate) ShouldFire(now time.Time) bool {
+	_, ok := g.Fire(now)
+	return ok
+}
+
+// Fire is like ShouldFire but also returns the occurrence being fired.
+func (g *OccurrenceGate) Fire(now time.Time) (time.Time, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	occurrence := g.rule.Floor(now)
+	if occurrence == farPast || !occurrence.After(g.lastFired) {
+		return time.Time{}, false
+	}
+	g.lastFired = occurrence
+	if g.maxLateness > 0 && now.Sub(occurrence) > g.maxLateness {
+		return time.Time{}, false
+	}
+	return occurrence, true
+}
+
+// LastFired returns the most recent occurrence that was fired or skipped. This is the value to persist.
+func (g *OccurrenceGate) LastFired() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.lastFired
+}
@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOccurrenceGate(t *testing.T) {
+	g := NewOccurrenceGate(MustParseRule("0 * * * *"), time.Time{}, 0)
+	now := time.Date(2000, 4, 28, 14, 0, 10, 0, time.UTC)
+
+	if !g.ShouldFire(now) {
+		t.Error("1) should fire")
+	}
+	if g.ShouldFire(now.Add(time.Second)) {
+		t.Error("2) should not fire twice")
+	}
+
+	// the clock steps backwards over the occurrence
+	if g.ShouldFire(now.Add(-time.Minute)) {
+		t.Error("3) should not fire after stepping back")
+	}
+	if g.ShouldFire(now) {
+		t.Error("4) should not fire again after stepping forward")
+	}
+
+	occurrence, ok := g.Fire(now.Add(time.Hour))
+	if !ok || occurrence != time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC) {
+		t.Errorf("5) %s %v", occurrence, ok)
+	}
+
+	// a restart with the persisted time carries on where it left off
+	g = NewOccurrenceGate(MustParseRule("0 * * * *"), g.LastFired(), 0)
+	if g.ShouldFire(now.Add(time.Hour + time.Minute)) {
+		t.Error("6) should not fire after restart")
+	}
+}
+
+func TestOccurrenceGateMaxLateness(t *testing.T) {
+	lastFired := time.Date(2000, 4, 28, 12, 0, 0, 0, time.UTC)
+	g := NewOccurrenceGate(MustParseRule("0 * * * *"), lastFired, 5*time.Minute)
+
+	if g.ShouldFire(time.Date(2000, 4, 28, 14, 10, 0, 0, time.UTC)) {
+		t.Error("should skip a late occurrence")
+	}
+	e := time.Date(2000, 4, 28, 14, 0, 0, 0, time.UTC)
+	if g.LastFired() != e {
+		t.Errorf("%s != %s", g.LastFired(), e)
+	}
+	if !g.ShouldFire(time.Date(2000, 4, 28, 15, 1, 0, 0, time.UTC)) {
+		t.Error("should fire on time")
+	}
+}
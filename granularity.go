@@ -0,0 +1,46 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Granularity is the resolution of the occurrences of a rule.
+type Granularity int
+
+const (
+	// MinuteGranularity makes the start of each matching minute an occurrence. This is the default.
+	MinuteGranularity Granularity = iota
+	// SecondGranularity makes every whole second of each matching minute an occurrence, so that NextAfter agrees
+	// with Matches for callers that poll every second.
+	SecondGranularity
+)
+
+// WithGranularity returns a copy of the rule with occurrences at the given resolution. This affects NextAfter,
+// Ceil, and Floor, and so anything using the rule as a Schedule; counting and listing functions such as
+// CountBetween and MissedBetween always work in whole minutes.
+func (r *Rule) WithGranularity(g Granularity) *Rule {
+	out := *r
+	out.granularity = g
+	return &out
+}
+
+// Granularity returns the resolution of the occurrences of the rule.
+func (r *Rule) Granularity() Granularity {
+	return r.granularity
+}
+
+// truncate drops the parts of t finer than the granularity of the rule, along with any monotonic clock reading.
+// Truncation is done on the wall clock, unlike time.Truncate, so that it is correct in locations whose offset from
+// UTC is not a whole number of minutes.
+func (r *Rule) truncate(t time.Time) time.Time {
+	if r.granularity == SecondGranularity {
+		return t.Round(0).Add(-time.Duration(t.Nanosecond()))
+	}
+	return truncateMinute(t)
+}
+
+// truncateMinute drops the seconds and nanoseconds shown on the wall clock of t, along with any monotonic clock
+// reading.
+func truncateMinute(t time.Time) time.Time {
+	return t.Round(0).Add(-time.Duration(t.Second())*time.Second - time.Duration(t.Nanosecond()))
+}
@@ -0,0 +1,72 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSecondGranularity(t *testing.T) {
+	r := MustParseRule("0 9 * * *").WithGranularity(SecondGranularity)
+	if r.Granularity() != SecondGranularity || MustParseRule("0 9 * * *").Granularity() != MinuteGranularity {
+		t.Error("unexpected granularity")
+	}
+
+	at := func(h, m, s, ns int) time.Time {
+		return time.Date(2026, time.March, 4, h, m, s, ns, time.UTC)
+	}
+	cases := []struct {
+		name           string
+		fn             func(time.Time) time.Time
+		from, expected time.Time
+	}{
+		{"next within minute", r.NextAfter, at(9, 0, 30, 500), at(9, 0, 31, 0)},
+		{"next at end of minute", r.NextAfter, at(9, 0, 59, 0), at(9, 0, 0, 0).AddDate(0, 0, 1)},
+		{"next before minute", r.NextAfter, at(8, 59, 59, 0), at(9, 0, 0, 0)},
+		{"ceil exact", r.Ceil, at(9, 0, 10, 0), at(9, 0, 10, 0)},
+		{"ceil partial", r.Ceil, at(9, 0, 10, 1), at(9, 0, 11, 0)},
+		{"floor within minute", r.Floor, at(9, 0, 10, 1), at(9, 0, 10, 0)},
+		{"floor after minute", r.Floor, at(10, 0, 0, 0), at(9, 0, 59, 0)},
+	}
+	for _, c := range cases {
+		if n := c.fn(c.from); !n.Equal(c.expected) {
+			t.Errorf("%s: %s != %s", c.name, n, c.expected)
+		}
+	}
+	// counting and listing work in whole minutes
+	missed := r.MissedBetween(at(8, 0, 0, 0), at(9, 0, 0, 0).AddDate(0, 0, 1), 0)
+	if len(missed) != 2 || !missed[0].Equal(at(9, 0, 0, 0)) || !missed[1].Equal(at(9, 0, 0, 0).AddDate(0, 0, 1)) {
+		t.Errorf("%v Did not match!", missed)
+	}
+	if c := r.CountBetween(at(8, 0, 0, 0), at(10, 0, 0, 0)); c != 1 {
+		t.Errorf("%d != 1", c)
+	}
+}
+
+func TestTruncationConsistent(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	r := MustParseRule("0 9 * * *").In(loc)
+	from := time.Now()
+	for _, n := range []time.Time{r.NextAfter(from), r.Ceil(r.NextAfter(from)), r.Floor(from)} {
+		if n.Location() != loc {
+			t.Errorf("%s is not in %s", n, loc)
+		}
+		if n != n.Round(0) {
+			t.Errorf("%s has a monotonic clock reading", n)
+		}
+		if n.Second() != 0 || n.Nanosecond() != 0 {
+			t.Errorf("%s is not a whole minute", n)
+		}
+	}
+}
+
+func TestTruncateMinute(t *testing.T) {
+	// offsets that are not whole minutes were common before standard time zones
+	odd := time.FixedZone("LMT", 19*60+32)
+	n := truncateMinute(time.Date(1900, time.January, 1, 12, 30, 45, 10, odd))
+	if e := time.Date(1900, time.January, 1, 12, 30, 0, 0, odd); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+}
@@ -0,0 +1,66 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Heatmap counts the occurrences of a rule over a calendar year, for rendering when a rule fires without
+// enumerating every minute.
+type Heatmap struct {
+	Year int
+	// ByDay counts the occurrences on each date, indexed by month and day from 0. Dates that do not exist, such as
+	// the 30th of February, are always 0.
+	ByDay [12][31]int
+	// ByTime counts the days on which the rule fires at each wall clock time, indexed by hour and minute.
+	ByTime [24][60]int
+}
+
+// Total returns the number of occurrences in the year.
+func (h Heatmap) Total() int {
+	total := 0
+	for _, month := range h.ByDay {
+		for _, n := range month {
+			total += n
+		}
+	}
+	return total
+}
+
+// Heatmap counts the occurrences of the rule in the given year, in the location the rule is bound to or UTC.
+// Occurrences are counted per matching day as CountBetween does, so this is cheap even for rules firing every
+// minute.
+func (r *Rule) Heatmap(year int) Heatmap {
+	loc := r.location
+	if loc == nil {
+		loc = time.UTC
+	}
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+
+	out := Heatmap{Year: year}
+	for date := civilDate(year, time.January, 1); date.Year() == year; date = date.AddDate(0, 0, 1) {
+		if !r.matchesDay(date) {
+			continue
+		}
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
+		}
+		day := &out.ByDay[date.Month()-1][date.Day()-1]
+
+		if hasTransition(date, loc) {
+			for _, t := range r.dayInstants(date, loc, hours, minutes) {
+				*day++
+				out.ByTime[t.Hour()][t.Minute()]++
+			}
+			continue
+		}
+		*day += len(hours) * len(minutes)
+		for _, h := range hours {
+			for _, m := range minutes {
+				out.ByTime[h][m]++
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeatmap(t *testing.T) {
+	h := MustParseRule("0/30 9 * * 1/2/3/4/5").Heatmap(2026)
+	if h.Year != 2026 {
+		t.Errorf("%d != 2026", h.Year)
+	}
+	// 2026-01-01 is a Thursday, 2026-01-03 is a Saturday
+	if h.ByDay[0][0] != 2 || h.ByDay[0][2] != 0 {
+		t.Errorf("%d, %d Did not match! 2, 0", h.ByDay[0][0], h.ByDay[0][2])
+	}
+	if h.ByTime[9][0] != 261 || h.ByTime[9][30] != 261 || h.ByTime[9][15] != 0 {
+		t.Errorf("%d, %d, %d Did not match! 261, 261, 0", h.ByTime[9][0], h.ByTime[9][30], h.ByTime[9][15])
+	}
+	if h.Total() != 522 {
+		t.Errorf("%d != 522", h.Total())
+	}
+
+	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	r := MustParseRule("*/7 * 31 * *")
+	if n, e := r.Heatmap(2026).Total(), r.CountBetween(start, start.AddDate(1, 0, 0)); n != e {
+		t.Errorf("%d != %d", n, e)
+	}
+	if n := MustParseRule("0 0 30 2 *").Heatmap(2026).Total(); n != 0 {
+		t.Errorf("%d != 0", n)
+	}
+}
+
+func TestHeatmapDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	h := MustParseRule("30 1 * * *").In(loc).Heatmap(2026)
+	// 01:30 is skipped when the clocks go forward on 29 March
+	if h.ByDay[2][28] != 0 || h.ByDay[2][29] != 1 {
+		t.Errorf("%d, %d Did not match! 0, 1", h.ByDay[2][28], h.ByDay[2][29])
+	}
+	if h.ByTime[1][30] != 364 {
+		t.Errorf("%d != 364", h.ByTime[1][30])
+	}
+}
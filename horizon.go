@@ -0,0 +1,56 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// DefaultSearchHorizon is how far NextAfter, Floor, and the other searches look for an occurrence of a rule unless
+// changed with WithSearchHorizon. It is long enough for the rarest satisfiable rules, such as a leap day that is
+// also a Monday.
+const DefaultSearchHorizon = 50 * 366 * 24 * time.Hour
+
+// WithSearchHorizon returns a copy of the rule that looks at most d ahead of or behind the given time for an
+// occurrence. A shorter horizon bounds the work done for rules that rarely or never match, such as "0 0 30 2 *".
+// The horizon is rounded down to whole days, and 0 restores DefaultSearchHorizon. It is kept by MarshalBinary and
+// ToProto.
+func (r *Rule) WithSearchHorizon(d time.Duration) *Rule {
+	out := *r
+	out.searchHorizon = d
+	return &out
+}
+
+// SearchHorizon returns how far the rule looks for an occurrence.
+func (r *Rule) SearchHorizon() time.Duration {
+	if r.searchHorizon <= 0 {
+		return DefaultSearchHorizon
+	}
+	return r.searchHorizon.Truncate(24 * time.Hour)
+}
+
+// Next is like NextAfter but returns a *HorizonError if there is no occurrence within the search horizon, rather
+// than a time far in the future. The error reports whether the rule never matches at all.
+func (r *Rule) Next(from time.Time) (time.Time, error) {
+	next := r.NextAfter(from)
+	if next.Equal(farFuture) {
+		return time.Time{}, &HorizonError{Rule: r.String(), From: from, Horizon: r.SearchHorizon(), NeverMatches: r.neverMatches()}
+	}
+	return next, nil
+}
+
+// gregorianCycle is the number of years after which the Gregorian calendar repeats, including the days of the week.
+const gregorianCycle = 400
+
+// neverMatches returns whether no date matches the rule. Rules with a calendar or a TimeOfDay may match on dates
+// they no longer exclude, so they are never reported.
+func (r *Rule) neverMatches() bool {
+	if r.calendar != nil || r.timeOfDay != nil {
+		return false
+	}
+	date := civilDate(2000, time.January, 1)
+	for end := date.AddDate(gregorianCycle, 0, 0); date.Before(end); date = r.nextDay(date) {
+		if r.matchesDay(date) {
+			return false
+		}
+	}
+	return true
+}
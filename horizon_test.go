@@ -0,0 +1,89 @@
+package ticktickrules
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	r := MustParseRule("0 0 29 2 *")
+	if n, err := r.Next(from); err != nil || !n.Equal(time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s %v", n, err)
+	}
+
+	short := r.WithSearchHorizon(365 * 24 * time.Hour)
+	_, err := short.Next(from)
+	if he, ok := err.(*HorizonError); !ok || he.Horizon != 365*24*time.Hour || he.Rule != "0 0 29 2 *" {
+		t.Errorf("%v should be a HorizonError", err)
+	}
+	if n := short.NextAfter(from); !n.Equal(farFuture) {
+		t.Errorf("%s != %s", n, farFuture)
+	}
+	if p := short.Floor(from.AddDate(2, 0, 0)); !p.Equal(farPast) {
+		t.Errorf("%s != %s", p, farPast)
+	}
+	if p := short.Floor(from.AddDate(0, 11, 0)); !p.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2024-02-29", p)
+	}
+
+	if !errors.Is(err, ErrNoMatchInHorizon) || IsNeverMatches(err) {
+		t.Errorf("%v should be ErrNoMatchInHorizon but not never matching", err)
+	}
+
+	for _, expr := range []string{"0 0 30 2 *", "0 0 1 * 1#2", "0 0 L-30 2 *"} {
+		_, err := MustParseRule(expr).WithSearchHorizon(24 * time.Hour).Next(from)
+		if !errors.Is(err, ErrNoMatchInHorizon) || !IsNeverMatches(err) {
+			t.Errorf("%s: %v should never match", expr, err)
+		}
+	}
+	if IsNeverMatches(ErrNoMatchInHorizon) || IsNeverMatches(nil) {
+		t.Error("only a HorizonError can never match")
+	}
+}
+
+func TestSearchHorizon(t *testing.T) {
+	r := MustParseRule("* * * * *")
+	if h := r.SearchHorizon(); h != DefaultSearchHorizon {
+		t.Errorf("%s != %s", h, DefaultSearchHorizon)
+	}
+	if h := r.WithSearchHorizon(36 * time.Hour).SearchHorizon(); h != 24*time.Hour {
+		t.Errorf("%s != 24h", h)
+	}
+	if h := r.WithSearchHorizon(36 * time.Hour).WithSearchHorizon(0).SearchHorizon(); h != DefaultSearchHorizon {
+		t.Errorf("%s != %s", h, DefaultSearchHorizon)
+	}
+}
+
+func TestHorizonNthFrom(t *testing.T) {
+	// a leap day that is also a Monday is decades apart
+	r := MustParseRule("0 0 29 2 1")
+	from := time.Date(2000, 3, 1, 0, 0, 0, 0, time.UTC)
+	e := time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC)
+	if n := r.NextAfter(from); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if n := r.NthFrom(from, 1); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if n := r.NthFrom(from, 2); !n.Equal(time.Date(2044, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2044-02-29", n)
+	}
+	if n := r.WithSearchHorizon(10*366*24*time.Hour).NthFrom(from, 1); !n.Equal(farFuture) {
+		t.Errorf("%s != %s", n, farFuture)
+	}
+
+	// the window of a rule matching every minute only closes if it is within the horizon
+	if n := r.NextNonMatch(e); !n.Equal(e.Add(time.Minute)) {
+		t.Errorf("%s != %s", n, e.Add(time.Minute))
+	}
+	every := MustParseRule("* * * * *").WithSearchHorizon(30 * 24 * time.Hour)
+	if n := every.NextNonMatch(from); !n.Equal(farFuture) {
+		t.Errorf("%s != %s", n, farFuture)
+	}
+	weekdays := MustParseRule("* * * * MON-FRI")
+	if n := weekdays.NextNonMatch(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)); !n.Equal(time.Date(2026, 3, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2026-03-07", n)
+	}
+}
@@ -0,0 +1,180 @@
+// Package httpdebug provides an http.Handler that shows the rules and scheduler entries of a service along with
+// their upcoming occurrences, and evaluates expressions given in the query string. It is intended to be mounted on
+// an internal debug endpoint:
+//
+//	h := httpdebug.New(httpdebug.WithScheduler(scheduler))
+//	h.AddRule("cleanup", cleanupRule)
+//	http.Handle("/debug/schedules", h)
+//
+// A request with an "expr" query parameter evaluates that expression instead, for example
+// "/debug/schedules?expr=0+9+*+*+MON-FRI&n=3&tz=Europe/London&from=2026-03-02T00:00:00Z". The "n" parameter sets
+// the number of occurrences shown, which defaults to 5.
+package httpdebug
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AstromechZA/ticktickrules"
+)
+
+// DefaultOccurrences is the number of upcoming occurrences shown when the request does not give "n".
+const DefaultOccurrences = 5
+
+// maxOccurrences bounds "n" so that a request cannot ask for unbounded work.
+const maxOccurrences = 1000
+
+// Handler renders schedules as plain text. It is safe for concurrent use.
+type Handler struct {
+	scheduler *ticktickrules.Scheduler
+	clock     ticktickrules.Clock
+
+	mu    sync.Mutex
+	names []string
+	rules map[string]*ticktickrules.Rule
+}
+
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithScheduler shows the entries of the given scheduler.
+func WithScheduler(s *ticktickrules.Scheduler) Option {
+	return func(h *Handler) {
+		h.scheduler = s
+	}
+}
+
+// WithClock reads the current time from the given clock rather than the system clock.
+func WithClock(c ticktickrules.Clock) Option {
+	return func(h *Handler) {
+		h.clock = c
+	}
+}
+
+// New returns a Handler with no rules.
+func New(opts ...Option) *Handler {
+	h := &Handler{clock: ticktickrules.SystemClock, rules: map[string]*ticktickrules.Rule{}}
+	for _, o := range opts {
+		o(h)
+	}
+	return h
+}
+
+// AddRule registers a rule to be shown under the given name, replacing any rule already registered with it.
+func (h *Handler) AddRule(name string, r *ticktickrules.Rule) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.rules[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.rules[name] = r
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	n := DefaultOccurrences
+	if s := q.Get("n"); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 1 || v > maxOccurrences {
+			http.Error(w, fmt.Sprintf("n must be a number between 1 and %d", maxOccurrences), http.StatusBadRequest)
+			return
+		}
+		n = v
+	}
+	from := h.clock.Now()
+	if s := q.Get("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+	if s := q.Get("tz"); s != "" {
+		loc, err := time.LoadLocation(s)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		from = from.In(loc)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if expr := q.Get("expr"); expr != "" {
+		h.serveExpression(w, expr, from, n)
+		return
+	}
+
+	h.mu.Lock()
+	names := append([]string(nil), h.names...)
+	rules := make([]*ticktickrules.Rule, len(names))
+	for i, name := range names {
+		rules[i] = h.rules[name]
+	}
+	h.mu.Unlock()
+
+	fmt.Fprintf(w, "now: %s\n", from.Format(time.RFC3339))
+	if len(rules) > 0 {
+		fmt.Fprintln(w, "\nrules:")
+		for i, r := range rules {
+			fmt.Fprintf(w, "  %s: %s\n", names[i], r)
+			writeOccurrences(w, r, from, n)
+		}
+	}
+	if h.scheduler != nil {
+		fmt.Fprintln(w, "\nscheduler:")
+		for _, e := range h.scheduler.Entries() {
+			fmt.Fprintf(w, "  [%d] %s: %v\n", e.ID, e.Name, e.Schedule)
+			fmt.Fprintf(w, "    paused=%t running=%d started=%d finished=%d failed=%d\n",
+				e.Paused, e.Running, e.Stats.Started, e.Stats.Finished, e.Stats.Failed)
+			if !e.Prev.IsZero() {
+				fmt.Fprintf(w, "    previous: %s\n", e.Prev.Format(time.RFC3339))
+			}
+			if e.Stats.LastError != nil {
+				fmt.Fprintf(w, "    last error: %s\n", e.Stats.LastError)
+			}
+			if !e.Paused {
+				writeOccurrences(w, e.Schedule, from, n)
+			}
+		}
+	}
+}
+
+// serveExpression writes the description and upcoming occurrences of a single expression, or the reason it is
+// invalid along with any suggested corrections.
+func (h *Handler) serveExpression(w http.ResponseWriter, expr string, from time.Time, n int) {
+	r, err := ticktickrules.ParseRule(expr, ticktickrules.Lenient())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintf(w, "invalid: %s\n", err)
+		for _, s := range ticktickrules.SuggestCorrections(expr) {
+			fmt.Fprintf(w, "hint: %s\n", s)
+		}
+		return
+	}
+	fmt.Fprintf(w, "expression: %s\n", r)
+	fmt.Fprintf(w, "normalized: %s\n", r.StringNormalized())
+	fmt.Fprintf(w, "description: %s\n", r.Describe())
+	fmt.Fprintf(w, "matches %s: %t\n", from.Format(time.RFC3339), r.Matches(from))
+	writeOccurrences(w, r, from, n)
+}
+
+// writeOccurrences writes the next n occurrences of s after from, stopping early if there are no more.
+func writeOccurrences(w io.Writer, s ticktickrules.Schedule, from time.Time, n int) {
+	t := from
+	for i := 0; i < n; i++ {
+		// schedules return a time far in the future when they have no next occurrence
+		next := s.NextAfter(t)
+		if !next.After(t) || next.Year() > 9999 {
+			fmt.Fprintln(w, "    no further occurrences")
+			return
+		}
+		fmt.Fprintf(w, "    %s\n", next.Format(time.RFC3339))
+		t = next
+	}
+}
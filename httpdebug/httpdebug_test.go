@@ -0,0 +1,71 @@
+package httpdebug
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AstromechZA/ticktickrules"
+)
+
+func get(t *testing.T, h http.Handler, url string) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+	return rec.Code, rec.Body.String()
+}
+
+func TestHandler(t *testing.T) {
+	clock := ticktickrules.NewFakeClock(time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC))
+	s := ticktickrules.NewScheduler(ticktickrules.WithClock(clock))
+	job := func(ctx context.Context, occurrence time.Time) error { return nil }
+	if _, err := s.Add("report", ticktickrules.MustParseRule("0 9 * * *"), job); err != nil {
+		t.Fatal(err)
+	}
+
+	h := New(WithScheduler(s), WithClock(clock))
+	h.AddRule("cleanup", ticktickrules.MustParseRule("30 2 * * *"))
+	code, body := get(t, h, "/?n=2")
+	if code != http.StatusOK {
+		t.Fatalf("%d != %d", code, http.StatusOK)
+	}
+	for _, e := range []string{
+		"now: 2026-03-02T08:30:00Z",
+		"  cleanup: 30 2 * * *\n    2026-03-03T02:30:00Z\n    2026-03-04T02:30:00Z\n",
+		"  [1] report: 0 9 * * *\n",
+		"    2026-03-02T09:00:00Z\n    2026-03-03T09:00:00Z\n",
+	} {
+		if !strings.Contains(body, e) {
+			t.Errorf("'%s' Did not contain '%s'", body, e)
+		}
+	}
+}
+
+func TestHandlerExpression(t *testing.T) {
+	h := New()
+	code, body := get(t, h, "/?expr=0+9+*+*+MON-FRI&n=2&from=2026-03-06T10:00:00Z&tz=Europe/London")
+	if code != http.StatusOK {
+		t.Fatalf("%d != %d: %s", code, http.StatusOK, body)
+	}
+	for _, e := range []string{"expression: 0 9 * * MON-FRI\n", "2026-03-09T09:00:00Z\n    2026-03-10T09:00:00Z\n"} {
+		if !strings.Contains(body, e) {
+			t.Errorf("'%s' Did not contain '%s'", body, e)
+		}
+	}
+
+	code, body = get(t, h, "/?expr=0+0+9+*+*+MON")
+	if code != http.StatusBadRequest || !strings.Contains(body, "hint: 6 fields given") {
+		t.Errorf("%d: '%s' Did not match!", code, body)
+	}
+	code, body = get(t, h, "/?expr=0+0+30+2+*")
+	if code != http.StatusOK || !strings.Contains(body, "no further occurrences") {
+		t.Errorf("%d: '%s' Did not match!", code, body)
+	}
+	for _, url := range []string{"/?n=0", "/?n=x", "/?from=yesterday", "/?tz=Nowhere/Special"} {
+		if code, _ := get(t, h, url); code != http.StatusBadRequest {
+			t.Errorf("%s: %d != %d", url, code, http.StatusBadRequest)
+		}
+	}
+}
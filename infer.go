@@ -0,0 +1,134 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// inferSlack is how much less precise than the best candidate a simpler candidate may be and still be preferred
+// by InferRule, so that a few missing samples do not lead to an overfitted rule.
+const inferSlack = 0.1
+
+// InferRule proposes the simplest rule consistent with a set of observed event times, along with a confidence
+// between 0 and 1, for recovering the schedules of legacy jobs that were never written down. Times are truncated to
+// the minute and evaluated in the location of the first sample, and the rule returned matches every sample.
+//
+// Each field is either left unrestricted, restricted to the observed values, or for minutes and hours restricted
+// to the evenly spaced values covering the observed ones. Candidates are scored by the fraction of their
+// occurrences between the first and last sample that were observed, and the confidence is this fraction scaled
+// down for small numbers of samples. At least 2 distinct samples are required.
+func InferRule(samples []time.Time) (*Rule, float64, error) {
+	if len(samples) == 0 {
+		return nil, 0, fmt.Errorf("At least 2 distinct samples are required")
+	}
+	loc := samples[0].Location()
+	seen := map[time.Time]bool{}
+	var times []time.Time
+	for _, s := range samples {
+		t := truncateMinute(s.In(loc))
+		if !seen[t] {
+			seen[t] = true
+			times = append(times, t)
+		}
+	}
+	if len(times) < 2 {
+		return nil, 0, fmt.Errorf("At least 2 distinct samples are required")
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+
+	observed := make([][]int, len(ruleFields))
+	for _, t := range times {
+		for i, v := range []int{t.Minute(), t.Hour(), t.Day(), int(t.Month()), int(t.Weekday())} {
+			observed[i] = append(observed[i], v)
+		}
+	}
+	options := make([][]FieldValues, len(ruleFields))
+	for i, f := range ruleFields {
+		values := normalizeField(observed[i], f.min, f.max)
+		options[i] = []FieldValues{nil}
+		if values != nil {
+			options[i] = append(options[i], values)
+		}
+		if i <= 1 {
+			if spaced := evenlySpaced(values, f); len(spaced) != len(values) {
+				options[i] = append(options[i], spaced)
+			}
+		}
+	}
+
+	type candidate struct {
+		rule      *Rule
+		precision float64
+		size      int
+	}
+	var candidates []candidate
+	maxPrecision := 0.0
+	end := times[len(times)-1].Add(time.Minute)
+	var try func(i int, items []string, size int)
+	try = func(i int, items []string, size int) {
+		if i < len(options) {
+			for _, o := range options[i] {
+				// simplicity is measured by how many parts the field needs when written out in standard syntax
+				parts := 0
+				if len(o) > 0 {
+					parts = strings.Count(compactField(o, ruleFields[i].min, ruleFields[i].max), ",") + 1
+				}
+				try(i+1, append(items, o.ruleItem()), size+parts)
+			}
+			return
+		}
+		r, err := NewRule(items[0], items[1], items[2], items[3], items[4])
+		if err != nil {
+			return
+		}
+		c := candidate{r, float64(len(times)) / float64(r.CountBetween(times[0], end)), size}
+		candidates = append(candidates, c)
+		if c.precision > maxPrecision {
+			maxPrecision = c.precision
+		}
+	}
+	try(0, nil, 0)
+
+	// the simplest candidate that is nearly as precise as the best wins
+	var best candidate
+	for _, c := range candidates {
+		if c.precision < maxPrecision-inferSlack {
+			continue
+		}
+		if best.rule == nil || c.size < best.size || (c.size == best.size && c.precision > best.precision) {
+			best = c
+		}
+	}
+	return best.rule, best.precision * (1 - 1/float64(len(times))), nil
+}
+
+// evenlySpaced returns the evenly spaced values of the field covering all of the given values, with the widest
+// spacing possible. Nil is returned if the values are unrestricted.
+func evenlySpaced(values FieldValues, f field) FieldValues {
+	if len(values) == 0 {
+		return nil
+	}
+	step := 0
+	for _, v := range values[1:] {
+		step = gcd(step, v-values[0])
+	}
+	if step == 0 {
+		return values
+	}
+	var out []int
+	for v := f.min + (values[0]-f.min)%step; v <= f.max; v += step {
+		out = append(out, v)
+	}
+	return normalizeField(out, f.min, f.max)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,71 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInferRule(t *testing.T) {
+	start := time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		rule     string
+		span     time.Duration
+		skip     int
+		expected string
+	}{
+		{"daily", "30 9 * * *", 30 * 24 * time.Hour, -1, "30 9 * * *"},
+		{"weekdays", "0 9 * * 1/2/3/4/5", 21 * 24 * time.Hour, -1, "0 9 * * 1,2,3,4,5"},
+		{"quarter hourly", "*/15 * * * *", 24 * time.Hour, -1, "0,15,30,45 * * * *"},
+		{"monthly", "0 0 1 * *", 365 * 24 * time.Hour, -1, "0 0 1 * *"},
+		{"missing sample", "10 */6 * * *", 10 * 24 * time.Hour, 7, "10 0,6,12,18 * * *"},
+		{"every other hour", "5 */2 * * *", 24 * time.Hour, 3, "5 0,2,4,6,8,10,12,14,16,18,20,22 * * *"},
+	}
+	for _, c := range cases {
+		r := MustParseRule(c.rule)
+		var samples []time.Time
+		for i, n := 0, r.NextAfter(start.Add(-time.Minute)); n.Before(start.Add(c.span)); i, n = i+1, r.NextAfter(n) {
+			if i != c.skip {
+				samples = append(samples, n.Add(17*time.Second))
+			}
+		}
+		inferred, confidence, err := InferRule(samples)
+		if err != nil {
+			t.Errorf("%s: %s", c.name, err)
+			continue
+		}
+		if s := inferred.StringNormalized(); s != c.expected {
+			t.Errorf("%s: '%s' Did not match! '%s'", c.name, s, c.expected)
+		}
+		if confidence <= 0.5 || confidence > 1 {
+			t.Errorf("%s: unexpected confidence %f", c.name, confidence)
+		}
+		for _, s := range samples {
+			if !inferred.Matches(s.Truncate(time.Minute)) {
+				t.Errorf("%s: %s does not match %s", c.name, inferred, s)
+			}
+		}
+	}
+}
+
+func TestInferRuleLowConfidence(t *testing.T) {
+	samples := []time.Time{
+		time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2026, time.March, 2, 9, 0, 30, 0, time.UTC),
+		time.Date(2026, time.March, 5, 14, 37, 0, 0, time.UTC),
+	}
+	_, confidence, err := InferRule(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if confidence > 0.5 {
+		t.Errorf("unexpected confidence %f", confidence)
+	}
+
+	if _, _, err := InferRule(samples[:2]); err == nil {
+		t.Error("should have failed")
+	}
+	if _, _, err := InferRule(nil); err == nil {
+		t.Error("should have failed")
+	}
+}
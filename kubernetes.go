@@ -0,0 +1,95 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseKubernetes parses a CronJob schedule exactly as Kubernetes does, so that manifests can be validated before
+// they are applied. This follows the vixie cron semantics of the parser Kubernetes uses:
+//
+//   - there are 5 fields, which may use comma separated lists, ranges, steps such as "5/15", and "?" for "*"
+//   - month and day of week names are accepted in any case, and Sunday may be 0 or 7
+//   - the shorthands "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", and "@hourly" are
+//     accepted
+//   - if both the day of month and day of week are restricted, the rule fires on days matching either of them, so
+//     "0 0 13 * 5" fires on every Friday and every 13th. A field counts as unrestricted only if it contains "*" or
+//     "?" without a step.
+//
+// Kubernetes rejects time zone prefixes in the schedule in favour of the timeZone field of the CronJob, and so does
+// this. The "N#K" day of week and "@every" are not supported by Kubernetes either.
+func ParseKubernetes(expr string) (*Rule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) == 1 && strings.HasPrefix(parts[0], "@") {
+		m, ok := macros[strings.ToLower(parts[0])]
+		if !ok {
+			return nil, fmt.Errorf("Expression '%s' uses an unsupported shorthand", expr)
+		}
+		parts = strings.Fields(m)
+	}
+	if len(parts) > 0 {
+		if _, ok := cutZonePrefix(parts[0]); ok {
+			return nil, fmt.Errorf("Expression '%s' must not contain a time zone, use the CronJob timeZone field instead", expr)
+		}
+	}
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("Expression '%s' must have 5 fields but has %d", expr, len(parts))
+	}
+
+	items := make([]string, 5)
+	for i, f := range ruleFields {
+		// "L" is never part of a name outside of the month field
+		if strings.Contains(parts[i], "#") || (f.name != monthField.name && strings.Contains(strings.ToUpper(parts[i]), "L")) {
+			return nil, &SyntaxError{Item: parts[i], Reason: "is not supported by Kubernetes"}
+		}
+		item, err := translateItem(parts[i], f, false)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+
+	either := vixieDays(parts, items)
+	r, err := NewRule(items[0], items[1], items[2], items[3], items[4])
+	if err != nil {
+		return nil, err
+	}
+	r.eitherDay = either
+	return r, nil
+}
+
+// vixieStar returns whether vixie cron treats the item as unrestricted, which is when any part of it is "*" or "?"
+// without a step greater than 1.
+func vixieStar(item string) bool {
+	for _, part := range strings.Split(item, ",") {
+		switch part {
+		case "*", "?", "*/1", "?/1":
+			return true
+		}
+	}
+	return false
+}
+
+// vixieDays returns whether a rule with the given fields, as written and as translated for NewRule, fires when either
+// the day of month or day of week matches. If one of the translated items covers every day without counting as
+// unrestricted, such as "1-31", every day matches, so both items are replaced with "*".
+func vixieDays(parts, items []string) bool {
+	either := !vixieStar(parts[2]) && !vixieStar(parts[4])
+	if either && (items[2] == "*" || items[4] == "*") {
+		items[2], items[4] = "*", "*"
+		return false
+	}
+	return either
+}
+
+// kubernetesSchedule renders the rule as a CronJob schedule. Rules restricting both the day of month and day of
+// week can only be rendered if they were parsed with ParseKubernetes, since Kubernetes fires when either matches.
+func (r *Rule) kubernetesSchedule() (string, error) {
+	if r.hasOccurrence() || r.dayOfMonthLast {
+		return "", fmt.Errorf("Rule '%s' cannot be expressed as a Kubernetes schedule", r)
+	}
+	if len(r.DaysOfMonth()) > 0 && len(r.DaysOfWeek()) > 0 && !r.eitherDay {
+		return "", fmt.Errorf("Rule '%s' restricts both the day of month and day of week", r)
+	}
+	return r.ToStandardCron(), nil
+}
@@ -0,0 +1,81 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseKubernetes checks schedules against the times Kubernetes would run them at.
+func TestParseKubernetes(t *testing.T) {
+	from := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{"*/15 * * * *", []string{"2026-03-01T00:15:00Z", "2026-03-01T00:30:00Z"}},
+		{"5/20 * * * *", []string{"2026-03-01T00:05:00Z", "2026-03-01T00:25:00Z"}},
+		{"0 9-17/4 * * *", []string{"2026-03-01T09:00:00Z", "2026-03-01T13:00:00Z", "2026-03-01T17:00:00Z"}},
+		{"0 0 * * 7", []string{"2026-03-08T00:00:00Z", "2026-03-15T00:00:00Z"}},
+		{"0 0 ? * mon-wed", []string{"2026-03-02T00:00:00Z", "2026-03-03T00:00:00Z", "2026-03-04T00:00:00Z"}},
+		{"0 0 13 * 5", []string{"2026-03-06T00:00:00Z", "2026-03-13T00:00:00Z", "2026-03-20T00:00:00Z"}},
+		{"0 0 */10 * 1", []string{"2026-03-02T00:00:00Z", "2026-03-09T00:00:00Z", "2026-03-11T00:00:00Z"}},
+		{"0 0 * * 1", []string{"2026-03-02T00:00:00Z", "2026-03-09T00:00:00Z"}},
+		{"0 0 1 * *", []string{"2026-04-01T00:00:00Z", "2026-05-01T00:00:00Z"}},
+		{"0 0 1-31 * 1", []string{"2026-03-02T00:00:00Z", "2026-03-03T00:00:00Z"}},
+		{"0 0 1 JAN *", []string{"2027-01-01T00:00:00Z"}},
+		{"0 0 1 JUL *", []string{"2026-07-01T00:00:00Z"}},
+		{"@weekly", []string{"2026-03-08T00:00:00Z", "2026-03-15T00:00:00Z"}},
+		{"@hourly", []string{"2026-03-01T01:00:00Z", "2026-03-01T02:00:00Z"}},
+	}
+	for _, c := range cases {
+		r, err := ParseKubernetes(c.expr)
+		if err != nil {
+			t.Errorf("%s: %s", c.expr, err)
+			continue
+		}
+		next := from
+		for _, e := range c.expected {
+			next = r.NextAfter(next)
+			if s := next.Format(time.RFC3339); s != e {
+				t.Errorf("%s: '%s' Did not match! '%s'", c.expr, s, e)
+				break
+			}
+		}
+	}
+}
+
+func TestParseKubernetesInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"CRON_TZ=UTC 0 0 * * *",
+		"TZ=UTC 0 0 * * *",
+		"0 0 * * 1#2",
+		"0 0 L * *",
+		"0 0 L-3 * *",
+		"0 0 * * 5L",
+		"@every 5m",
+		"0 0 * * 8",
+		"0 0 * *",
+		"0 0 0 * * *",
+		"60 * * * *",
+	} {
+		if _, err := ParseKubernetes(expr); err == nil {
+			t.Errorf("%s: expected an error", expr)
+		}
+	}
+}
+
+func TestFormatKubernetes(t *testing.T) {
+	r, _ := ParseKubernetes("0 0 13 * 5")
+	if s, err := r.Format(Kubernetes); err != nil || s != "0 0 13 * 5" {
+		t.Errorf("'%s' Did not match! '0 0 13 * 5' (%v)", s, err)
+	}
+	if s, err := MustParseRule("*/30 9 * * 1/2/3").Format(Kubernetes); err != nil || s != "0,30 9 * * 1-3" {
+		t.Errorf("'%s' Did not match! '0,30 9 * * 1-3' (%v)", s, err)
+	}
+	if _, err := MustParseRule("0 0 13 * 5").Format(Kubernetes); err == nil {
+		t.Error("expected an error when both days must match")
+	}
+	if r.Fingerprint() == MustParseRule("0 0 13 * 5").Fingerprint() {
+		t.Error("expected the fingerprints to differ")
+	}
+}
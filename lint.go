@@ -0,0 +1,151 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Severity is how serious a lint finding is.
+type Severity int
+
+const (
+	// Info findings are worth knowing about but often intended.
+	Info Severity = iota
+	// Warning findings are probably mistakes.
+	Warning
+	// Error findings are expressions that are invalid or can never fire.
+	Error
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Info:
+		return "info"
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	}
+	return "Severity(" + strconv.Itoa(int(s)) + ")"
+}
+
+// Finding is a problem reported by Lint.
+type Finding struct {
+	Severity Severity
+	// Code is a short stable identifier for the kind of problem, such as "every-minute", for filtering findings.
+	Code    string
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s (%s)", f.Severity, f.Message, f.Code)
+}
+
+// Lint checks an expression for common anti-patterns, such as schedules that fire every minute or days of the
+// month that some months do not have. It is intended as a pre-merge check for schedule configuration. Invalid
+// expressions are reported as an Error finding rather than an error. No findings are returned for an expression
+// with no problems.
+func Lint(expr string) []Finding {
+	var out []Finding
+	add := func(s Severity, code, format string, args ...interface{}) {
+		out = append(out, Finding{Severity: s, Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) > 0 {
+		if _, ok := cutZonePrefix(parts[0]); ok {
+			parts = parts[1:]
+		}
+	}
+	for i, p := range parts {
+		if i >= len(ruleFields) {
+			break
+		}
+		if hasRepeatedValues(p, ruleFields[i]) {
+			add(Warning, "redundant", "%s '%s' lists the same value more than once", ruleFields[i].name, p)
+		}
+		if isStartStep(p, ruleFields[i]) {
+			add(Warning, "ambiguous-step", "%s '%s' matches only the two values listed, whereas in Quartz and "+
+				"standard cron it is a step starting from the first; use the QuartzSteps option for that",
+				ruleFields[i].name, p)
+		}
+	}
+
+	r, err := ParseRule(expr)
+	if err != nil {
+		add(Error, "invalid", "%s", err.Error())
+		return out
+	}
+
+	if len(r.Minutes()) == 0 {
+		add(Warning, "every-minute", "runs every minute, which is rarely intended for anything but the lightest jobs")
+	}
+	if m, h := r.Minutes(), r.Hours(); len(m) == 1 && m[0] == 0 && len(h) == 1 && h[0] == 0 {
+		add(Info, "midnight", "runs at midnight along with many other jobs, consider a less busy time")
+	}
+	if (len(r.DaysOfMonth()) > 0 || r.dayOfMonthLast) && (len(r.DaysOfWeek()) > 0 || r.hasOccurrence()) {
+		add(Warning, "dom-and-dow", "restricts both the day of month and day of week, so both must match, whereas "+
+			"standard cron fires when either matches")
+	}
+	lintDaysOfMonth(r, add)
+	return out
+}
+
+// lintDaysOfMonth reports days of the month that are missing from some or all of the months the rule matches.
+func lintDaysOfMonth(r *Rule, add func(Severity, string, string, ...interface{})) {
+	days := r.DaysOfMonth()
+	if len(days) == 0 {
+		return
+	}
+	months := expandField(r.Months(), monthField.min, monthField.max)
+
+	var possible, short []int
+	for _, d := range days {
+		fits := false
+		for _, m := range months {
+			// the 29th of February only occurs in leap years
+			if d <= time.Date(2024, time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+				fits = true
+			}
+			if d > time.Date(2023, time.Month(m)+1, 0, 0, 0, 0, 0, time.UTC).Day() {
+				short = append(short, m)
+			}
+		}
+		if fits {
+			possible = append(possible, d)
+		}
+	}
+
+	if len(possible) == 0 {
+		add(Error, "never-matches", "day of month %s never occurs in month %s", days, FieldValues(months))
+	} else if len(short) > 0 && possible[0] >= 29 {
+		add(Warning, "skips-months", "day of month %s is skipped in months with fewer days", days)
+	} else if len(short) > 0 {
+		add(Info, "skips-months", "day of month %s is skipped in months with fewer days", days)
+	}
+}
+
+// hasRepeatedValues returns whether a list item such as "0/0/0" or "MON,1" names any value more than once.
+func hasRepeatedValues(item string, f field) bool {
+	if strings.HasPrefix(item, "*") {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, p := range strings.FieldsFunc(item, func(c rune) bool { return c == '/' || c == ',' }) {
+		if strings.ContainsAny(p, "-*") {
+			// ranges and steps are not single values
+			return false
+		}
+		p = replaceNames(strings.ToUpper(p), f.names, f.offset)
+		if f.alias > 0 && p == strconv.Itoa(f.alias) {
+			p = strconv.Itoa(f.min)
+		}
+		if seen[p] {
+			return true
+		}
+		seen[p] = true
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package ticktickrules
+
+import (
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	cases := map[string][]string{
+		"30 9 * * 1/2/3/4/5":  nil,
+		"* * * * *":           {"every-minute"},
+		"0 0 * * *":           {"midnight"},
+		"0/0/0 12 * * *":      {"redundant"},
+		"0 12 * * MON/1":      {"redundant", "ambiguous-step"},
+		"15 12 1 * 1":         {"dom-and-dow"},
+		"15 12 31 * *":        {"skips-months"},
+		"15 12 1/31 * *":      {"ambiguous-step", "skips-months"},
+		"15 12 31 JAN/MAR *":  nil,
+		"15 12 31 1/3/5 *":    nil,
+		"15 12 30/31 2 *":     {"ambiguous-step", "never-matches"},
+		"15 12 29 2 *":        {"skips-months"},
+		"0 0 * * * *":         {"invalid"},
+		"CRON_TZ=UTC 5 3 * *": {"invalid"},
+	}
+	for expr, e := range cases {
+		findings := Lint(expr)
+		var codes []string
+		for _, f := range findings {
+			codes = append(codes, f.Code)
+		}
+		if len(codes) != len(e) {
+			t.Errorf("%s: %v Did not match! %v", expr, codes, e)
+			continue
+		}
+		for i := range codes {
+			if codes[i] != e[i] {
+				t.Errorf("%s: %v Did not match! %v", expr, codes, e)
+				break
+			}
+		}
+	}
+}
+
+func TestLintSeverity(t *testing.T) {
+	for expr, e := range map[string]Severity{
+		"0 0 * * *":       Info,
+		"15 12 31 * *":    Warning,
+		"15 12 1/31 * *":  Info,
+		"15 12 30 2 *":    Error,
+		"not a cron rule": Error,
+	} {
+		f := Lint(expr)
+		if len(f) == 0 {
+			t.Errorf("%s: expected a finding", expr)
+		} else if f[len(f)-1].Severity != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, f[len(f)-1].Severity, e)
+		}
+	}
+
+	f := Finding{Severity: Warning, Code: "every-minute", Message: "runs every minute"}
+	if s := f.String(); s != "warning: runs every minute (every-minute)" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
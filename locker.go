@@ -0,0 +1,44 @@
+package ticktickrules
+
+import (
+	"sync"
+	"time"
+)
+
+// Locker coordinates schedulers running the same jobs on several instances, so that only one of them runs each
+// occurrence. Implementations are typically backed by a shared database such as Redis or Postgres.
+type Locker interface {
+	// TryLock attempts to take the lock for key until the given time, and returns whether it was taken. It must not
+	// block waiting for the lock.
+	TryLock(key string, until time.Time) bool
+}
+
+// MemoryLocker is a Locker for schedulers within a single process.
+type MemoryLocker struct {
+	clock Clock
+
+	mu    sync.Mutex
+	locks map[string]time.Time
+}
+
+// NewMemoryLocker returns a MemoryLocker that expires locks according to c.
+func NewMemoryLocker(c Clock) *MemoryLocker {
+	return &MemoryLocker{clock: c, locks: make(map[string]time.Time)}
+}
+
+// TryLock takes the lock for key unless it is already held and has not expired.
+func (m *MemoryLocker) TryLock(key string, until time.Time) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.clock.Now()
+	for k, expiry := range m.locks {
+		if !expiry.After(now) {
+			delete(m.locks, k)
+		}
+	}
+	if _, ok := m.locks[key]; ok {
+		return false
+	}
+	m.locks[key] = until
+	return true
+}
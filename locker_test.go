@@ -0,0 +1,66 @@
+package ticktickrules
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+var _ Locker = NewMemoryLocker(SystemClock)
+
+func TestMemoryLocker(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 0, 0, 0, time.UTC))
+	l := NewMemoryLocker(c)
+	until := c.Now().Add(time.Hour)
+
+	if !l.TryLock("job", until) {
+		t.Error("1) should have locked")
+	}
+	if l.TryLock("job", until) {
+		t.Error("2) should not have locked")
+	}
+	if !l.TryLock("other", until) {
+		t.Error("3) should have locked")
+	}
+	c.Advance(time.Hour)
+	if !l.TryLock("job", until.Add(time.Hour)) {
+		t.Error("4) should have locked after expiry")
+	}
+}
+
+func TestSchedulerLocker(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	l := NewMemoryLocker(c)
+	ran := make(chan string, 10)
+
+	// two instances of the same scheduler share a lock
+	for _, instance := range []string{"a", "b"} {
+		instance := instance
+		m := new(recordingMetrics)
+		s := NewScheduler(WithClock(c), WithLocker(l), WithMetrics(m))
+		if _, err := s.Add("job", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+			ran <- instance
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go s.Run(ctx)
+		m.waitFor(t, 1)
+	}
+
+	c.BlockUntil(2)
+	c.Advance(time.Minute)
+	c.BlockUntil(2)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Error("job did not run")
+	}
+	select {
+	case instance := <-ran:
+		t.Errorf("job also ran on %s", instance)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
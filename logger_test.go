@@ -0,0 +1,73 @@
+package ticktickrules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	_ Logger = slog.Default()
+	_ Logger = nopLogger{}
+)
+
+// recordingLogger records each message with its level.
+type recordingLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *recordingLogger) log(level, msg string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, fmt.Sprintf("%s %s %v", level, msg, args))
+}
+
+func (l *recordingLogger) Debug(msg string, args ...interface{}) { l.log("DEBUG", msg, args...) }
+
+func (l *recordingLogger) Info(msg string, args ...interface{}) { l.log("INFO", msg, args...) }
+
+func (l *recordingLogger) Warn(msg string, args ...interface{}) { l.log("WARN", msg, args...) }
+
+func (l *recordingLogger) Error(msg string, args ...interface{}) { l.log("ERROR", msg, args...) }
+
+func TestSchedulerLogger(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	l := new(recordingLogger)
+	s := NewScheduler(WithClock(c), WithLogger(l))
+	if _, err := s.Add("job", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Run(ctx)
+		close(done)
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	cancel()
+	<-done
+
+	expected := []string{
+		"DEBUG next occurrence computed [job job next 2000-04-28 15:00:00 +0000 UTC]",
+		"DEBUG next occurrence computed [job job next 2000-04-28 16:00:00 +0000 UTC]",
+		"ERROR job failed [job job occurrence 2000-04-28 15:00:00 +0000 UTC error boom]",
+	}
+	if len(l.messages) != len(expected) {
+		t.Errorf("%d != %d: %v", len(l.messages), len(expected), l.messages)
+		return
+	}
+	for i, e := range expected {
+		if l.messages[i] != e {
+			t.Errorf("%d) '%s' Did not match!", i, l.messages[i])
+		}
+	}
+}
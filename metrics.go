@@ -0,0 +1,36 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Metrics receives instrumentation events from a Scheduler so that they can be exported to a monitoring system.
+// Methods are called synchronously from the scheduler and must not call back into it.
+type Metrics interface {
+	// JobScheduled is called whenever the next occurrence of a job is computed.
+	JobScheduled(name string, next time.Time)
+	// JobStarted is called when a job starts running for an occurrence.
+	JobStarted(name string, occurrence time.Time)
+	// JobFinished is called when a job returns, with how long it ran for and the error it returned.
+	JobFinished(name string, occurrence time.Time, duration time.Duration, err error)
+	// JobOverrun is called when an occurrence of a job is due while the previous run is still going.
+	JobOverrun(name string, occurrence time.Time)
+	// JobMissed is called for each occurrence skipped because the scheduler was not running or the clock jumped.
+	JobMissed(name string, occurrence time.Time)
+}
+
+// NopMetrics is a Metrics that ignores every event. It can be embedded to implement only some of the methods.
+type NopMetrics struct{}
+
+func (NopMetrics) JobScheduled(name string, next time.Time) {}
+
+func (NopMetrics) JobStarted(name string, occurrence time.Time) {}
+
+func (NopMetrics) JobFinished(name string, occurrence time.Time, duration time.Duration, err error) {}
+
+func (NopMetrics) JobOverrun(name string, occurrence time.Time) {}
+
+func (NopMetrics) JobMissed(name string, occurrence time.Time) {}
+
+// maxMissedReported bounds how many missed occurrences are reported for one job after a long clock jump.
+const maxMissedReported = 1000
@@ -0,0 +1,120 @@
+package ticktickrules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+var _ Metrics = NopMetrics{}
+
+// recordingMetrics records every event as a string.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (m *recordingMetrics) record(format string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, fmt.Sprintf(format, args...))
+}
+
+func (m *recordingMetrics) JobScheduled(name string, next time.Time) {
+	m.record("scheduled %s %s", name, next.Format("15:04"))
+}
+
+func (m *recordingMetrics) JobStarted(name string, occurrence time.Time) {
+	m.record("started %s %s", name, occurrence.Format("15:04"))
+}
+
+func (m *recordingMetrics) JobFinished(name string, occurrence time.Time, duration time.Duration, err error) {
+	m.record("finished %s %s %v", name, occurrence.Format("15:04"), err)
+}
+
+func (m *recordingMetrics) JobOverrun(name string, occurrence time.Time) {
+	m.record("overrun %s %s", name, occurrence.Format("15:04"))
+}
+
+func (m *recordingMetrics) JobMissed(name string, occurrence time.Time) {
+	m.record("missed %s %s", name, occurrence.Format("15:04"))
+}
+
+func (m *recordingMetrics) waitFor(t *testing.T, n int) []string {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		if len(m.events) >= n {
+			out := append([]string(nil), m.events...)
+			m.mu.Unlock()
+			return out
+		}
+		m.mu.Unlock()
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d events: %v", n, m.events)
+	return nil
+}
+
+func TestSchedulerMetrics(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m))
+	if _, err := s.Add("job", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	// the machine is suspended past two occurrences
+	c.Step(2 * time.Hour)
+	c.Advance(time.Minute)
+
+	expected := []string{
+		"scheduled job 15:00",
+		"missed job 16:00",
+		"scheduled job 17:00",
+		"started job 15:00",
+		"finished job 15:00 boom",
+	}
+	events := m.waitFor(t, len(expected))
+	for i, e := range expected {
+		if events[i] != e {
+			t.Errorf("%d) '%s' Did not match! '%s'", i, events[i], e)
+		}
+	}
+}
+
+func TestSchedulerMetricsOverrun(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m))
+	release := make(chan struct{})
+	if _, err := s.Add("job", MustParseRule("* * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+	defer close(release)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	m.waitFor(t, 3)
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	events := m.waitFor(t, 6)
+	if events[3] != "overrun job 15:01" {
+		t.Errorf("'%s' Did not match!", events[3])
+	}
+}
@@ -0,0 +1,262 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseOptions holds the settings controlled by ParseOption values.
+type parseOptions struct {
+	extraWhitespace bool
+	comments        bool
+	mixedCaseNames  bool
+	quartzSteps     bool
+	strictStandard  bool
+	timesOfDay      map[string]TimeOfDay
+}
+
+// ParseOption configures how tolerant ParseRule is of its input.
+type ParseOption func(*parseOptions)
+
+// AllowExtraWhitespace tolerates leading and trailing whitespace and runs of spaces or tabs between fields. By
+// default fields must be separated by exactly one space.
+func AllowExtraWhitespace() ParseOption {
+	return func(o *parseOptions) {
+		o.extraWhitespace = true
+	}
+}
+
+// AllowComments tolerates a trailing comment starting with "#", such as "0 2 * * * # nightly". The "#" must start
+// the expression or follow whitespace, so that "5#3" in the day of week field is not mistaken for a comment.
+func AllowComments() ParseOption {
+	return func(o *parseOptions) {
+		o.comments = true
+	}
+}
+
+// AllowMixedCaseNames tolerates month and day of week names in any case, such as "Jan" or "mon". By default
+// names must be upper case.
+func AllowMixedCaseNames() ParseOption {
+	return func(o *parseOptions) {
+		o.mixedCaseNames = true
+	}
+}
+
+// QuartzSteps interprets an item of two values such as "3/5" as a step starting from the first value, as Quartz
+// and standard cron do, so "3/5" in the minute field matches 3, 8, 13, and so on up to 58. By default it is a list
+// matching only 3 and 5. Items with three or more values are always lists.
+func QuartzSteps() ParseOption {
+	return func(o *parseOptions) {
+		o.quartzSteps = true
+	}
+}
+
+// StrictStandard parses the fields using only standard cron syntax, with comma separated lists, ranges, and steps,
+// so "3/5" is a step starting from 3. The legacy "N/M/O" list syntax of this package is rejected; existing
+// expressions can be converted with MigrateLegacyExpression.
+func StrictStandard() ParseOption {
+	return func(o *parseOptions) {
+		o.strictStandard = true
+	}
+}
+
+// Lenient enables all of the leniency options. This is useful for ingesting raw crontab lines verbatim.
+func Lenient() ParseOption {
+	return func(o *parseOptions) {
+		AllowExtraWhitespace()(o)
+		AllowComments()(o)
+		AllowMixedCaseNames()(o)
+	}
+}
+
+// ParseRule constructs and validates a new Rule from a 5-part cron expression such as "*/5 * * * *". The fields
+// take the same forms as the arguments to NewRule. By default the expression must be written strictly; the given
+// options can be used to tolerate extra whitespace, comments, and mixed case names.
+//
+// The expression may be prefixed with "CRON_TZ=<zone>" or "TZ=<zone>", for example
+// "CRON_TZ=America/New_York 0 9 * * *", in which case the rule is bound to that location and all matching and
+// next time calculations are done in it.
+func ParseRule(expr string, opts ...ParseOption) (*Rule, error) {
+	o := new(parseOptions)
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	body := expr
+	if o.comments {
+		if i := commentStart(body); i >= 0 {
+			body = strings.TrimRight(body[:i], " \t")
+		}
+	}
+
+	parts := strings.Fields(body)
+	if !o.extraWhitespace && strings.Join(parts, " ") != body {
+		return nil, fmt.Errorf("Expression '%s' contains extra whitespace", expr)
+	}
+
+	var loc *time.Location
+	if len(parts) > 0 {
+		if zone, ok := cutZonePrefix(parts[0]); ok {
+			l, err := time.LoadLocation(zone)
+			if err != nil {
+				return nil, fmt.Errorf("Expression '%s' has invalid time zone: %s", expr, err.Error())
+			}
+			loc = l
+			parts = parts[1:]
+		}
+	}
+
+	// a resolved time of day stands in for both the minute and hour fields
+	var tod TimeOfDay
+	var todName string
+	if len(parts) == 4 && strings.HasPrefix(parts[0], "@") {
+		if tod = o.timesOfDay[parts[0][1:]]; tod != nil {
+			todName = parts[0][1:]
+			parts = append([]string{"*", "*"}, parts[1:]...)
+		}
+	}
+
+	if len(parts) != 5 {
+		return nil, fmt.Errorf("Expression '%s' must have 5 fields but has %d", expr, len(parts))
+	}
+	if o.mixedCaseNames {
+		parts[3] = strings.ToUpper(parts[3])
+		parts[4] = strings.ToUpper(parts[4])
+	}
+	if o.strictStandard {
+		for i, f := range ruleFields {
+			item, err := standardItem(parts[i], f)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = item
+		}
+	} else if o.quartzSteps {
+		for i, f := range ruleFields {
+			item, err := quartzStep(parts[i], f)
+			if err != nil {
+				return nil, err
+			}
+			parts[i] = item
+		}
+	}
+	r, err := NewRule(parts[0], parts[1], parts[2], parts[3], parts[4])
+	if err != nil {
+		return nil, err
+	}
+	r.location = loc
+	if tod != nil {
+		r = r.WithTimeOfDay(todName, tod)
+	}
+	return r, nil
+}
+
+// commentStart returns the index of the "#" starting a comment in expr, or -1 if there is none. Only a "#" at the
+// start of expr or after whitespace starts a comment.
+func commentStart(expr string) int {
+	for i := 0; i < len(expr); i++ {
+		if expr[i] == '#' && (i == 0 || expr[i-1] == ' ' || expr[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// standardItem converts an item in standard cron syntax into the form accepted by NewRule, rejecting the legacy
+// list syntax.
+func standardItem(item string, f field) (string, error) {
+	for _, part := range strings.Split(item, ",") {
+		if strings.Count(part, "/") > 1 {
+			return "", &SyntaxError{Item: item, Reason: "uses the legacy N/M/O list syntax, separate lists with commas"}
+		}
+	}
+	return translateItem(item, f, false)
+}
+
+// MigrateLegacyExpression rewrites an expression written in the native syntax of this package into standard cron
+// syntax that means the same thing, for example "0/15/30/45 9/10/11/12 * * 1/2/3/4/5" becomes
+// "*/15 9-12 * * 1-5". The result can be parsed with the StrictStandard option. Any time zone prefix is kept.
+// Expressions that cannot be parsed are returned unchanged.
+func MigrateLegacyExpression(expr string) string {
+	r, err := ParseRule(expr, AllowExtraWhitespace(), AllowMixedCaseNames())
+	if err != nil {
+		return expr
+	}
+	return r.locationPrefix() + r.ToStandardCron()
+}
+
+// isStartStep returns whether the item is two values separated by "/", which is a list here but a start and step
+// in Quartz and standard cron.
+func isStartStep(item string, f field) bool {
+	i := strings.Index(item, "/")
+	return i > 0 && isNumber(replaceNames(item[:i], f.names, f.offset)) && isNumber(item[i+1:]) &&
+		!strings.Contains(item[i+1:], "/")
+}
+
+// quartzStep expands a start and step item such as "3/5" into the list of values it matches. Other items are
+// returned unchanged, as are invalid starts so that NewRule reports them.
+func quartzStep(item string, f field) (string, error) {
+	if !isStartStep(item, f) {
+		return item, nil
+	}
+	i := strings.Index(item, "/")
+	start, err := strconv.Atoi(replaceNames(item[:i], f.names, f.offset))
+	if err != nil || start < f.min || start > f.max {
+		return item, nil
+	}
+	step, err := strconv.Atoi(item[i+1:])
+	if err != nil || step < 1 {
+		return "", &SyntaxError{Item: item, Reason: "has an invalid step"}
+	}
+	var values []int
+	for v := start; v <= f.max; v += step {
+		values = append(values, v)
+	}
+	return FieldValues(values).ruleItem(), nil
+}
+
+// cutZonePrefix returns the zone name from a "CRON_TZ=" or "TZ=" prefix field.
+func cutZonePrefix(field string) (string, bool) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if strings.HasPrefix(field, prefix) {
+			return field[len(prefix):], true
+		}
+	}
+	return "", false
+}
+
+// MustParseRule is like ParseRule but panics if there is an error parsing the expression
+func MustParseRule(expr string, opts ...ParseOption) *Rule {
+	r, err := ParseRule(expr, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// ParseSchedule parses any of the schedule expressions supported by this package. As well as the cron
+// expressions accepted by ParseRule, this supports "@every <duration>" for fixed intervals such as "@every 10m30s",
+// and "@at <RFC3339 time>" for one-off schedules such as "@at 2026-01-01T00:00:00Z".
+func ParseSchedule(expr string, opts ...ParseOption) (Schedule, error) {
+	trimmed := strings.TrimSpace(expr)
+	if strings.HasPrefix(trimmed, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(trimmed, "@every ")))
+		if err != nil {
+			return nil, fmt.Errorf("Expression '%s' has invalid duration: %s", expr, err.Error())
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("Expression '%s' must have a duration of at least 1s", expr)
+		}
+		return Every(d), nil
+	}
+	if strings.HasPrefix(trimmed, "@at ") {
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(strings.TrimPrefix(trimmed, "@at ")))
+		if err != nil {
+			return nil, fmt.Errorf("Expression '%s' has invalid time: %s", expr, err.Error())
+		}
+		return At(t), nil
+	}
+	return ParseRule(expr, opts...)
+}
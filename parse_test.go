@@ -0,0 +1,236 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	r, err := ParseRule("10/20/30 */5 1 2/3 *")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "10/20/30 */5 1 2/3 *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+}
+
+func TestParseRuleBadFieldCount(t *testing.T) {
+	_, err := ParseRule("* * * *")
+	if err == nil {
+		t.Error("should have failed")
+		return
+	}
+	_, err = ParseRule("* * * * * *")
+	if err == nil {
+		t.Error("should have failed")
+		return
+	}
+}
+
+func TestParseRuleNames(t *testing.T) {
+	r, err := ParseRule("0 9 * JAN/JUL MON/FRI")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if s := r.StringNormalized(); s != "0 9 * 1,7 1,5" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if _, err = ParseRule("0 9 * jan mon"); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestParseRuleWhitespace(t *testing.T) {
+	expr := " 10/20/30  */5 1\t2/3 *  "
+	if _, err := ParseRule(expr); err == nil {
+		t.Error("should have failed")
+		return
+	}
+	r, err := ParseRule(expr, AllowExtraWhitespace())
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "10/20/30 */5 1 2/3 *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+}
+
+func TestParseRuleComments(t *testing.T) {
+	expr := "0 2 * * * # nightly"
+	if _, err := ParseRule(expr); err == nil {
+		t.Error("should have failed")
+		return
+	}
+	r, err := ParseRule(expr, AllowComments())
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "0 2 * * *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+
+	// "#" within an item is the occurrence of a day of week rather than a comment
+	cases := map[string]string{
+		"0 9 * * 1#2":             "0 9 * * 1#2",
+		"0 9 * * 1#2 # second":    "0 9 * * 1#2",
+		"0 9 * * MON#2\t# second": "0 9 * * MON#2",
+	}
+	for expr, e := range cases {
+		for _, opt := range []ParseOption{AllowComments(), Lenient()} {
+			r, err := ParseRule(expr, opt)
+			if err != nil {
+				t.Errorf("%s: %v", expr, err)
+			} else if r.String() != e {
+				t.Errorf("%s: '%s' Did not match! '%s'", expr, r.String(), e)
+			}
+		}
+	}
+	if _, err := ParseRule("0 9 * * 1#", AllowComments()); err == nil {
+		t.Error("0 9 * * 1# should have failed")
+	}
+}
+
+func TestParseRuleLenient(t *testing.T) {
+	r, err := ParseRule("  0\t9 * Jan/jul mon/Fri   # weekly report", Lenient())
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "0 9 * JAN/JUL MON/FRI" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+}
+
+func TestParseRuleTimeZone(t *testing.T) {
+	r, err := ParseRule("CRON_TZ=America/New_York 0 9 * * *")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.String() != "CRON_TZ=America/New_York 0 9 * * *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+
+	from := time.Date(2000, 4, 28, 12, 0, 0, 0, time.UTC)
+	n := r.NextAfter(from)
+	e := time.Date(2000, 4, 28, 13, 0, 0, 0, time.UTC)
+	if !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if n.Location() != r.Location() {
+		t.Errorf("%s should be in %s", n, r.Location())
+	}
+	if !r.Matches(e) {
+		t.Errorf("%s should match", e)
+	}
+	if r.Matches(time.Date(2000, 4, 28, 9, 0, 0, 0, time.UTC)) {
+		t.Error("09:00 UTC should not match")
+	}
+
+	r, err = ParseRule("TZ=Europe/London 0 9 * * *")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if r.Location().String() != "Europe/London" {
+		t.Errorf("%s should be Europe/London", r.Location())
+	}
+
+	if _, err = ParseRule("CRON_TZ=Nowhere/Special 0 9 * * *"); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func BenchmarkParseRule(b *testing.B) {
+	exprs := []string{"* * * * *", "*/5 9/10/11/12 * * 1/2/3/4/5", "0 0 1/15 JAN/JUL *", "30 2 * * 5#3"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ParseRule(exprs[i%len(exprs)])
+	}
+}
+
+func TestQuartzSteps(t *testing.T) {
+	cases := map[string]string{
+		"3/20 * * * *":             "3,23,43 * * * *",
+		"0/15/30 * * * *":          "0,15,30 * * * *",
+		"0 6/6 * * *":              "0 6,12,18 * * *",
+		"0 0 * FEB/4 *":            "0 0 * 2,6,10 *",
+		"0 0 * * MON/2":            "0 0 * * 1,3,5",
+		"*/20 * * * *":             "0,20,40 * * * *",
+		"CRON_TZ=UTC 5/30 * * * *": "CRON_TZ=UTC 5,35 * * * *",
+	}
+	for expr, e := range cases {
+		r, err := ParseRule(expr, QuartzSteps())
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+		} else if r.StringNormalized() != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, r.StringNormalized(), e)
+		}
+	}
+	if r := MustParseRule("3/20 * * * *"); r.StringNormalized() != "3,20 * * * *" {
+		t.Errorf("'%s' Did not match!", r.StringNormalized())
+	}
+	for _, expr := range []string{"60/5 * * * *", "5/0 * * * *", "5/x * * * *"} {
+		if _, err := ParseRule(expr, QuartzSteps()); err == nil {
+			t.Errorf("%s: should have failed", expr)
+		}
+	}
+}
+
+func TestStrictStandard(t *testing.T) {
+	cases := map[string]string{
+		"*/15 9-17 * * 1-5":  "0,15,30,45 9,10,11,12,13,14,15,16,17 * * 1,2,3,4,5",
+		"3/20 * * * *":       "3,23,43 * * * *",
+		"0 0 1,15 JAN-MAR *": "0 0 1,15 1,2,3 *",
+		"0 0 * * 7":          "0 0 * * 0",
+		"0 0 * * 5#3":        "0 0 * * 5#3",
+		"0/15/30 * * * *":    "",
+		"0 0 * * 1,2/3/4":    "",
+		"0 0 * * 8":          "",
+	}
+	for expr, e := range cases {
+		r, err := ParseRule(expr, StrictStandard())
+		if e == "" {
+			if err == nil {
+				t.Errorf("%s: should have failed", expr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+		} else if r.StringNormalized() != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, r.StringNormalized(), e)
+		}
+	}
+}
+
+func TestMigrateLegacyExpression(t *testing.T) {
+	cases := map[string]string{
+		"0/15/30/45 9/10/11/12 * * 1/2/3/4/5": "*/15 9-12 * * 1-5",
+		"5/35 * * * *":                        "5,35 * * * *",
+		"CRON_TZ=UTC 0 9 1/15 JAN/JUL *":      "CRON_TZ=UTC 0 9 1,15 1,7 *",
+		"0 0 * * 5#2":                         "0 0 * * 5#2",
+		"not valid":                           "not valid",
+	}
+	for expr, e := range cases {
+		migrated := MigrateLegacyExpression(expr)
+		if migrated != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, migrated, e)
+			continue
+		}
+		if expr == e {
+			continue
+		}
+		r, err := ParseRule(migrated, StrictStandard())
+		if err != nil {
+			t.Errorf("%s: %s", migrated, err)
+		} else if r.StringNormalized() != MustParseRule(expr).StringNormalized() {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, r.StringNormalized(), MustParseRule(expr).StringNormalized())
+		}
+	}
+}
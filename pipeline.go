@@ -0,0 +1,97 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"time"
+)
+
+// Dependency defines the schedule of a job relative to an upstream job: for each upstream occurrence it fires at
+// the first occurrence of Rule at least Delay later.
+type Dependency struct {
+	Rule  *Rule
+	Delay time.Duration
+}
+
+// After returns when the job fires for an upstream occurrence at the given time.
+func (d Dependency) After(upstream time.Time) time.Time {
+	return d.Rule.NextAfter(upstream.Add(d.Delay - time.Nanosecond))
+}
+
+// FireTimes returns when the job fires for each of the given upstream occurrences, in order. Upstream occurrences
+// that lead to the same time only fire the job once.
+func (d Dependency) FireTimes(upstream []time.Time) []time.Time {
+	var out []time.Time
+	for _, u := range upstream {
+		t := d.After(u)
+		if t.Equal(farFuture) {
+			continue
+		}
+		if len(out) == 0 || t.After(out[len(out)-1]) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// Pipeline is a set of jobs where some jobs are scheduled relative to others, as is common in ETL pipelines where
+// a load runs at the first quiet slot after an extract has had time to finish. Jobs must be added after the jobs
+// they depend on, so a Pipeline can never contain a cycle.
+type Pipeline struct {
+	jobs map[string]pipelineJob
+}
+
+type pipelineJob struct {
+	schedule   Schedule
+	upstream   string
+	dependency Dependency
+}
+
+// NewPipeline returns a Pipeline with no jobs.
+func NewPipeline() *Pipeline {
+	return &Pipeline{jobs: map[string]pipelineJob{}}
+}
+
+// AddJob adds a job that runs on its own schedule. An error is returned if the name is already used.
+func (p *Pipeline) AddJob(name string, schedule Schedule) error {
+	if _, ok := p.jobs[name]; ok {
+		return fmt.Errorf("Job '%s' is already defined", name)
+	}
+	p.jobs[name] = pipelineJob{schedule: schedule}
+	return nil
+}
+
+// AddDependent adds a job that runs after each occurrence of the upstream job as described by dep. An error is
+// returned if the name is already used or the upstream job has not been added.
+func (p *Pipeline) AddDependent(name, upstream string, dep Dependency) error {
+	if _, ok := p.jobs[name]; ok {
+		return fmt.Errorf("Job '%s' is already defined", name)
+	}
+	if _, ok := p.jobs[upstream]; !ok {
+		return fmt.Errorf("Job '%s' depends on unknown job '%s'", name, upstream)
+	}
+	p.jobs[name] = pipelineJob{upstream: upstream, dependency: dep}
+	return nil
+}
+
+// FireTimes returns when the named job fires for the occurrences of the job at the root of its dependencies within
+// [start, end). The fire times of a dependent job may fall after end, since they belong to runs that started
+// within the range.
+func (p *Pipeline) FireTimes(name string, start, end time.Time) ([]time.Time, error) {
+	job, ok := p.jobs[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown job '%s'", name)
+	}
+	if job.schedule == nil {
+		upstream, err := p.FireTimes(job.upstream, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return job.dependency.FireTimes(upstream), nil
+	}
+
+	var out []time.Time
+	for t := job.schedule.NextAfter(start.Add(-time.Nanosecond)); t.Before(end); t = job.schedule.NextAfter(t) {
+		out = append(out, t)
+	}
+	return out, nil
+}
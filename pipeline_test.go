@@ -0,0 +1,72 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDependency(t *testing.T) {
+	// loads run on the hour or half hour at least 20 minutes after the extract
+	d := Dependency{Rule: MustParseRule("0/30 * * * *"), Delay: 20 * time.Minute}
+	cases := map[string]string{
+		"2026-03-02T01:00:00Z": "2026-03-02T01:30:00Z",
+		"2026-03-02T01:10:00Z": "2026-03-02T01:30:00Z",
+		"2026-03-02T01:15:00Z": "2026-03-02T02:00:00Z",
+	}
+	for in, e := range cases {
+		u, _ := time.Parse(time.RFC3339, in)
+		if s := d.After(u).Format(time.RFC3339); s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", in, s, e)
+		}
+	}
+
+	// upstream runs landing in the same slot only fire the job once
+	var upstream []time.Time
+	for _, s := range []string{"2026-03-02T01:00:00Z", "2026-03-02T01:05:00Z", "2026-03-02T01:40:00Z"} {
+		u, _ := time.Parse(time.RFC3339, s)
+		upstream = append(upstream, u)
+	}
+	if out := d.FireTimes(upstream); len(out) != 2 || out[0].Minute() != 30 || out[1].Hour() != 2 {
+		t.Errorf("%v Did not match!", out)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	p := NewPipeline()
+	if err := p.AddJob("extract", MustParseRule("0 1/13 * * *")); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddDependent("transform", "extract", Dependency{MustParseRule("*/15 * * * *"), 40 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddDependent("load", "transform", Dependency{MustParseRule("0 * * * *"), 10 * time.Minute}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddJob("load", MustParseRule("* * * * *")); err == nil {
+		t.Error("expected an error for a duplicate job")
+	}
+	if err := p.AddDependent("report", "missing", Dependency{MustParseRule("* * * * *"), 0}); err == nil {
+		t.Error("expected an error for an unknown upstream job")
+	}
+
+	start := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	out, err := p.FireTimes("load", start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []time.Time{
+		time.Date(2026, 3, 2, 2, 0, 0, 0, time.UTC),
+		time.Date(2026, 3, 2, 14, 0, 0, 0, time.UTC),
+	}
+	if len(out) != len(expected) {
+		t.Fatalf("%v != %v", out, expected)
+	}
+	for i, e := range expected {
+		if !out[i].Equal(e) {
+			t.Errorf("%s != %s", out[i], e)
+		}
+	}
+	if _, err := p.FireTimes("missing", start, start.Add(time.Hour)); err == nil {
+		t.Error("expected an error for an unknown job")
+	}
+}
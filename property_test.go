@@ -0,0 +1,167 @@
+package ticktickrules
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"testing/quick"
+	"time"
+)
+
+// randomRuleItem returns a random rule item of any of the supported forms for a field with the given bounds.
+func randomRuleItem(rnd *rand.Rand, min, max int) string {
+	switch rnd.Intn(5) {
+	case 0:
+		return "*"
+	case 1:
+		return "*/" + strconv.Itoa(1+rnd.Intn(max))
+	case 2:
+		a := min + rnd.Intn(max-min)
+		return strconv.Itoa(a) + "/" + strconv.Itoa(a+1+rnd.Intn(max-a))
+	case 3:
+		a := min + rnd.Intn(max-min)
+		item := strconv.Itoa(a) + "-" + strconv.Itoa(a+1+rnd.Intn(max-a))
+		if rnd.Intn(2) == 0 {
+			item += "/" + strconv.Itoa(1+rnd.Intn(max))
+		}
+		return item + "," + strconv.Itoa(min+rnd.Intn(max-min+1))
+	default:
+		return strconv.Itoa(min + rnd.Intn(max-min+1))
+	}
+}
+
+// invariantCase is a random rule, location, and starting time used by the invariant checks.
+type invariantCase struct {
+	Rule *Rule
+	From time.Time
+}
+
+var invariantLocations = []string{"UTC", "Europe/London", "America/New_York", "Australia/Lord_Howe"}
+
+// Generate implements quick.Generator.
+func (invariantCase) Generate(rnd *rand.Rand, size int) reflect.Value {
+	var r *Rule
+	for r == nil {
+		r, _ = NewRule(
+			randomRuleItem(rnd, 0, 59),
+			randomRuleItem(rnd, 0, 23),
+			randomRuleItem(rnd, 1, 28),
+			randomRuleItem(rnd, 1, 12),
+			randomRuleItem(rnd, 0, 6),
+		)
+	}
+	loc, err := time.LoadLocation(invariantLocations[rnd.Intn(len(invariantLocations))])
+	if err != nil {
+		loc = time.UTC
+	}
+	r = r.In(loc).WithFallBackPolicy(FallBackPolicy(rnd.Intn(3))).WithSpringForwardPolicy(SpringForwardPolicy(rnd.Intn(2)))
+	from := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(rnd.Int63n(int64(4 * 365 * 24 * time.Hour))))
+	if rnd.Intn(2) == 0 {
+		from = from.Truncate(time.Minute)
+	}
+	return reflect.ValueOf(invariantCase{Rule: r, From: from})
+}
+
+// unreachable is later than any time returned for a rule that is never matched.
+var unreachable = time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// slowNextFrom is the reference implementation that the optimized search is checked against. It finds the next
+// match after from by testing every minute in turn, up to limit minutes ahead, and returns farFuture if there is
+// none.
+func slowNextFrom(r *Rule, from time.Time, limit int) time.Time {
+	t := truncateMinute(r.localize(from))
+	for i := 0; i < limit; i++ {
+		t = t.Add(time.Minute)
+		if r.Matches(t) {
+			return t
+		}
+	}
+	return farFuture
+}
+
+// slowFloor is like slowNextFrom but searches backwards for the last match at or before from.
+func slowFloor(r *Rule, from time.Time, limit int) time.Time {
+	t := truncateMinute(r.localize(from))
+	for i := 0; i < limit; i++ {
+		if r.Matches(t) {
+			return t
+		}
+		t = t.Add(-time.Minute)
+	}
+	return farPast
+}
+
+func TestDifferentialNextAfter(t *testing.T) {
+	// two months keeps the scans quick while covering every carry up to the month
+	limit := 62 * 24 * 60
+	check := func(c invariantCase) bool {
+		e := slowNextFrom(c.Rule, c.From, limit)
+		n := c.Rule.NextAfter(c.From)
+		if e.Equal(farFuture) {
+			// the reference gave up, so all that can be checked is that nothing was missed within its limit
+			return n.Sub(c.From) > time.Duration(limit-1)*time.Minute
+		}
+		if !n.Equal(e) {
+			t.Logf("%s from %s gave %s, expected %s", c.Rule, c.From, n, e)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(check, &quick.Config{MaxCount: 300, Rand: rand.New(rand.NewSource(1))}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDifferentialFloor(t *testing.T) {
+	limit := 62 * 24 * 60
+	check := func(c invariantCase) bool {
+		e := slowFloor(c.Rule, c.From, limit)
+		f := c.Rule.Floor(c.From)
+		if e.Equal(farPast) {
+			return c.From.Sub(f) >= time.Duration(limit-1)*time.Minute
+		}
+		if !f.Equal(e) {
+			t.Logf("%s floor of %s gave %s, expected %s", c.Rule, c.From, f, e)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(check, &quick.Config{MaxCount: 300, Rand: rand.New(rand.NewSource(1))}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvariantNextAfterMatches(t *testing.T) {
+	check := func(c invariantCase) bool {
+		n := c.Rule.NextAfter(c.From)
+		if n.After(unreachable) {
+			return true
+		}
+		if !n.After(c.From) || !c.Rule.Matches(n) {
+			t.Logf("%s from %s gave %s", c.Rule, c.From, n)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(check, &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInvariantFloorMatches(t *testing.T) {
+	check := func(c invariantCase) bool {
+		f := c.Rule.Floor(c.From)
+		if f.Year() < 1000 {
+			return true
+		}
+		if f.After(c.From) || !c.Rule.Matches(f) {
+			t.Logf("%s floor of %s gave %s", c.Rule, c.From, f)
+			return false
+		}
+		return c.Rule.NextAfter(f.Add(-time.Nanosecond)).Equal(f)
+	}
+	if err := quick.Check(check, &quick.Config{MaxCount: 2000, Rand: rand.New(rand.NewSource(1))}); err != nil {
+		t.Error(err)
+	}
+}
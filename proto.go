@@ -0,0 +1,145 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RuleProto is a stable representation of the parsed fields of a rule, for sending schedules through APIs such as
+// gRPC without passing expression strings that each side might parse differently. Its fields mirror the
+// RuleProto message in rulepb/rule.proto, so converting to and from generated code is a field by field copy.
+//
+// Empty value lists match any value. Days of the week are 0-6 starting on Sunday.
+type RuleProto struct {
+	Minutes     []int32
+	Hours       []int32
+	DaysOfMonth []int32
+	Months      []int32
+	DaysOfWeek  []int32
+	// DayOfWeekNth is the occurrence within the month for a "N#K" day of week, or 0.
+	DayOfWeekNth int32
+	// DayOfWeekLast is set for a "NL" day of week.
+	DayOfWeekLast bool
+	// DayOfMonthLast is set for a "L" or "L-N" day of month, with DayOfMonthOffset as N.
+	DayOfMonthLast   bool
+	DayOfMonthOffset int32
+	// Location is the name of the location the rule is bound to, or empty.
+	Location string
+	// FixedOffset is the offset from UTC in seconds of a location that never changes its clocks, used when the
+	// name cannot be loaded on the receiving side.
+	FixedOffset   int32
+	Fixed         bool
+	FallBack      int32
+	SpringForward int32
+	Granularity   int32
+	EitherDay     bool
+	// SearchHorizon is the duration set with WithSearchHorizon in nanoseconds, or 0 for DefaultSearchHorizon.
+	SearchHorizon int64
+}
+
+// ToProto returns the fields of the rule as a RuleProto. An error is returned for rules with a calendar or the
+// other restrictions added with the With methods, which RuleProto cannot represent.
+func (r *Rule) ToProto() (*RuleProto, error) {
+	if s := r.restriction(); s != "" {
+		return nil, fmt.Errorf("Rule '%s' has %s, which cannot be represented as a RuleProto", r, s)
+	}
+	out := &RuleProto{
+		Minutes:          int32s(r.Minutes()),
+		Hours:            int32s(r.Hours()),
+		DaysOfMonth:      int32s(r.DaysOfMonth()),
+		Months:           int32s(r.Months()),
+		DaysOfWeek:       int32s(r.DaysOfWeek()),
+		DayOfWeekNth:     int32(r.dayOfWeekNth),
+		DayOfWeekLast:    r.dayOfWeekLast,
+		DayOfMonthLast:   r.dayOfMonthLast,
+		DayOfMonthOffset: int32(r.dayOfMonthOffset),
+		FallBack:         int32(r.fallBack),
+		SpringForward:    int32(r.springForward),
+		Granularity:      int32(r.granularity),
+		EitherDay:        r.eitherDay,
+		SearchHorizon:    int64(r.searchHorizon),
+	}
+	if r.location != nil {
+		out.Location = r.location.String()
+		start, end := time.Now().In(r.location).ZoneBounds()
+		_, offset := time.Now().In(r.location).Zone()
+		out.Fixed = start.IsZero() && end.IsZero()
+		out.FixedOffset = int32(offset)
+	}
+	return out, nil
+}
+
+// FromProto returns the rule described by p, validating it in the same way as NewRule.
+func FromProto(p *RuleProto) (*Rule, error) {
+	dom := protoItem(p.DaysOfMonth, dayOfMonthField)
+	if p.DayOfMonthLast {
+		if len(p.DaysOfMonth) > 0 {
+			return nil, fmt.Errorf("Rule proto has both days of month and a last day of month")
+		}
+		dom = "L"
+		if p.DayOfMonthOffset != 0 {
+			dom += "-" + strconv.Itoa(int(p.DayOfMonthOffset))
+		}
+	}
+	dow := protoItem(p.DaysOfWeek, dayOfWeekField)
+	if p.DayOfWeekLast {
+		dow += "L"
+	} else if p.DayOfWeekNth != 0 {
+		dow += "#" + strconv.Itoa(int(p.DayOfWeekNth))
+	}
+
+	out, err := NewRule(protoItem(p.Minutes, minuteField), protoItem(p.Hours, hourField), dom,
+		protoItem(p.Months, monthField), dow)
+	if err != nil {
+		return nil, err
+	}
+	if p.FallBack < int32(FireEarliest) || p.FallBack > int32(FireTwice) {
+		return nil, fmt.Errorf("Rule proto has unknown fall back policy %d", p.FallBack)
+	}
+	if p.SpringForward < int32(SkipMissing) || p.SpringForward > int32(ShiftMissing) {
+		return nil, fmt.Errorf("Rule proto has unknown spring forward policy %d", p.SpringForward)
+	}
+	if p.Granularity < int32(MinuteGranularity) || p.Granularity > int32(SecondGranularity) {
+		return nil, fmt.Errorf("Rule proto has unknown granularity %d", p.Granularity)
+	}
+	out.fallBack = FallBackPolicy(p.FallBack)
+	out.springForward = SpringForwardPolicy(p.SpringForward)
+	out.granularity = Granularity(p.Granularity)
+	out.eitherDay = p.EitherDay
+	out.searchHorizon = time.Duration(p.SearchHorizon)
+
+	if p.Location != "" {
+		if out.location, err = time.LoadLocation(p.Location); err != nil {
+			if !p.Fixed {
+				return nil, err
+			}
+			out.location = time.FixedZone(p.Location, int(p.FixedOffset))
+		}
+	}
+	return out, nil
+}
+
+// int32s converts field values for a RuleProto.
+func int32s(values FieldValues) []int32 {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make([]int32, len(values))
+	for i, v := range values {
+		out[i] = int32(v)
+	}
+	return out
+}
+
+// protoItem renders the values of a RuleProto field as a rule item, leaving range checks to NewRule.
+func protoItem(values []int32, f field) string {
+	if len(values) == 0 {
+		return "*"
+	}
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return normalizeField(ints, f.min, f.max).ruleItem()
+}
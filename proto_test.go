@@ -0,0 +1,71 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProto(t *testing.T) {
+	for _, r := range []*Rule{
+		MustParseRule("* * * * *"),
+		MustParseRule("0,30 9-17 * * MON-FRI"),
+		MustParseRule("CRON_TZ=Europe/London 0 9 * * 1#2").WithFallBackPolicy(FireTwice),
+		MustParseRule("0 17 L-3 * *").WithGranularity(SecondGranularity),
+		MustParseRule("0 17 * JUN 5L").In(time.FixedZone("India", 5*3600+30*60)),
+		MustParseRule("0 0 29 2 *").WithSearchHorizon(48 * time.Hour),
+	} {
+		p, err := r.ToProto()
+		if err != nil {
+			t.Errorf("%s: %s", r, err)
+			continue
+		}
+		out, err := FromProto(p)
+		if err != nil {
+			t.Errorf("%s: %s", r, err)
+			continue
+		}
+		if out.Fingerprint() != r.Fingerprint() || out.Location().String() != r.Location().String() {
+			t.Errorf("'%s' != '%s'", out.StringNormalized(), r.StringNormalized())
+		}
+		if out.SearchHorizon() != r.SearchHorizon() {
+			t.Errorf("%s horizon %s != %s", r, out.SearchHorizon(), r.SearchHorizon())
+		}
+	}
+
+	k, _ := ParseKubernetes("0 0 1 * 1")
+	kp, _ := k.ToProto()
+	if out, err := FromProto(kp); err != nil || !out.eitherDay {
+		t.Errorf("%v should keep either day matching", err)
+	}
+
+	if p, _ := MustParseRule("*/20 0 1 JAN SUN").ToProto(); len(p.Minutes) != 3 || p.Minutes[2] != 40 || len(p.Months) != 1 || p.DaysOfWeek[0] != 0 {
+		t.Errorf("%#v Did not match!", p)
+	}
+
+	for _, p := range []*RuleProto{
+		{Minutes: []int32{60}},
+		{DaysOfWeek: []int32{1, 2}, DayOfWeekNth: 2},
+		{DaysOfMonth: []int32{1}, DayOfMonthLast: true},
+		{FallBack: 7},
+		{Granularity: -1},
+		{Location: "Nowhere/Special"},
+	} {
+		if _, err := FromProto(p); err == nil {
+			t.Errorf("%#v: expected an error", p)
+		}
+	}
+}
+
+func TestProtoRestrictions(t *testing.T) {
+	r := MustParseRule("0 0 * * *")
+	for _, x := range []*Rule{
+		r.WithCalendar(NewHolidays()),
+		mustRule(r.WithISOWeeks(1)),
+		mustRule(r.WithWeeksOfMonth(2)),
+		mustRule(r.WithBusinessDay(1)),
+	} {
+		if _, err := x.ToProto(); err == nil {
+			t.Errorf("%s should not have been converted", x)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// TimeOfDay resolves a time of day that changes from one day to the next, such as sunset, which the application
+// provides for each date. Only the date returned by date.Date() is meaningful.
+type TimeOfDay interface {
+	// At returns the hour and minute on the date, or false if there is none that day, such as sunset during the
+	// polar night.
+	At(date time.Time) (hour, minute int, ok bool)
+}
+
+// TimeOfDayFunc adapts an ordinary function to the TimeOfDay interface.
+type TimeOfDayFunc func(date time.Time) (hour, minute int, ok bool)
+
+// At calls f(date).
+func (f TimeOfDayFunc) At(date time.Time) (int, int, bool) {
+	return f(date)
+}
+
+// WithTimeOfDay returns a copy of the rule that fires once on each matching day at the time resolved by tod, in
+// place of its minute and hour fields. The name is shown as "@name" in place of those fields by String, so
+// WithTimeOfDay("sunset", tod) on "0 9 * * MON-FRI" gives "@sunset * * MON-FRI". The name is also used by
+// StringNormalized and Fingerprint, so resolvers should be given distinct names. Format, ToProto and MarshalBinary
+// return an error for rules with a TimeOfDay, which none of those forms can express.
+func (r *Rule) WithTimeOfDay(name string, tod TimeOfDay) *Rule {
+	out := *r
+	out.minute, out.minuteRule = nil, "*"
+	out.hour, out.hourRule = nil, "*"
+	out.timeOfDay, out.timeOfDayName = tod, name
+	out.buildMasks()
+	return &out
+}
+
+// ResolveTimeOfDay accepts "@name" in place of the minute and hour fields, so with ResolveTimeOfDay("sunset", tod)
+// the expression "@sunset * * MON-FRI" fires at sunset on weekdays. See WithTimeOfDay.
+func ResolveTimeOfDay(name string, tod TimeOfDay) ParseOption {
+	return func(o *parseOptions) {
+		if o.timesOfDay == nil {
+			o.timesOfDay = make(map[string]TimeOfDay)
+		}
+		o.timesOfDay[name] = tod
+	}
+}
+
+// resolveTimeOfDay returns the hour and minute the resolver gives for the date of t. Values out of range are
+// treated as no time on that day.
+func (r *Rule) resolveTimeOfDay(t time.Time) (int, int, bool) {
+	h, m, ok := r.timeOfDay.At(civilDate(t.Date()))
+	if !ok || h < hourField.min || h > hourField.max || m < minuteField.min || m > minuteField.max {
+		return 0, 0, false
+	}
+	return h, m, true
+}
+
+// clockOn returns the hours and minutes the rule fires at on the given date, which are hours and minutes unless
+// the rule has a TimeOfDay. False is returned if the TimeOfDay has no time on that date.
+func (r *Rule) clockOn(date time.Time, hours, minutes []int) ([]int, []int, bool) {
+	if r.timeOfDay == nil {
+		return hours, minutes, true
+	}
+	h, m, ok := r.resolveTimeOfDay(date)
+	if !ok {
+		return nil, nil, false
+	}
+	return []int{h}, []int{m}, true
+}
+
+// matchesClock returns whether the hour and minute of m are matched by the rule.
+func (r *Rule) matchesClock(m *moment) bool {
+	if r.timeOfDay == nil {
+		return hasBit(r.masks.hour, m.hour) && hasBit(r.masks.minute, m.minute)
+	}
+	h, min, ok := r.resolveTimeOfDay(m.t)
+	return ok && h == m.hour && min == m.minute
+}
@@ -0,0 +1,122 @@
+package ticktickrules
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// testSunset is a made up sunset that is a minute later each day of the month, with none on the 13th.
+var testSunset = TimeOfDayFunc(func(date time.Time) (int, int, bool) {
+	if date.Day() == 13 {
+		return 0, 0, false
+	}
+	return 18, date.Day(), true
+})
+
+func TestTimeOfDay(t *testing.T) {
+	r, err := ParseRule("@sunset * * MON-FRI", ResolveTimeOfDay("sunset", testSunset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != "@sunset * * MON-FRI" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+	if d := r.Describe(); !strings.HasPrefix(d, "at @sunset on Monday") {
+		t.Errorf("'%s' Did not match!", d)
+	}
+
+	// Thursday the 12th, then Friday the 13th has no sunset so the weekend is skipped too
+	from := time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC)
+	expected := []time.Time{
+		time.Date(2026, 3, 12, 18, 12, 0, 0, time.UTC),
+		time.Date(2026, 3, 16, 18, 16, 0, 0, time.UTC),
+		time.Date(2026, 3, 17, 18, 17, 0, 0, time.UTC),
+	}
+	for _, e := range expected {
+		from = r.NextAfter(from)
+		if !from.Equal(e) {
+			t.Errorf("%s != %s", from, e)
+		}
+		if !r.Matches(e) || r.Matches(e.Add(time.Minute)) || r.Matches(e.Add(time.Hour)) {
+			t.Errorf("%s did not match as expected around %s", r, e)
+		}
+	}
+	if f := r.Floor(time.Date(2026, 3, 16, 18, 15, 0, 0, time.UTC)); !f.Equal(expected[0]) {
+		t.Errorf("%s != %s", f, expected[0])
+	}
+	if n := r.NthFrom(time.Date(2026, 3, 12, 9, 0, 0, 0, time.UTC), 3); !n.Equal(expected[2]) {
+		t.Errorf("%s != %s", n, expected[2])
+	}
+	if c := r.CountBetween(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)); c != 21 {
+		t.Errorf("%d != 21", c)
+	}
+	if _, err := r.Shifted(time.Hour); err == nil {
+		t.Error("shifting a resolved time of day should have failed")
+	}
+
+	// without the option the name is not recognised
+	if _, err := ParseRule("@sunset * * MON-FRI"); err == nil {
+		t.Error("@sunset should have failed without ResolveTimeOfDay")
+	}
+}
+
+func TestWithTimeOfDay(t *testing.T) {
+	r := MustParseRule("CRON_TZ=UTC 0 9 1 * *").WithTimeOfDay("sunset", testSunset)
+	if r.String() != "CRON_TZ=UTC @sunset 1 * *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+	if r.Matches(time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)) || !r.Matches(time.Date(2026, 3, 1, 18, 1, 0, 0, time.UTC)) {
+		t.Errorf("%s did not replace the hour and minute", r)
+	}
+
+	// times out of range are treated as no time on that day
+	broken := MustParseRule("* * * * *").WithTimeOfDay("broken", TimeOfDayFunc(func(date time.Time) (int, int, bool) {
+		return 24, 0, true
+	}))
+	if n := broken.WithSearchHorizon(48 * time.Hour).NextAfter(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)); !n.Equal(farFuture) {
+		t.Errorf("%s != %s", n, farFuture)
+	}
+}
+
+func TestTimeOfDayExport(t *testing.T) {
+	plain := MustParseRule("* * 1 * *")
+	r := plain.WithTimeOfDay("sunset", testSunset)
+	if s := r.StringNormalized(); s != "@sunset 1 * *" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if r.Fingerprint() == plain.Fingerprint() {
+		t.Error("the resolver should change the fingerprint")
+	}
+	if r.Fingerprint() == plain.WithTimeOfDay("sunrise", testSunset).Fingerprint() {
+		t.Error("the resolver name should change the fingerprint")
+	}
+	if s, err := r.Format(Standard); err == nil {
+		t.Errorf("formatting a resolved time of day should have failed, got '%s'", s)
+	}
+	if _, err := r.ToProto(); err == nil {
+		t.Error("converting a resolved time of day to a proto should have failed")
+	}
+	if _, err := r.MarshalBinary(); err == nil {
+		t.Error("encoding a resolved time of day should have failed")
+	}
+
+	// replacing the minute or hour drops the resolver, other fields keep it
+	m, err := r.WithMinute("15")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.String() != "15 * 1 * *" {
+		t.Errorf("'%s' Did not match!", m.String())
+	}
+	if !m.Matches(time.Date(2026, 3, 1, 9, 15, 0, 0, time.UTC)) {
+		t.Errorf("%s did not match at 09:15", m)
+	}
+	d, err := r.WithDayOfMonth("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "@sunset 2 * *" {
+		t.Errorf("'%s' Did not match!", d.String())
+	}
+}
@@ -0,0 +1,201 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// rruleDays are the two letter day names used by iCalendar, indexed by day of week from Sunday.
+var rruleDays = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// rruleFrequencies are the supported FREQ values from finest to coarsest.
+var rruleFrequencies = []string{"MINUTELY", "HOURLY", "DAILY", "WEEKLY", "MONTHLY", "YEARLY"}
+
+// ParseRRule parses an iCalendar recurrence rule as defined by RFC 5545, such as
+// "FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30". The "RRULE:" prefix is optional.
+//
+// Only rules that can be represented without a start date are supported, so INTERVAL must be 1 and COUNT, UNTIL,
+// BYSETPOS and the like are rejected. A recurrence takes any parts not given from its start date, which this
+// package has no notion of, so these default to the start of the period instead: minute 0, hour 0, the 1st of the
+// month, and January. Weekly rules must give BYDAY.
+func ParseRRule(s string) (*Rule, error) {
+	parts := map[string]string{}
+	for _, p := range strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "RRULE:"), ";") {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("Recurrence rule '%s' has invalid part '%s'", s, p)
+		}
+		parts[strings.ToUpper(kv[0])] = strings.ToUpper(kv[1])
+	}
+
+	freq := -1
+	for i, f := range rruleFrequencies {
+		if parts["FREQ"] == f {
+			freq = i
+		}
+	}
+	if freq < 0 {
+		return nil, fmt.Errorf("Recurrence rule '%s' has unsupported FREQ '%s'", s, parts["FREQ"])
+	}
+	for k, v := range parts {
+		switch k {
+		case "FREQ", "BYMINUTE", "BYHOUR", "BYDAY", "BYMONTHDAY", "BYMONTH", "WKST":
+		case "INTERVAL":
+			if v != "1" {
+				return nil, fmt.Errorf("Recurrence rule '%s' must have INTERVAL=1", s)
+			}
+		case "BYSECOND":
+			if v != "0" {
+				return nil, fmt.Errorf("Recurrence rule '%s' must have BYSECOND=0", s)
+			}
+		default:
+			return nil, fmt.Errorf("Recurrence rule '%s' has unsupported part %s", s, k)
+		}
+	}
+
+	defaultTo := func(key, value string, from int) {
+		if _, ok := parts[key]; !ok && freq >= from {
+			parts[key] = value
+		}
+	}
+	defaultTo("BYMINUTE", "0", 1)
+	defaultTo("BYHOUR", "0", 2)
+	_, hasDay := parts["BYDAY"]
+	_, hasMonthDay := parts["BYMONTHDAY"]
+	if freq == 3 && !hasDay {
+		return nil, fmt.Errorf("Recurrence rule '%s' must have BYDAY with FREQ=WEEKLY", s)
+	}
+	if !hasDay && !hasMonthDay {
+		defaultTo("BYMONTHDAY", "1", 4)
+		defaultTo("BYMONTH", "1", 5)
+	}
+
+	dow, err := rruleDayItem(parts["BYDAY"], s, freq == 4 || (freq == 5 && parts["BYMONTH"] != ""))
+	if err != nil {
+		return nil, err
+	}
+	return NewRule(
+		rruleItem(parts["BYMINUTE"]),
+		rruleItem(parts["BYHOUR"]),
+		rruleMonthDayItem(parts["BYMONTHDAY"]),
+		rruleItem(parts["BYMONTH"]),
+		dow,
+	)
+}
+
+// rruleItem converts a comma separated BY list into the form accepted by NewRule.
+func rruleItem(list string) string {
+	if list == "" {
+		return "*"
+	}
+	return strings.Replace(list, ",", "/", -1)
+}
+
+// rruleMonthDayItem is like rruleItem but converts a single negative day, which counts back from the end of the
+// month, into "L" or "L-N".
+func rruleMonthDayItem(list string) string {
+	if n, err := strconv.Atoi(list); err == nil && n < 0 {
+		if n == -1 {
+			return "L"
+		}
+		return "L-" + strconv.Itoa(-1-n)
+	}
+	return rruleItem(list)
+}
+
+// rruleDayItem converts a BYDAY list into a day of week item. An ordinal such as "2MO" is converted to "1#2" when
+// allowed and it is the only day given.
+func rruleDayItem(list, s string, ordinals bool) (string, error) {
+	if list == "" {
+		return "*", nil
+	}
+	days := strings.Split(list, ",")
+	out := make([]string, len(days))
+	for i, d := range days {
+		if len(d) < 2 {
+			return "", fmt.Errorf("Recurrence rule '%s' has invalid BYDAY '%s'", s, d)
+		}
+		day := -1
+		for j, n := range rruleDays {
+			if d[len(d)-2:] == n {
+				day = j
+			}
+		}
+		if day < 0 {
+			return "", fmt.Errorf("Recurrence rule '%s' has invalid BYDAY '%s'", s, d)
+		}
+		out[i] = strconv.Itoa(day)
+		if ordinal := d[:len(d)-2]; ordinal != "" {
+			if !ordinals || len(days) > 1 || (strings.HasPrefix(ordinal, "-") && ordinal != "-1") {
+				return "", fmt.Errorf("Recurrence rule '%s' has unsupported BYDAY '%s'", s, d)
+			}
+			if ordinal == "-1" {
+				out[i] += "L"
+			} else {
+				out[i] += "#" + strings.TrimPrefix(ordinal, "+")
+			}
+		}
+	}
+	return strings.Join(out, "/"), nil
+}
+
+// RRule renders the rule as an iCalendar recurrence rule, for example "30 9 * * 1/3/5" becomes
+// "FREQ=DAILY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30". The frequency is the finest unrestricted field, so that every
+// other field can be given as a BY part.
+//
+// Recurrence rules have no time zone of their own, so the location is dropped along with the calendar and other
+// restrictions. The time zone of the start date the rule is attached to is used instead. An error is returned for
+// rules that fire when either the day of month or the day of week matches, such as those from ParseKubernetes,
+// since the BY parts of a recurrence rule must all match.
+func (r *Rule) RRule() (string, error) {
+	if r.eitherDay && r.restrictsBothDays() {
+		return "", fmt.Errorf("Rule '%s' fires when either day field matches, which a recurrence rule cannot express", r)
+	}
+	freq := "DAILY"
+	if len(r.Minutes()) == 0 {
+		freq = "MINUTELY"
+	} else if len(r.Hours()) == 0 {
+		freq = "HOURLY"
+	}
+	minutes, hours := r.Minutes(), r.Hours()
+
+	var byDay string
+	if days := r.DaysOfWeek(); len(days) > 0 {
+		names := make([]string, len(days))
+		for i, d := range days {
+			names[i] = rruleDays[d]
+		}
+		byDay = strings.Join(names, ",")
+	}
+	if r.hasOccurrence() {
+		// ordinals are only valid in monthly rules, which take the time of day from the start date unless given
+		freq = "MONTHLY"
+		if r.dayOfWeekLast {
+			byDay = "-1" + byDay
+		} else {
+			byDay = strconv.Itoa(r.dayOfWeekNth) + byDay
+		}
+		minutes = FieldValues(expandField(minutes, minuteField.min, minuteField.max))
+		hours = FieldValues(expandField(hours, hourField.min, hourField.max))
+	}
+
+	out := []string{"FREQ=" + freq}
+	add := func(key string, values FieldValues) {
+		if len(values) > 0 {
+			out = append(out, key+"="+values.String())
+		}
+	}
+	add("BYMONTH", r.Months())
+	add("BYMONTHDAY", r.DaysOfMonth())
+	if r.dayOfMonthLast {
+		// negative days count back from the end of the month
+		out = append(out, "BYMONTHDAY="+strconv.Itoa(-1-r.dayOfMonthOffset))
+	}
+	if byDay != "" {
+		out = append(out, "BYDAY="+byDay)
+	}
+	add("BYHOUR", hours)
+	add("BYMINUTE", minutes)
+	return strings.Join(out, ";"), nil
+}
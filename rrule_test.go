@@ -0,0 +1,97 @@
+package ticktickrules
+
+import (
+	"testing"
+)
+
+func TestParseRRule(t *testing.T) {
+	cases := map[string]string{
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30": "30 9 * * 1,3,5",
+		"RRULE:FREQ=DAILY;BYHOUR=8,17":                    "0 8,17 * * *",
+		"FREQ=DAILY":                                      "0 0 * * *",
+		"FREQ=HOURLY;BYMINUTE=15,45":                      "15,45 * * * *",
+		"FREQ=MINUTELY;BYHOUR=9;INTERVAL=1;BYSECOND=0":    "* 9 * * *",
+		"FREQ=MONTHLY":                                    "0 0 1 * *",
+		"FREQ=MONTHLY;BYMONTHDAY=1,15;BYHOUR=12":          "0 12 1,15 * *",
+		"FREQ=MONTHLY;BYDAY=2TU":                          "0 0 * * 2#2",
+		"FREQ=MONTHLY;BYDAY=-1FR":                         "0 0 * * 5L",
+		"FREQ=MONTHLY;BYMONTHDAY=-3":                      "0 0 L-2 * *",
+		"FREQ=YEARLY":                                     "0 0 1 1 *",
+		"FREQ=YEARLY;BYMONTH=12;BYMONTHDAY=25":            "0 0 25 12 *",
+		"FREQ=YEARLY;BYMONTH=5;BYDAY=-1MO":                "0 0 * 5 1L",
+		"FREQ=YEARLY;BYMONTH=5;BYDAY=-2MO":                "",
+		"FREQ=DAILY;BYDAY=1MO":                            "",
+		"FREQ=MONTHLY;BYDAY=1MO,1FR":                      "",
+		"FREQ=WEEKLY":                                     "",
+		"FREQ=DAILY;INTERVAL=2":                           "",
+		"FREQ=DAILY;COUNT=10":                             "",
+		"FREQ=SECONDLY":                                   "",
+		"FREQ=DAILY;BYDAY=XX":                             "",
+		"FREQ=DAILY;BYHOUR=24":                            "",
+		"BYHOUR":                                          "",
+	}
+	for expr, e := range cases {
+		r, err := ParseRRule(expr)
+		if e == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error but got '%s'", expr, r.StringNormalized())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+		} else if s := r.StringNormalized(); s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+	}
+}
+
+func TestRRule(t *testing.T) {
+	cases := map[string]string{
+		"30 9 * * 1/3/5":        "FREQ=DAILY;BYDAY=MO,WE,FR;BYHOUR=9;BYMINUTE=30",
+		"* * * * *":             "FREQ=MINUTELY",
+		"*/30 * * * *":          "FREQ=HOURLY;BYMINUTE=0,30",
+		"0 0 1/15 JAN *":        "FREQ=DAILY;BYMONTH=1;BYMONTHDAY=1,15;BYHOUR=0;BYMINUTE=0",
+		"0 12 * * 2#2":          "FREQ=MONTHLY;BYDAY=2TU;BYHOUR=12;BYMINUTE=0",
+		"0 12 * * 5L":           "FREQ=MONTHLY;BYDAY=-1FR;BYHOUR=12;BYMINUTE=0",
+		"0 12 L * *":            "FREQ=DAILY;BYMONTHDAY=-1;BYHOUR=12;BYMINUTE=0",
+		"* 12 * * 0#1":          "",
+		"0 0 * * 0/6":           "FREQ=DAILY;BYDAY=SU,SA;BYHOUR=0;BYMINUTE=0",
+		"CRON_TZ=UTC 5 * * * *": "FREQ=HOURLY;BYMINUTE=5",
+	}
+	for expr, e := range cases {
+		r := MustParseRule(expr)
+		s, err := r.RRule()
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+		if e != "" && s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+		back, err := ParseRRule(s)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+		} else if back.StringNormalized() != r.In(nil).StringNormalized() {
+			t.Errorf("%s: '%s' Did not match! '%s'", s, back.StringNormalized(), r.StringNormalized())
+		}
+	}
+
+	// Kubernetes fires when either day matches, which BY parts cannot express
+	k, err := ParseKubernetes("0 0 13 * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := k.RRule(); err == nil {
+		t.Errorf("expected an error, got '%s'", s)
+	}
+	k, err = ParseKubernetes("0 0 * * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := k.RRule(); err != nil {
+		t.Error(err)
+	} else if s != "FREQ=DAILY;BYDAY=FR;BYHOUR=0;BYMINUTE=0" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
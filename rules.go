@@ -7,104 +7,307 @@ package ticktickrules
 
 import (
 	"fmt"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Rule is a structure encoding a Cron-like rule
 type Rule struct {
-	minute         []int
-	minuteRule     string
-	hour           []int
-	hourRule       string
-	dayOfWeek      []int
-	dayOfWeekRule  string
-	dayOfMonth     []int
-	dayOfMonthRule string
-	month          []int
-	monthRule      string
+	minute           []int
+	minuteRule       string
+	hour             []int
+	hourRule         string
+	dayOfWeek        []int
+	dayOfWeekNth     int
+	dayOfWeekLast    bool
+	dayOfWeekRule    string
+	dayOfMonth       []int
+	dayOfMonthLast   bool
+	dayOfMonthOffset int
+	dayOfMonthRule   string
+	month            []int
+	monthRule        string
+	location         *time.Location
+	fallBack         FallBackPolicy
+	springForward    SpringForwardPolicy
+	calendar         Calendar
+	isoWeeks         []int
+	weeksOfMonth     []int
+	businessDay      int
+	timeOfDay        TimeOfDay
+	timeOfDayName    string
+	// eitherDay matches days where either the day of month or the day of week matches when both are restricted,
+	// as vixie cron does
+	eitherDay bool
+	// granularity is the resolution of the occurrences
+	granularity Granularity
+	// searchHorizon limits how far NextAfter and Floor look for an occurrence, or 0 for DefaultSearchHorizon
+	searchHorizon time.Duration
+	// masks hold the matched values of each field as bits, so that matching does not need to scan the slices
+	masks fieldMasks
 }
 
-// rule to support */10 */0 */1
-var ruleType1 = regexp.MustCompile(`^\*/\d+$`)
+// fieldMasks holds a bit for each value matched by a field. A mask of 0 matches any value.
+type fieldMasks struct {
+	minute     uint64
+	hour       uint64
+	dayOfMonth uint64
+	month      uint64
+	dayOfWeek  uint64
+}
+
+// buildMasks fills in the masks from the expanded fields. It must be called whenever the fields are replaced.
+func (r *Rule) buildMasks() {
+	r.masks = fieldMasks{
+		minute:     bitmask(r.minute),
+		hour:       bitmask(r.hour),
+		dayOfMonth: bitmask(r.dayOfMonth),
+		month:      bitmask(r.month),
+		dayOfWeek:  bitmask(r.dayOfWeek),
+	}
+}
+
+// hasBit returns whether v is set in the mask, or whether the mask is 0 and so matches any value.
+func hasBit(mask uint64, v int) bool {
+	return mask == 0 || mask&(1<<uint(v)) != 0
+}
+
+// isNumber returns whether s is an optionally negative whole number, such as "10" or "-1".
+func isNumber(s string) bool {
+	if len(s) > 0 && s[0] == '-' {
+		s = s[1:]
+	}
+	if len(s) == 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumberList returns whether s is two or more numbers separated by "/", such as "0/10/20".
+func isNumberList(s string) bool {
+	if strings.IndexByte(s, '/') < 0 {
+		return false
+	}
+	for {
+		i := strings.IndexByte(s, '/')
+		if i < 0 {
+			return isNumber(s)
+		}
+		if !isNumber(s[:i]) {
+			return false
+		}
+		s = s[i+1:]
+	}
+}
+
+// field describes the values allowed in one of the parts of a rule.
+type field struct {
+	name   string
+	min    int
+	max    int
+	names  []string
+	offset int
+	// alias is a value above max that is also accepted, such as 7 for Sunday
+	alias int
+	// question accepts "?" as a synonym for "*", as Quartz uses it to mark whichever of the day fields is unused
+	question bool
+}
+
+var (
+	minuteField     = field{name: "Minute", min: 0, max: 59}
+	hourField       = field{name: "Hour", min: 0, max: 23}
+	dayOfMonthField = field{name: "Day of Month", min: 1, max: 31, question: true}
+	monthField      = field{name: "Month", min: 1, max: 12, names: monthNames, offset: 1}
+	dayOfWeekField  = field{name: "Day of Week", min: 0, max: 6, names: dayOfWeekNames, alias: 7, question: true}
+)
+
+// replaceNames substitutes any names in the rule item with their index plus offset.
+func replaceNames(r string, names []string, offset int) string {
+	if names == nil || strings.IndexFunc(r, unicode.IsLetter) < 0 {
+		return r
+	}
+	parts := strings.Split(r, "/")
+	for i, p := range parts {
+		for j, n := range names {
+			if p == n {
+				parts[i] = strconv.Itoa(j + offset)
+				break
+			}
+		}
+	}
+	return strings.Join(parts, "/")
+}
 
-// rule to support 0/10/20
-var ruleType2 = regexp.MustCompile(`^\d+(?:/\d+)+$`)
+// maxValueDigits is the longest number that is parsed before being rejected as out of range, which keeps the
+// conversion well clear of overflow.
+const maxValueDigits = 9
+
+// parseValue parses a single number from a rule item and checks that it is between min and max.
+func parseValue(s, item string, f field, min, max int) (int, error) {
+	digits := strings.TrimPrefix(s, "-")
+	if len(digits) > maxValueDigits {
+		return 0, &RangeError{Field: f.name, Item: item, Value: s, Min: min, Max: max}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &SyntaxError{Item: item, Reason: "could not be parsed"}
+	}
+	if v < min || v > max {
+		return 0, &RangeError{Field: f.name, Item: item, Value: s, Min: min, Max: max}
+	}
+	return v, nil
+}
+
+func parseRuleItem(original string, f field) ([]int, error) {
+	r := replaceNames(original, f.names, f.offset)
+	upper := f.max
+	if f.alias > upper {
+		upper = f.alias
+	}
 
-func parseRuleItem(r string, maxsum int) ([]int, error) {
 	var out []int
-	if r == "*" {
+	if r == "*" || (r == "?" && f.question) {
 		// noop
-	} else if ruleType1.MatchString(r) {
+	} else if strings.HasPrefix(r, "*/") && isNumber(r[2:]) {
 
-		i := strings.Split(r, "/")[1]
-		v, err := strconv.Atoi(i)
+		i := r[2:]
+		if i == "0" {
+			return nil, &SyntaxError{Item: original, Reason: "cannot be 0"}
+		}
+		v, err := parseValue(i, original, f, 1, f.max-f.min)
 		if err != nil {
-			return nil, fmt.Errorf("Rule item '%s' could not be parsed", r)
+			return nil, err
 		}
 
-		if v == 0 {
-			return nil, fmt.Errorf("Rule item '%s' cannot be 0", r)
+		// steps start from the lowest value of the field, so "*/3" in months is 1, 4, 7, 10
+		for sum := f.min; sum <= f.max; sum += v {
+			out = append(out, sum)
 		}
 
-		if v >= maxsum {
-			return nil, fmt.Errorf("Rule item '%s' does not divide", r)
-		}
+	} else if isNumberList(r) {
 
-		var sum int
-		for {
-			out = append(out, sum)
-			sum += v
-			if sum >= maxsum {
-				break
+		// lists may be given in any order and with repeats, they are sorted by NewRule
+		for rest := r; rest != ""; {
+			p := rest
+			if i := strings.IndexByte(rest, '/'); i >= 0 {
+				p, rest = rest[:i], rest[i+1:]
+			} else {
+				rest = ""
+			}
+			v, err := parseValue(p, original, f, f.min, upper)
+			if err != nil {
+				return nil, err
 			}
+			out = append(out, v)
 		}
 
-	} else if ruleType2.MatchString(r) {
+	} else if strings.ContainsAny(r, ",-") {
 
-		parts := strings.Split(r, "/")
-		lst := 0
-		for _, p := range parts {
-			v, err := strconv.Atoi(p)
+		// lists, ranges and stepped ranges such as "0,15-30/5,45"
+		for _, part := range strings.Split(original, ",") {
+			t, err := parseTerm(part, original, f)
 			if err != nil {
-				return nil, fmt.Errorf("Rule item '%s' could not be parsed", r)
-			} else if v < 0 {
-				return nil, fmt.Errorf("Rule item '%s' cannot have negative value", r)
+				return nil, err
 			}
-
-			if len(out) == 0 {
-				out = append(out, v)
-			} else if v <= lst {
-				return nil, fmt.Errorf("Rule item '%s' has bad ordering", r)
-			} else {
-				out = append(out, v)
-				lst = v
+			if out, err = expandTerm(t, original, f, upper, out); err != nil {
+				return nil, err
 			}
 		}
 
-	} else {
+	} else if isNumber(r) {
 
-		v, err := strconv.Atoi(r)
+		v, err := parseValue(r, original, f, f.min, upper)
 		if err != nil {
-			return nil, fmt.Errorf("Rule item '%s' is not supported", r)
+			return nil, err
 		}
 		out = append(out, v)
 
+	} else {
+		return nil, &SyntaxError{Item: original, Reason: "is not supported"}
 	}
 	return out, nil
 }
 
-func validateItemsRange(items []int, min int, max int) error {
-	for _, i := range items {
-		if i > max {
-			return fmt.Errorf("%d is > %d", i, max)
-		} else if i < min {
-			return fmt.Errorf("%d is < %d", i, min)
+// expandTerm appends the values matched by t to out, checking that they are within the range of the field.
+func expandTerm(t Term, item string, f field, upper int, out []int) ([]int, error) {
+	check := func(v, min, max int) error {
+		if v < min || v > max {
+			return &RangeError{Field: f.name, Item: item, Value: strconv.Itoa(v), Min: min, Max: max}
 		}
+		return nil
 	}
-	return nil
+	switch t := t.(type) {
+	case WildcardTerm:
+		for v := f.min; v <= f.max; v++ {
+			out = append(out, v)
+		}
+	case ValueTerm:
+		if err := check(t.Value, f.min, upper); err != nil {
+			return nil, err
+		}
+		out = append(out, t.Value)
+	case RangeTerm:
+		if err := check(t.From, f.min, upper); err != nil {
+			return nil, err
+		}
+		if err := check(t.To, f.min, upper); err != nil {
+			return nil, err
+		}
+		if t.From > t.To {
+			return nil, &SyntaxError{Item: item, Reason: "has bad ordering"}
+		}
+		for v := t.From; v <= t.To; v++ {
+			out = append(out, v)
+		}
+	case StepTerm:
+		if t.Every == 0 {
+			return nil, &SyntaxError{Item: item, Reason: "cannot be 0"}
+		}
+		if err := check(t.Every, 1, f.max-f.min); err != nil {
+			return nil, err
+		}
+		base, err := expandTerm(t.Base, item, f, upper, nil)
+		if err != nil {
+			return nil, err
+		}
+		// steps count from the start of the base, so "10-30/15" is 10 and 25
+		for i := 0; i < len(base); i += t.Every {
+			out = append(out, base[i])
+		}
+	case ListTerm:
+		for _, lt := range t {
+			var err error
+			if out, err = expandTerm(lt, item, f, upper, out); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return out, nil
+}
+
+// sortValues sorts the values of a field and removes any repeats, in place. The expanded fields are always kept
+// sorted so that searches can stop at the first value past the one they are looking for.
+func sortValues(values []int) []int {
+	if len(values) < 2 {
+		return values
+	}
+	sort.Ints(values)
+	n := 1
+	for _, v := range values[1:] {
+		if v != values[n-1] {
+			values[n] = v
+			n++
+		}
+	}
+	return values[:n]
 }
 
 func doesMatch(v int, vs []int) bool {
@@ -116,92 +319,108 @@ func doesMatch(v int, vs []int) bool {
 	return false
 }
 
-const naiveMaxIterations = 31 * 8 * 12
-
-func roundUp(current int, items []int, ceiling int) int {
-	if len(items) == 0 {
-		r := current + 1
-		if r >= ceiling {
-			return 0
-		}
-		return r
-	}
-	for _, i := range items {
-		if i > current {
-			return i
-		}
-	}
-	return items[0]
-}
+// farFuture is returned when no next match can be found, and farPast when no previous match can be found.
+var (
+	farFuture = time.Unix(1<<62, 0)
+	farPast   = time.Unix(-1<<62, 0)
+)
 
 // NewRule constructs and validates a new Rule structure from the cron-like arguments provided.
 // Each rule string can be of the following forms:
-//     "*" - matches any value
-//     "*/N" - matches 0 and any multiple of N
-//     "N/M/O.." - matches N or M or O, etc.
 //
-//     field         allowed values
-//     -----         --------------
-//     minute        0-59
-//     hour          0-23
-//     day of month  1-31
-//     month         1-12
-//     day of week   0-7 (0	or 7 is	Sun)
+//	"*" - matches any value
+//	"?" - (day of month and day of week only) the same as "*"
+//	"*/N" - matches the lowest allowed value and every N-th value after it
+//	"N/M/O.." - matches N or M or O, etc. The values may be given in any order and repeats are ignored
+//	"N,M,O.." - also matches N or M or O, and may be mixed with the other forms, for example "0,15-30/5,45"
+//	"N-M" - matches the values from N to M inclusive
+//	"N-M/S" - matches N and every S-th value after it up to M
+//	"N#K" - (day of week only) matches the K-th day N of the month, for example "5#3" is the third Friday
+//	"NL" - (day of week only) matches the last day N of the month, for example "5L" is the last Friday
+//	"L" - (day of month only) matches the last day of the month
+//	"L-N" - (day of month only) matches N days before the last day of the month, for example "L-3" is the 28th
+//	of a 31 day month and the 25th of February in a common year
+//
+// Months and days of the week may also be given by their upper case three letter names, such as "JAN" or "MON".
+//
+//	field         allowed values
+//	-----         --------------
+//	minute        0-59
+//	hour          0-23
+//	day of month  1-31
+//	month         1-12
+//	day of week   0-7 (0	or 7 is	Sun)
 //
 // An error will be returned if one of the rules is invalid.
 func NewRule(minute, hour, dayOfMonth, month, dayOfWeek string) (*Rule, error) {
 	output := new(Rule)
 
-	m, err := parseRuleItem(minute, 60)
+	m, err := parseRuleItem(minute, minuteField)
 	if err != nil {
 		return nil, err
 	}
-	output.minute = m
-	if err := validateItemsRange(output.minute, 0, 59); err != nil {
-		return nil, fmt.Errorf("Minute rule invalid: %s", err.Error())
-	}
+	output.minute = sortValues(m)
 	output.minuteRule = minute
 
-	h, err := parseRuleItem(hour, 24)
+	h, err := parseRuleItem(hour, hourField)
 	if err != nil {
 		return nil, err
 	}
-	output.hour = h
-	if err := validateItemsRange(output.hour, 0, 23); err != nil {
-		return nil, fmt.Errorf("Hour rule invalid: %s", err.Error())
-	}
+	output.hour = sortValues(h)
 	output.hourRule = hour
 
-	dow, err := parseRuleItem(dayOfWeek, 7)
+	dowItem := dayOfWeek
+	if i := strings.Index(dayOfWeek, "#"); i >= 0 {
+		nth, err := strconv.Atoi(dayOfWeek[i+1:])
+		if err != nil || nth < 1 || nth > 5 {
+			return nil, &SyntaxError{Item: dayOfWeek, Reason: "must have an occurrence between 1 and 5"}
+		}
+		output.dayOfWeekNth = nth
+		dowItem = dayOfWeek[:i]
+	} else if len(dayOfWeek) > 1 && strings.HasSuffix(dayOfWeek, "L") {
+		output.dayOfWeekLast = true
+		dowItem = dayOfWeek[:len(dayOfWeek)-1]
+	}
+	dow, err := parseRuleItem(dowItem, dayOfWeekField)
 	if err != nil {
 		return nil, err
 	}
-	output.dayOfWeek = dow
-	if err := validateItemsRange(output.dayOfWeek, 0, 7); err != nil {
-		return nil, fmt.Errorf("Day of Week rule invalid: %s", err.Error())
+	if output.hasOccurrence() && len(dow) != 1 {
+		return nil, &SyntaxError{Item: dayOfWeek, Reason: "must have a single day of week"}
+	}
+	// 7 is also accepted for Sunday
+	for i, v := range dow {
+		if v == 7 {
+			dow[i] = 0
+		}
 	}
+	output.dayOfWeek = sortValues(dow)
 	output.dayOfWeekRule = dayOfWeek
 
-	dom, err := parseRuleItem(dayOfMonth, 31)
-	if err != nil {
-		return nil, err
-	}
-	output.dayOfMonth = dom
-	if err := validateItemsRange(output.dayOfMonth, 1, 31); err != nil {
-		return nil, fmt.Errorf("Day of Month rule invalid: %s", err.Error())
+	if dayOfMonth == "L" || strings.HasPrefix(dayOfMonth, "L-") {
+		output.dayOfMonthLast = true
+		if dayOfMonth != "L" {
+			if output.dayOfMonthOffset, err = parseValue(dayOfMonth[2:], dayOfMonth, dayOfMonthField, 0, 30); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		dom, err := parseRuleItem(dayOfMonth, dayOfMonthField)
+		if err != nil {
+			return nil, err
+		}
+		output.dayOfMonth = sortValues(dom)
 	}
 	output.dayOfMonthRule = dayOfMonth
 
-	m, err = parseRuleItem(month, 24)
+	m, err = parseRuleItem(month, monthField)
 	if err != nil {
 		return nil, err
 	}
-	output.month = m
-	if err := validateItemsRange(output.month, 1, 12); err != nil {
-		return nil, fmt.Errorf("Month rule invalid: %s", err.Error())
-	}
+	output.month = sortValues(m)
 	output.monthRule = month
 
+	output.buildMasks()
 	return output, nil
 }
 
@@ -214,60 +433,88 @@ func MustNewRule(minute, hour, dayOfMonth, month, dayOfWeek string) *Rule {
 	return r
 }
 
+// Location returns the location the rule is bound to, or nil if the rule is evaluated in the location of the
+// times given to it.
+func (r *Rule) Location() *time.Location {
+	return r.location
+}
+
+// localize converts t into the location the rule is bound to, if any.
+func (r *Rule) localize(t time.Time) time.Time {
+	if r.location != nil {
+		return t.In(r.location)
+	}
+	return t
+}
+
 // String converts the rule back to its native 5-part cron expression.
 func (r *Rule) String() string {
-	return fmt.Sprintf("%s %s %s %s %s", r.minuteRule, r.hourRule, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule)
+	clock := r.minuteRule + " " + r.hourRule
+	if r.timeOfDay != nil {
+		clock = "@" + r.timeOfDayName
+	}
+	if r.location != nil {
+		return fmt.Sprintf("CRON_TZ=%s %s %s %s %s", r.location, clock, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule)
+	}
+	return fmt.Sprintf("%s %s %s %s", clock, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule)
 }
 
 // NextUTC returns the next UTC time this rule is true.
 func (r *Rule) NextUTC() time.Time {
-	return r.NextAfter(time.Now().UTC())
+	return r.NextUTCWith(SystemClock)
 }
 
-// NextAfter returns the next time this rule will match after the given time.
-func (r *Rule) NextAfter(from time.Time) time.Time {
-	originalFrom := from
-	originalMinute := from.Minute()
-	originalHour := from.Hour()
+// NextUTCWith is like NextUTC but reads the current time from the given clock.
+func (r *Rule) NextUTCWith(c Clock) time.Time {
+	return r.NextAfter(c.Now().UTC()).UTC()
+}
 
-	// first attempt to match in the current hour
-	nextMinute := roundUp(originalMinute, r.minute, 60)
-	from = time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), nextMinute, 0, 0, from.Location())
-	if from.After(originalFrom) {
-		// double check (this shouldn't be needed)
-		if r.Matches(from) {
-			return from
+// NextAfter returns the next time this rule will match after the given time. If there is no match within the
+// search horizon of the rule then a time far in the future is returned, use Next to get an error instead.
+func (r *Rule) NextAfter(from time.Time) time.Time {
+	from = r.localize(from)
+	if r.granularity == SecondGranularity {
+		if next := r.truncate(from).Add(time.Second); next.Second() != 0 && r.Matches(next) {
+			return next
 		}
 	}
-
-	// either in the future but not matched, or in the past
-	nextHour := roundUp(originalHour, r.hour, 24)
-	from = time.Date(from.Year(), from.Month(), from.Day(), nextHour, from.Minute(), 0, 0, from.Location())
-
-	if from.After(originalFrom) {
-		if r.Matches(from) {
-			return from
+	loc := from.Location()
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+	year, month, day := from.Date()
+
+	// iterate in days until we hit a day that matches
+	date := civilDate(year, month, day)
+	end := date.Add(r.SearchHorizon())
+	for numIterations := 0; !date.After(end); numIterations, date = numIterations+1, r.nextDay(date) {
+		if !r.matchesDay(date) {
+			continue
+		}
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
 		}
-	}
-
-	// otherwise truncate to the first minute and hour and into the next day
-	nextMinute = roundUp(59, r.minute, 60)
-	nextHour = roundUp(23, r.hour, 24)
-	from = from.Add(24 * time.Hour)
-	from = time.Date(from.Year(), from.Month(), from.Day(), nextHour, nextMinute, 0, 0, from.Location())
 
-	// now iterate in days until we hit a day that matches
-	numIterations := 0
-	for {
-		if r.Matches(from) {
-			return from.Truncate(time.Minute)
+		// days on which the clocks change are resolved one occurrence at a time
+		if hasTransition(date, loc) {
+			for _, t := range r.dayInstants(date, loc, hours, minutes) {
+				if t.After(from) {
+					return t
+				}
+			}
+			continue
 		}
-		from = from.Add(24 * time.Hour)
-		numIterations++
-		if numIterations > naiveMaxIterations {
-			return time.Unix(1<<62, 0)
+
+		// later days start from their first hour and minute, the first day carries on from the current time
+		h, m := hours[0], minutes[0]
+		if numIterations == 0 {
+			if h, m, ok = nextClock(hours, minutes, from.Hour(), from.Minute()); !ok {
+				continue
+			}
 		}
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, loc)
 	}
+	return farFuture
 }
 
 // UntilNext returns the duration until the next match.
@@ -278,37 +525,457 @@ func (r *Rule) UntilNext(from time.Time) time.Duration {
 
 // UntilNextUTC returns the duration until the next match from the current UTC time.
 func (r *Rule) UntilNextUTC() time.Duration {
-	now := time.Now().UTC()
+	return r.UntilNextWith(SystemClock)
+}
+
+// UntilNextWith returns the duration until the next match from the current time of the given clock.
+func (r *Rule) UntilNextWith(c Clock) time.Duration {
+	now := c.Now().UTC()
 	next := r.NextAfter(now)
 	return next.Sub(now)
 }
 
-// Matches returns whether the given time is matched by the rule.
-func (r *Rule) Matches(t time.Time) bool {
-	if len(r.month) > 0 {
-		if !doesMatch(int(t.Month()), r.month) {
-			return false
+// Ceil returns the first time at or after t that this rule matches.
+func (r *Rule) Ceil(t time.Time) time.Time {
+	t = r.localize(t)
+	if truncated := r.truncate(t); truncated.Equal(t) && r.Matches(t) {
+		return truncated
+	}
+	return r.NextAfter(t)
+}
+
+// Floor returns the most recent time at or before t that this rule matches.
+func (r *Rule) Floor(t time.Time) time.Time {
+	t = r.localize(t)
+	if r.granularity == SecondGranularity {
+		if r.Matches(t) {
+			return r.truncate(t)
 		}
+		// the latest second of the previous matching minute
+		if f := r.minuteFloor(t); !f.Equal(farPast) {
+			return f.Add(59 * time.Second)
+		}
+		return farPast
 	}
-	if len(r.dayOfWeek) > 0 {
-		if !doesMatch(int(t.Weekday()), r.dayOfWeek) {
-			return false
+	return r.minuteFloor(t)
+}
+
+// minuteFloor returns the start of the most recent matching minute starting at or before t, which must already be
+// in the location of the rule.
+func (r *Rule) minuteFloor(t time.Time) time.Time {
+	loc := t.Location()
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+	year, month, day := t.Date()
+
+	// walk backwards in days until we hit a day with a match at or before t
+	date := civilDate(year, month, day)
+	end := date.Add(-r.SearchHorizon())
+	for numIterations := 0; !date.Before(end); numIterations, date = numIterations+1, r.previousDay(date) {
+		if !r.matchesDay(date) {
+			continue
+		}
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
+		}
+
+		if hasTransition(date, loc) {
+			instants := r.dayInstants(date, loc, hours, minutes)
+			for i := len(instants) - 1; i >= 0; i-- {
+				if !instants[i].After(t) {
+					return instants[i]
+				}
+			}
+			continue
+		}
+
+		h, m := hours[len(hours)-1], minutes[len(minutes)-1]
+		if numIterations == 0 {
+			if h, m, ok = previousClock(hours, minutes, t.Hour(), t.Minute()); !ok {
+				continue
+			}
 		}
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, loc)
 	}
-	if len(r.dayOfMonth) > 0 {
-		if !doesMatch(t.Day(), r.dayOfMonth) {
-			return false
+	return farPast
+}
+
+// nextClock returns the first hour and minute strictly after h:m in the sorted hours and minutes. The minute is
+// tried first and carries into the next hour when it runs out, which resets the minute to its first value. False
+// is returned when the hours run out too and the search must carry into the next day.
+func nextClock(hours, minutes []int, h, m int) (int, int, bool) {
+	if i := sort.SearchInts(hours, h); i < len(hours) && hours[i] == h {
+		if j := sort.SearchInts(minutes, m+1); j < len(minutes) {
+			return h, minutes[j], true
+		}
+	}
+	if i := sort.SearchInts(hours, h+1); i < len(hours) {
+		return hours[i], minutes[0], true
+	}
+	return 0, 0, false
+}
+
+// previousClock is like nextClock but returns the last hour and minute at or before h:m, borrowing from the
+// previous hour when the minutes run out.
+func previousClock(hours, minutes []int, h, m int) (int, int, bool) {
+	if i := sort.SearchInts(hours, h); i < len(hours) && hours[i] == h {
+		if j := sort.SearchInts(minutes, m+1) - 1; j >= 0 {
+			return h, minutes[j], true
+		}
+	}
+	if i := sort.SearchInts(hours, h) - 1; i >= 0 {
+		return hours[i], minutes[len(minutes)-1], true
+	}
+	return 0, 0, false
+}
+
+// nextDay returns the first candidate day after date. The day carries to the next value of the day of month field,
+// and into the next month when those run out, and whole months the rule does not match are skipped so that rules
+// such as "0 0 29 2 *" reach the next leap year in a few iterations. The year carries along with the month. The
+// result is only a candidate, it must still be checked with matchesDay.
+func (r *Rule) nextDay(date time.Time) time.Time {
+	next := civilDate(date.Year(), date.Month(), date.Day()+1)
+	days := r.carryDays()
+	for i := 0; i < 24; i++ {
+		if !hasBit(r.masks.month, int(next.Month())) {
+			next = civilDate(next.Year(), next.Month()+1, 1)
+			continue
 		}
+		if days != nil {
+			j := sort.SearchInts(days, next.Day())
+			if j == len(days) || days[j] > daysIn(next) {
+				next = civilDate(next.Year(), next.Month()+1, 1)
+				continue
+			}
+			next = civilDate(next.Year(), next.Month(), days[j])
+		}
+		break
+	}
+	return next
+}
+
+// previousDay is like nextDay but returns the candidate day before date, borrowing from the end of the previous
+// month.
+func (r *Rule) previousDay(date time.Time) time.Time {
+	prev := civilDate(date.Year(), date.Month(), date.Day()-1)
+	days := r.carryDays()
+	for i := 0; i < 24; i++ {
+		if !hasBit(r.masks.month, int(prev.Month())) {
+			prev = civilDate(prev.Year(), prev.Month(), 0)
+			continue
+		}
+		if days != nil {
+			j := sort.SearchInts(days, prev.Day()+1) - 1
+			if j < 0 {
+				prev = civilDate(prev.Year(), prev.Month(), 0)
+				continue
+			}
+			prev = civilDate(prev.Year(), prev.Month(), days[j])
+		}
+		break
+	}
+	return prev
+}
+
+// carryDays returns the days of month that every match must fall on, or nil if days outside of the day of month
+// field can match, as they can for "L" or when either day field may match on its own.
+func (r *Rule) carryDays() []int {
+	if len(r.dayOfMonth) == 0 || r.dayOfMonthLast {
+		return nil
+	}
+	if r.eitherDay && (r.masks.dayOfWeek != 0 || r.hasOccurrence()) {
+		return nil
+	}
+	return r.dayOfMonth
+}
+
+// daysIn returns the number of days in the month of date.
+func daysIn(date time.Time) int {
+	return civilDate(date.Year(), date.Month()+1, 0).Day()
+}
+
+// NextNonMatch returns the first whole minute after from that the rule does not match. For wide rules used as
+// allowed windows, this is when the current window closes. If the rule matches every minute for longer than its
+// search horizon then a time far in the future is returned.
+func (r *Rule) NextNonMatch(from time.Time) time.Time {
+	from = r.localize(from)
+	loc := from.Location()
+	t := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), from.Minute()+1, 0, 0, loc)
+
+	end := civilDate(from.Date()).Add(r.SearchHorizon())
+	for !civilDate(t.Date()).After(end) {
+		year, month, day := t.Date()
+		tomorrow := time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+		if !r.matchesDay(civilDate(year, month, day)) {
+			return t
+		}
+
+		// days matching every hour and minute can be skipped entirely
+		if len(r.hour) > 0 || len(r.minute) > 0 || r.timeOfDay != nil || hasTransition(civilDate(year, month, day), loc) {
+			for ; t.Before(tomorrow); t = t.Add(time.Minute) {
+				if !r.Matches(t) {
+					return t
+				}
+			}
+		}
+		t = tomorrow
 	}
-	if len(r.hour) > 0 {
-		if !doesMatch(t.Hour(), r.hour) {
+	return farFuture
+}
+
+// matchesDayOfMonth returns whether the day of month is matched, counting from the end of the month for "L-N".
+func (r *Rule) matchesDayOfMonth(m *moment) bool {
+	if r.dayOfMonthLast {
+		return m.day == time.Date(m.t.Year(), time.Month(m.month)+1, 0, 0, 0, 0, 0, time.UTC).Day()-r.dayOfMonthOffset
+	}
+	return hasBit(r.masks.dayOfMonth, m.day)
+}
+
+// hasOccurrence returns whether the day of week is restricted to an occurrence within the month, such as "5#3" or
+// "5L".
+func (r *Rule) hasOccurrence() bool {
+	return r.dayOfWeekNth > 0 || r.dayOfWeekLast
+}
+
+// matchesOccurrence returns whether the day is the occurrence of its weekday within the month required by the rule.
+func (r *Rule) matchesOccurrence(m *moment) bool {
+	if r.dayOfWeekLast {
+		return m.day+7 > time.Date(m.t.Year(), time.Month(m.month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	}
+	return r.dayOfWeekNth == 0 || (m.day-1)/7+1 == r.dayOfWeekNth
+}
+
+// matchesDay returns whether the month, day of month, and day of week of t are matched by the rule and the date
+// is not excluded by its calendar.
+func (r *Rule) matchesDay(t time.Time) bool {
+	m := newMoment(t)
+	return r.matchesDate(&m)
+}
+
+// matchesDate is like matchesDay but takes a time that has already been broken down.
+func (r *Rule) matchesDate(m *moment) bool {
+	if !hasBit(r.masks.month, m.month) {
+		return false
+	}
+	dow := hasBit(r.masks.dayOfWeek, m.weekday) && r.matchesOccurrence(m)
+	dom := r.matchesDayOfMonth(m)
+	if r.eitherDay && (r.masks.dayOfMonth != 0 || r.dayOfMonthLast) && (r.masks.dayOfWeek != 0 || r.hasOccurrence()) {
+		if !dow && !dom {
 			return false
 		}
+	} else if !dow || !dom {
+		return false
 	}
-	if len(r.minute) > 0 {
-		if !doesMatch(t.Minute(), r.minute) {
+	if len(r.weeksOfMonth) > 0 && !doesMatch((m.day-1)/7+1, r.weeksOfMonth) {
+		return false
+	}
+	if len(r.isoWeeks) > 0 {
+		if _, week := m.t.ISOWeek(); !doesMatch(week, r.isoWeeks) {
 			return false
 		}
 	}
+	if r.calendar != nil && r.calendar.IsExcluded(m.t) {
+		return false
+	}
+	if r.businessDay != 0 && !r.matchesBusinessDay(m.t) {
+		return false
+	}
 	return true
 }
+
+// NthFrom returns the n-th occurrence of the rule after from, so NthFrom(from, 1) is the same as NextAfter(from).
+// Whole days are skipped at a time rather than stepping through each occurrence. If n is less than 1, from is
+// returned. A time far in the future is returned if the gap before any of the occurrences is longer than the
+// search horizon of the rule.
+func (r *Rule) NthFrom(from time.Time, n int) time.Time {
+	if n < 1 {
+		return from
+	}
+	from = r.localize(from)
+	loc := from.Location()
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+	first := civilDate(from.Date())
+
+	// each matching day allows the search to continue for another horizon, as NextAfter would from there
+	end := first.Add(r.SearchHorizon())
+	for date := first; !date.After(end); date = r.nextDay(date) {
+		if !r.matchesDay(date) {
+			continue
+		}
+		end = date.Add(r.SearchHorizon())
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
+		}
+
+		// the first day and days on which the clocks change are resolved one occurrence at a time
+		if date.Equal(first) || hasTransition(date, loc) {
+			for _, t := range r.dayInstants(date, loc, hours, minutes) {
+				if t.After(from) {
+					n--
+					if n == 0 {
+						return t
+					}
+				}
+			}
+			continue
+		}
+
+		// skip whole days while more than a day of occurrences remains
+		if perDay := len(hours) * len(minutes); n > perDay {
+			n -= perDay
+			continue
+		}
+		h, m := hours[(n-1)/len(minutes)], minutes[(n-1)%len(minutes)]
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, loc)
+	}
+	return farFuture
+}
+
+// CountBetween returns the number of occurrences of the rule at or after start and before end. Occurrences are
+// counted per matching day from the hour and minute fields rather than enumerated one by one.
+func (r *Rule) CountBetween(start, end time.Time) int {
+	start = r.localize(start)
+	end = end.In(start.Location())
+	if !end.After(start) {
+		return 0
+	}
+	loc := start.Location()
+	hours := expandField(r.hour, 0, 23)
+	minutes := expandField(r.minute, 0, 59)
+
+	count := 0
+	first := civilDate(start.Date())
+	last := civilDate(end.Date())
+	for date := first; !date.After(last); date = date.AddDate(0, 0, 1) {
+		if !r.matchesDay(date) {
+			continue
+		}
+		hours, minutes, ok := r.clockOn(date, hours, minutes)
+		if !ok {
+			continue
+		}
+
+		// partial days and days on which the clocks change are counted one occurrence at a time
+		if date.Equal(first) || date.Equal(last) || hasTransition(date, loc) {
+			for _, t := range r.dayInstants(date, loc, hours, minutes) {
+				if !t.Before(start) && t.Before(end) {
+					count++
+				}
+			}
+			continue
+		}
+		count += len(hours) * len(minutes)
+	}
+	return count
+}
+
+// expandField returns the sorted distinct values of a field, or every value from min to max if any is allowed.
+func expandField(values []int, min, max int) []int {
+	if out := normalizeField(values, min, max); out != nil {
+		return out
+	}
+	out := make([]int, 0, max-min+1)
+	for i := min; i <= max; i++ {
+		out = append(out, i)
+	}
+	return out
+}
+
+// MissedBetween returns the occurrences after lastRun and at or before now, earliest first. This is the list of
+// runs that should have fired while a process was down. At most limit times are returned; a limit of 0 or less
+// means no limit. Occurrences are whole minutes whatever the granularity of the rule.
+func (r *Rule) MissedBetween(lastRun, now time.Time, limit int) []time.Time {
+	if r.granularity != MinuteGranularity {
+		r = r.WithGranularity(MinuteGranularity)
+	}
+	var out []time.Time
+	next := r.NextAfter(lastRun)
+	for !next.After(now) {
+		if limit > 0 && len(out) >= limit {
+			break
+		}
+		out = append(out, next)
+		next = r.NextAfter(next)
+	}
+	return out
+}
+
+// Overlaps returns the first minute within the given window from the current UTC time at which both rules
+// fire, and whether such a minute was found.
+func Overlaps(a, b *Rule, window time.Duration) (time.Time, bool) {
+	return OverlapsAfter(a, b, time.Now().UTC(), window)
+}
+
+// OverlapsAfter returns the first minute after from, and no later than from + window, at which both rules fire,
+// and whether such a minute was found.
+func OverlapsAfter(a, b *Rule, from time.Time, window time.Duration) (time.Time, bool) {
+	end := from.Add(window)
+	x := a.NextAfter(from)
+	y := b.NextAfter(from)
+	for !x.After(end) && !y.After(end) {
+		if x.Equal(y) {
+			return x, true
+		} else if x.Before(y) {
+			x = a.Ceil(y)
+		} else {
+			y = b.Ceil(x)
+		}
+	}
+	return time.Time{}, false
+}
+
+// Matches returns whether the given time is matched by the rule. Any time within a matching minute is matched,
+// so callers polling more than once a minute should use MatchesInstant instead.
+func (r *Rule) Matches(t time.Time) bool {
+	t = r.localize(t)
+	m := newMoment(t)
+	return r.matchesMoment(&m, isSecondPass(t))
+}
+
+// MatchesMinute returns whether the minute containing t is matched by the rule. It is the same as Matches.
+func (r *Rule) MatchesMinute(t time.Time) bool {
+	return r.Matches(t)
+}
+
+// MatchesInstant returns whether t is exactly an occurrence of the rule, which is the start of a matching minute,
+// or any whole second of one for rules with SecondGranularity. A caller polling every second sees this return
+// true once per occurrence, where Matches would be true 60 times.
+func (r *Rule) MatchesInstant(t time.Time) bool {
+	t = r.localize(t)
+	return r.truncate(t).Equal(t) && r.Matches(t)
+}
+
+// matchesMoment is like Matches but takes a time that has already been broken down in the location of the rule,
+// along with whether its wall clock time already occurred once before.
+func (r *Rule) matchesMoment(m *moment, secondPass bool) bool {
+	if !r.firesTwice() && secondPass {
+		return false
+	}
+	if r.matchesClock(m) && r.matchesDate(m) {
+		return true
+	}
+	return r.springForward == ShiftMissing && r.matchesGap(m.t)
+}
+
+// matchesWallClock returns whether the date, hour, and minute shown by t are matched by the rule.
+func (r *Rule) matchesWallClock(t time.Time) bool {
+	m := newMoment(t)
+	return r.matchesClock(&m) && r.matchesDate(&m)
+}
+
+// moment is a time broken down into the parts that rules match against.
+type moment struct {
+	t            time.Time
+	month, day   int
+	weekday      int
+	hour, minute int
+}
+
+func newMoment(t time.Time) moment {
+	_, month, day := t.Date()
+	hour, minute, _ := t.Clock()
+	return moment{t: t, month: int(month), day: day, weekday: int(t.Weekday()), hour: hour, minute: minute}
+}
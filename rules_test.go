@@ -1,6 +1,7 @@
 package ticktickrules
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -142,31 +143,31 @@ func TestNaiveMultiple(t *testing.T) {
 	start := time.Date(2000, 1, 1, 1, 0, 1, 0, time.UTC)
 	r, _ := NewRule("*/25", "*/2", "*", "*", "*")
 	n1 := r.NextAfter(start)
-	e1 := time.Date(2000, 1, 1, 2, 25, 0, 0, time.UTC)
+	e1 := time.Date(2000, 1, 1, 2, 0, 0, 0, time.UTC)
 	if n1 != e1 {
 		t.Errorf("n1 %s != %s", n1, e1)
 		return
 	}
 	n2 := r.NextAfter(n1)
-	e2 := time.Date(2000, 1, 1, 2, 50, 0, 0, time.UTC)
+	e2 := time.Date(2000, 1, 1, 2, 25, 0, 0, time.UTC)
 	if n2 != e2 {
 		t.Errorf("n2 %s != %s", n2, e2)
 		return
 	}
 	n3 := r.NextAfter(n2)
-	e3 := time.Date(2000, 1, 1, 4, 0, 0, 0, time.UTC)
+	e3 := time.Date(2000, 1, 1, 2, 50, 0, 0, time.UTC)
 	if n3 != e3 {
 		t.Errorf("n3 %s != %s", n3, e3)
 		return
 	}
 	n4 := r.NextAfter(n3)
-	e4 := time.Date(2000, 1, 1, 4, 25, 0, 0, time.UTC)
+	e4 := time.Date(2000, 1, 1, 4, 0, 0, 0, time.UTC)
 	if n4 != e4 {
 		t.Errorf("n4 %s != %s", n4, e4)
 		return
 	}
 	n5 := r.NextAfter(n4)
-	e5 := time.Date(2000, 1, 1, 4, 50, 0, 0, time.UTC)
+	e5 := time.Date(2000, 1, 1, 4, 25, 0, 0, time.UTC)
 	if n5 != e5 {
 		t.Errorf("n5 %s != %s", n5, e5)
 		return
@@ -236,3 +237,652 @@ func TestNaiveNextEdgeCasesDay(t *testing.T) {
 	}
 
 }
+
+func TestCeil(t *testing.T) {
+	r := MustNewRule("*/15", "*", "*", "*", "*")
+
+	exact := time.Date(2000, 4, 28, 14, 30, 0, 0, time.UTC)
+	if c := r.Ceil(exact); c != exact {
+		t.Errorf("1) %s != %s", c, exact)
+	}
+
+	c := r.Ceil(time.Date(2000, 4, 28, 14, 30, 1, 0, time.UTC))
+	e := time.Date(2000, 4, 28, 14, 45, 0, 0, time.UTC)
+	if c != e {
+		t.Errorf("2) %s != %s", c, e)
+	}
+
+	c = r.Ceil(time.Date(2000, 4, 28, 14, 31, 0, 0, time.UTC))
+	if c != e {
+		t.Errorf("3) %s != %s", c, e)
+	}
+}
+
+func TestFloor(t *testing.T) {
+	r := MustNewRule("*/15", "*", "*", "*", "*")
+
+	exact := time.Date(2000, 4, 28, 14, 30, 0, 0, time.UTC)
+	if f := r.Floor(exact); f != exact {
+		t.Errorf("1) %s != %s", f, exact)
+	}
+
+	f := r.Floor(time.Date(2000, 4, 28, 14, 44, 59, 0, time.UTC))
+	if f != exact {
+		t.Errorf("2) %s != %s", f, exact)
+	}
+
+	// previous day
+	r = MustNewRule("30", "12", "*", "*", "*")
+	f = r.Floor(time.Date(2000, 4, 28, 12, 29, 0, 0, time.UTC))
+	e := time.Date(2000, 4, 27, 12, 30, 0, 0, time.UTC)
+	if f != e {
+		t.Errorf("3) %s != %s", f, e)
+	}
+
+	// previous month
+	r = MustNewRule("0", "0", "31", "*", "*")
+	f = r.Floor(time.Date(2000, 5, 15, 0, 0, 0, 0, time.UTC))
+	e = time.Date(2000, 3, 31, 0, 0, 0, 0, time.UTC)
+	if f != e {
+		t.Errorf("4) %s != %s", f, e)
+	}
+}
+
+func TestMissedBetween(t *testing.T) {
+	r := MustNewRule("0", "*/6", "*", "*", "*")
+	lastRun := time.Date(2000, 4, 28, 6, 0, 0, 0, time.UTC)
+	now := time.Date(2000, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	missed := r.MissedBetween(lastRun, now, 0)
+	if len(missed) != 5 {
+		t.Errorf("%v should have 5 items", missed)
+		return
+	}
+	e := time.Date(2000, 4, 28, 12, 0, 0, 0, time.UTC)
+	if missed[0] != e {
+		t.Errorf("first %s != %s", missed[0], e)
+	}
+	if missed[4] != now {
+		t.Errorf("last %s != %s", missed[4], now)
+	}
+
+	missed = r.MissedBetween(lastRun, now, 2)
+	if len(missed) != 2 || missed[0] != e {
+		t.Errorf("%v should have 2 items starting at %s", missed, e)
+	}
+
+	if missed = r.MissedBetween(now, now, 0); len(missed) != 0 {
+		t.Errorf("%v should be empty", missed)
+	}
+}
+
+func TestOverlapsAfter(t *testing.T) {
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+
+	backups := MustNewRule("0", "2", "*", "*", "*")
+	deploys := MustNewRule("*/30", "*", "*", "*", "1")
+	o, ok := OverlapsAfter(backups, deploys, from, 7*24*time.Hour)
+	if !ok {
+		t.Error("should have overlapped")
+		return
+	}
+	e := time.Date(2000, 5, 1, 2, 0, 0, 0, time.UTC)
+	if o != e {
+		t.Errorf("%s != %s", o, e)
+	}
+
+	if _, ok = OverlapsAfter(backups, deploys, from, 24*time.Hour); ok {
+		t.Error("should not have overlapped within a day")
+	}
+
+	deploys = MustNewRule("15", "*", "*", "*", "*")
+	if _, ok = OverlapsAfter(backups, deploys, from, 365*24*time.Hour); ok {
+		t.Error("should never overlap")
+	}
+}
+
+func TestNthDayOfWeek(t *testing.T) {
+	r := MustNewRule("0", "0", "*", "*", "FRI#1")
+	if !r.Matches(time.Date(2000, 4, 7, 0, 0, 0, 0, time.UTC)) {
+		t.Error("first friday should match")
+	}
+	if r.Matches(time.Date(2000, 4, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Error("second friday should not match")
+	}
+	if s := r.StringNormalizedNames(); s != "0 0 * * FRI#1" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+
+	if _, err := NewRule("0", "0", "*", "*", "1/2#1"); err == nil {
+		t.Error("should have failed")
+	}
+	if _, err := NewRule("0", "0", "*", "*", "1#0"); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestLastDayOfWeek(t *testing.T) {
+	r := MustParseRule("0 17 * * 5L")
+	// the last Fridays of April 2000 and February 2024
+	for _, d := range []time.Time{time.Date(2000, 4, 28, 17, 0, 0, 0, time.UTC), time.Date(2024, 2, 23, 17, 0, 0, 0, time.UTC)} {
+		if !r.Matches(d) {
+			t.Errorf("%s should match", d)
+		}
+		if r.Matches(d.AddDate(0, 0, -7)) {
+			t.Errorf("%s should not match", d.AddDate(0, 0, -7))
+		}
+	}
+	n := r.NextAfter(time.Date(2000, 4, 28, 18, 0, 0, 0, time.UTC))
+	if e := time.Date(2000, 5, 26, 17, 0, 0, 0, time.UTC); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+	if s := MustNewRule("0", "17", "*", "*", "FRIL").StringNormalizedNames(); s != "0 17 * * FRIL" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if s := r.Describe(); !strings.Contains(s, "on the last Friday of the month") {
+		t.Errorf("'%s' Did not match!", s)
+	}
+
+	for _, item := range []string{"L", "1/2L", "1#2L"} {
+		if _, err := NewRule("0", "0", "*", "*", item); err == nil {
+			t.Errorf("%s: should have failed", item)
+		}
+	}
+}
+
+func TestLastDayOfMonth(t *testing.T) {
+	cases := []struct {
+		item     string
+		expected []time.Time
+	}{
+		{"L", []time.Time{
+			time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 2, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC),
+		}},
+		{"L-3", []time.Time{
+			time.Date(2024, 1, 28, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 2, 26, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 28, 0, 0, 0, 0, time.UTC),
+		}},
+		{"L-29", []time.Time{
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+		}},
+	}
+	for _, c := range cases {
+		r := MustNewRule("0", "0", c.item, "*", "*")
+		from := time.Date(2023, 12, 31, 12, 0, 0, 0, time.UTC)
+		if c.expected[0].Year() == 2023 {
+			from = time.Date(2022, 12, 31, 12, 0, 0, 0, time.UTC)
+		}
+		for _, e := range c.expected {
+			from = r.NextAfter(from)
+			if !from.Equal(e) {
+				t.Errorf("%s: %s != %s", c.item, from, e)
+			}
+			if r.Matches(e.AddDate(0, 0, -1)) {
+				t.Errorf("%s: %s should not match", c.item, e.AddDate(0, 0, -1))
+			}
+		}
+		if s := r.StringNormalized(); s != "0 0 "+c.item+" * *" {
+			t.Errorf("'%s' Did not match!", s)
+		}
+	}
+
+	if s := MustParseRule("0 0 L-3 * *").Describe(); !strings.Contains(s, "on the 4th to last day of the month") {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	for _, item := range []string{"L-31", "L-", "L-x", "L,1", "1L"} {
+		if _, err := NewRule("0", "0", item, "*", "*"); err == nil {
+			t.Errorf("%s: should have failed", item)
+		}
+	}
+}
+
+func TestLeapDay(t *testing.T) {
+	r := MustParseRule("0 0 29 2 *")
+	cases := []struct {
+		from, expected time.Time
+	}{
+		{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2028, 2, 28, 23, 59, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2032, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// 2100 is not a leap year
+		{time.Date(2097, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2104, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		if n := r.NextAfter(c.from); !n.Equal(c.expected) {
+			t.Errorf("%s: %s != %s", c.from, n, c.expected)
+		}
+	}
+	if p := r.Floor(time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)); !p.Equal(time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2028-02-29", p)
+	}
+
+	// a leap day on a Monday only comes around every 28 years
+	r = MustParseRule("0 0 29 2 MON")
+	if n := r.NextAfter(time.Date(2016, 3, 1, 0, 0, 0, 0, time.UTC)); !n.Equal(time.Date(2044, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2044-02-29", n)
+	}
+	if p := r.Floor(time.Date(2044, 2, 28, 0, 0, 0, 0, time.UTC)); !p.Equal(time.Date(2016, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("%s != 2016-02-29", p)
+	}
+}
+
+func TestNthFrom(t *testing.T) {
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+	for _, expr := range []string{"* * * * *", "*/25 */2 * * *", "0/30 9/17 * * 1/3/5", "15 4 29 2 *"} {
+		r := MustParseRule(expr)
+		e := from
+		for n := 1; n <= 50; n++ {
+			e = r.NextAfter(e)
+			if got := r.NthFrom(from, n); got != e {
+				t.Errorf("%s n=%d: %s != %s", expr, n, got, e)
+				break
+			}
+		}
+	}
+
+	r := MustParseRule("0 0 * * *")
+	exact := time.Date(2000, 4, 28, 0, 0, 0, 0, time.UTC)
+	e := time.Date(2000, 4, 30, 0, 0, 0, 0, time.UTC)
+	if n := r.NthFrom(exact, 2); n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+	if n := r.NthFrom(exact, 0); n != exact {
+		t.Errorf("%s != %s", n, exact)
+	}
+}
+
+func TestCountBetween(t *testing.T) {
+	start := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+	for _, expr := range []string{"* * * * *", "*/25 */2 * * *", "0 9/17 * * 1/3/5", "0/30 * 1/15 * *"} {
+		r := MustParseRule(expr)
+		end := start.Add(5 * 24 * time.Hour)
+		count := 0
+		for n := r.Ceil(start); n.Before(end); n = r.NextAfter(n) {
+			count++
+		}
+		if c := r.CountBetween(start, end); c != count {
+			t.Errorf("%s: %d != %d", expr, c, count)
+		}
+	}
+
+	r := MustParseRule("0 0 * * *")
+	year := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	if c := r.CountBetween(year, year.AddDate(1, 0, 0)); c != 366 {
+		t.Errorf("%d != 366", c)
+	}
+	if c := r.CountBetween(year, year); c != 0 {
+		t.Errorf("%d != 0", c)
+	}
+}
+
+func TestNextAfterSpringForward(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err.Error())
+	}
+	// clocks go forward at 01:00 on 2000-03-26
+	r := MustNewRule("0", "9", "*", "*", "*")
+	n := r.NextAfter(time.Date(2000, 3, 25, 9, 0, 0, 0, loc))
+	e := time.Date(2000, 3, 26, 9, 0, 0, 0, loc)
+	if !n.Equal(e) {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = r.NextAfter(n)
+	e = time.Date(2000, 3, 27, 9, 0, 0, 0, loc)
+	if !n.Equal(e) {
+		t.Errorf("2) %s != %s", n, e)
+	}
+}
+
+func TestNextAfterFallBack(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err.Error())
+	}
+	// clocks go back at 02:00 on 2000-10-29, making it 25 hours long
+	r := MustNewRule("0", "0", "*", "*", "*")
+	n := r.NextAfter(time.Date(2000, 10, 29, 0, 0, 0, 0, loc))
+	e := time.Date(2000, 10, 30, 0, 0, 0, 0, loc)
+	if !n.Equal(e) {
+		t.Errorf("1) %s != %s", n, e)
+	}
+
+	r = MustNewRule("30", "23", "*", "*", "*")
+	n = r.NextAfter(time.Date(2000, 10, 28, 23, 30, 0, 0, loc))
+	e = time.Date(2000, 10, 29, 23, 30, 0, 0, loc)
+	if !n.Equal(e) {
+		t.Errorf("2) %s != %s", n, e)
+	}
+}
+
+func TestNextAfterHourMinuteInterplay(t *testing.T) {
+	// the next hour must start from its first minute rather than the minute rounded up in the current hour
+	r := MustNewRule("0/30", "9/17", "*", "*", "*")
+	n := r.NextAfter(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	e := time.Date(2000, 4, 28, 17, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+}
+
+func TestStepMonths(t *testing.T) {
+	r, err := NewRule("0", "0", "1", "*/3", "*")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if s := r.StringNormalized(); s != "0 0 1 1,4,7,10 *" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	n := r.NextAfter(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	e := time.Date(2000, 7, 1, 0, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+
+	if _, err = NewRule("0", "0", "1", "*/12", "*"); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestStepDayOfWeek(t *testing.T) {
+	r := MustNewRule("0", "0", "*", "*", "*/2")
+	if s := r.StringNormalized(); s != "0 0 * * 0,2,4,6" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestSundayAsSeven(t *testing.T) {
+	r := MustNewRule("0", "0", "*", "*", "7")
+	if !r.Matches(time.Date(2000, 4, 30, 0, 0, 0, 0, time.UTC)) {
+		t.Error("sunday should match")
+	}
+	r = MustNewRule("0", "0", "*", "*", "7#1")
+	if !r.Matches(time.Date(2000, 4, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Error("first sunday should match")
+	}
+}
+
+func TestStepDayOfMonth(t *testing.T) {
+	r, err := NewRule("0", "0", "*/10", "*", "*")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if s := r.StringNormalized(); s != "0 0 1,11,21,31 * *" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	n := r.NextAfter(time.Date(2000, 4, 21, 0, 0, 0, 0, time.UTC))
+	e := time.Date(2000, 5, 1, 0, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("%s != %s", n, e)
+	}
+	if r.Matches(time.Date(2000, 4, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Error("10th should not match")
+	}
+}
+
+func TestRangeErrors(t *testing.T) {
+	for _, item := range []string{"99999999999999999999", "-1", "*/-5", "*/99999999999999999999", "1/-2", "60"} {
+		_, err := NewRule(item, "*", "*", "*", "*")
+		if _, ok := err.(*RangeError); !ok {
+			t.Errorf("'%s' should have given a RangeError but gave %v", item, err)
+		}
+	}
+
+	_, err := NewRule("*", "*", "*", "*", "8")
+	if re, ok := err.(*RangeError); !ok || re.Field != "Day of Week" || re.Max != 7 {
+		t.Errorf("%v should be a Day of Week RangeError", err)
+	}
+}
+
+func TestSyntaxErrors(t *testing.T) {
+	for _, item := range []string{"abc", "*/0", "5-1", "*/", "--1", "1-5/0", "1,,2", "1-"} {
+		_, err := NewRule(item, "*", "*", "*", "*")
+		if _, ok := err.(*SyntaxError); !ok {
+			t.Errorf("'%s' should have given a SyntaxError but gave %v", item, err)
+		}
+	}
+}
+
+func TestMixedItems(t *testing.T) {
+	cases := []struct {
+		item     string
+		f        field
+		expected string
+	}{
+		{"0,15-30/5,45", minuteField, "0,15,20,25,30,45"},
+		{"1-5", dayOfWeekField, "1,2,3,4,5"},
+		{"MON-WED,FRI", dayOfWeekField, "1,2,3,5"},
+		{"10-30/15", minuteField, "10,25"},
+		{"*/20,5", minuteField, "0,5,20,40"},
+		{"1/2,4-5", dayOfMonthField, "1,2,4,5"},
+		{"JAN-MAR,OCT-DEC/2", monthField, "1,2,3,10,12"},
+	}
+	for _, c := range cases {
+		values, err := parseRuleItem(c.item, c.f)
+		if err != nil {
+			t.Errorf("%s: %v", c.item, err)
+			continue
+		}
+		if s := normalizeField(values, c.f.min, c.f.max).String(); s != c.expected {
+			t.Errorf("%s: %s != %s", c.item, s, c.expected)
+		}
+	}
+
+	r := MustParseRule("0,30 9-17 * * MON-FRI")
+	if !r.Matches(time.Date(2026, 3, 6, 17, 30, 0, 0, time.UTC)) || r.Matches(time.Date(2026, 3, 7, 9, 0, 0, 0, time.UTC)) {
+		t.Error("0,30 9-17 * * MON-FRI did not match as expected")
+	}
+
+	for _, item := range []string{"0,60", "50-60", "0-10/60"} {
+		if _, err := NewRule(item, "*", "*", "*", "*"); err == nil {
+			t.Errorf("'%s' should have given a RangeError", item)
+		} else if _, ok := err.(*RangeError); !ok {
+			t.Errorf("'%s' should have given a RangeError but gave %v", item, err)
+		}
+	}
+}
+
+func TestUnorderedValues(t *testing.T) {
+	cases := []struct {
+		minute, dayOfWeek string
+		minutes, days     string
+	}{
+		{"30/10", "*", "10,30", "*"},
+		{"5/5/1", "*", "1,5", "*"},
+		{"45,0-10/5,5", "*", "0,5,10,45", "*"},
+		{"0", "5-7", "0", "0,5,6"},
+		{"0", "SUN/0/7", "0", "0"},
+	}
+	for _, c := range cases {
+		r, err := NewRule(c.minute, "*", "*", "*", c.dayOfWeek)
+		if err != nil {
+			t.Errorf("%s %s: %v", c.minute, c.dayOfWeek, err)
+			continue
+		}
+		// the stored values, not just the normalized ones, must be sorted without repeats
+		if s := FieldValues(r.minute).String(); s != c.minutes {
+			t.Errorf("%s != %s", s, c.minutes)
+		}
+		if s := FieldValues(r.dayOfWeek).String(); s != c.days {
+			t.Errorf("%s != %s", s, c.days)
+		}
+	}
+
+	r := MustParseRule("50/10/30 9 * * *")
+	from := time.Date(2026, 3, 6, 9, 5, 0, 0, time.UTC)
+	for _, e := range []int{10, 30, 50} {
+		from = r.NextAfter(from)
+		if from.Minute() != e || from.Hour() != 9 {
+			t.Errorf("%s Did not match! 09:%02d", from, e)
+		}
+	}
+}
+
+func TestNextAfterDifferential(t *testing.T) {
+	exprs := []string{
+		"* * * * *",
+		"59 23 * * *",
+		"0 0 * * *",
+		"30/10 9/17 * * *",
+		"*/7 */5 * * *",
+		"0 0 31 * *",
+		"0 12 30 1-3 *",
+		"15 3 29 2 *",
+		"0 0 1,15,31 * 1-5",
+		"45 23 L * *",
+		"45 23 L-2 * *",
+		"0 6 * * 5L",
+		"0 6 * * 1#5",
+		"0 0 * JUN/DEC SAT/SUN",
+		"0-5 22-23 28-31 * *",
+	}
+	var rules []*Rule
+	for _, e := range exprs {
+		rules = append(rules, MustParseRule(e))
+	}
+	either, _ := ParseKubernetes("0 0 13 * 5")
+	rules = append(rules, either)
+
+	limit := 2 * 366 * 24 * 60
+	starts := []time.Time{
+		time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 1, 31, 23, 59, 0, 0, time.UTC),
+		time.Date(2027, 12, 31, 23, 45, 30, 0, time.UTC),
+		time.Date(2028, 2, 28, 23, 59, 59, 0, time.UTC),
+		time.Date(2028, 2, 29, 3, 15, 0, 0, time.UTC),
+		time.Date(2026, 6, 30, 9, 40, 0, 0, time.FixedZone("", 5*3600+30*60)),
+	}
+	for _, r := range rules {
+		for _, s := range starts {
+			// follow a few occurrences from each start so that each carry is exercised
+			from := s
+			for i := 0; i < 5; i++ {
+				e := slowNextFrom(r, from, limit)
+				if e.Equal(farFuture) {
+					break
+				}
+				if got := r.NextAfter(from); !got.Equal(e) {
+					t.Errorf("%s after %s: %s != %s", r, from, got, e)
+					break
+				}
+				from = e
+			}
+			if e, got := slowFloor(r, s, limit), r.Floor(s); !e.Equal(farPast) && !got.Equal(e) {
+				t.Errorf("%s floor of %s: %s != %s", r, s, got, e)
+			}
+		}
+	}
+}
+
+func TestNextNonMatch(t *testing.T) {
+	r := MustParseRule("* 9 * * 1/2/3/4/5")
+	n := r.NextNonMatch(time.Date(2000, 4, 28, 9, 15, 30, 0, time.UTC))
+	e := time.Date(2000, 4, 28, 10, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+
+	// a whole weekend
+	r = MustParseRule("* * * * 0/6")
+	n = r.NextNonMatch(time.Date(2000, 4, 29, 12, 0, 0, 0, time.UTC))
+	e = time.Date(2000, 5, 1, 0, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+
+	// not currently matching
+	n = r.NextNonMatch(time.Date(2000, 4, 28, 12, 0, 0, 0, time.UTC))
+	e = time.Date(2000, 4, 28, 12, 1, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("3) %s != %s", n, e)
+	}
+
+	r = MustParseRule("* * * * *")
+	if n = r.NextNonMatch(time.Now()); n != farFuture {
+		t.Errorf("4) %s != %s", n, farFuture)
+	}
+}
+
+func TestMatchesAllocations(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	rules := []*Rule{
+		MustParseRule("*/5 9/10/11/12 * * 1/2/3/4/5"),
+		MustParseRule("0 0 1/15 JAN/JUL *"),
+		MustParseRule("CRON_TZ=Europe/London 30 1 * * 0#1"),
+	}
+	now := time.Date(2026, time.March, 29, 1, 30, 0, 0, loc)
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, r := range rules {
+			r.Matches(now)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("%v != 0", allocs)
+	}
+}
+
+// BenchmarkMatches checks a rule against a time as done for every rule on each tick of a scheduler. It should
+// report 0 allocs/op.
+func BenchmarkMatches(b *testing.B) {
+	r := MustParseRule("*/5 9/10/11/12 * * 1/2/3/4/5")
+	t := time.Date(2026, time.March, 4, 10, 15, 0, 0, time.UTC)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		r.Matches(t.Add(time.Duration(i%1440) * time.Minute))
+	}
+}
+
+func TestQuestionMark(t *testing.T) {
+	r, err := NewRule("0", "12", "?", "*", "MON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.String() != "0 12 ? * MON" || r.StringNormalized() != "0 12 * * 1" {
+		t.Errorf("'%s' Did not match!", r.StringNormalized())
+	}
+	if r, err = NewRule("0", "12", "15", "*", "?"); err != nil || r.StringNormalized() != "0 12 15 * *" {
+		t.Errorf("%v %v", r, err)
+	}
+	for _, item := range []string{"?", "?/2", "1/?"} {
+		if _, err := NewRule(item, "*", "*", "*", "*"); err == nil {
+			t.Errorf("%s: should have failed", item)
+		}
+	}
+	if _, err := NewRule("*", "*", "*", "*", "?#2"); err == nil {
+		t.Error("?#2 should have failed")
+	}
+}
+
+func TestMatchesInstant(t *testing.T) {
+	r := MustParseRule("0 9 * * *")
+	nine := time.Date(2026, time.March, 4, 9, 0, 0, 0, time.UTC)
+	minute, instant := 0, 0
+	for i := 0; i < 120; i++ {
+		t := nine.Add(time.Duration(i-30) * time.Second)
+		if r.MatchesMinute(t) {
+			minute++
+		}
+		if r.MatchesInstant(t) {
+			instant++
+		}
+	}
+	if minute != 60 || instant != 1 {
+		t.Errorf("%d, %d Did not match! 60, 1", minute, instant)
+	}
+	if r.MatchesInstant(nine.Add(time.Nanosecond)) {
+		t.Error("should not have matched")
+	}
+	if !r.WithGranularity(SecondGranularity).MatchesInstant(nine.Add(5 * time.Second)) {
+		t.Error("should have matched")
+	}
+}
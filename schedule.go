@@ -0,0 +1,116 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Schedule is anything that can say whether it matches a time and when it next matches. It is implemented by Rule
+// and RuleSet so that schedulers can accept any of them interchangeably.
+type Schedule interface {
+	// NextAfter returns the next time the schedule matches after the given time.
+	NextAfter(from time.Time) time.Time
+	// Matches returns whether the given time is matched by the schedule.
+	Matches(t time.Time) bool
+}
+
+// RuleSet is a group of rules that matches whenever any of its rules match.
+type RuleSet []*Rule
+
+// NextAfter returns the earliest next match of any of the rules after the given time.
+func (s RuleSet) NextAfter(from time.Time) time.Time {
+	next := farFuture
+	for _, r := range s {
+		if n := r.NextAfter(from); n.Before(next) {
+			next = n
+		}
+	}
+	return next
+}
+
+// Matches returns whether any of the rules match the given time.
+func (s RuleSet) Matches(t time.Time) bool {
+	for _, r := range s {
+		if r.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// EveryDuration is a schedule that matches at a fixed interval rather than on the wall clock. Occurrences are
+// aligned to whole multiples of the interval since the anchor, or since the Unix epoch if the anchor is zero, so
+// that every process using the same schedule fires at the same instants. Intervals are whole seconds.
+type EveryDuration struct {
+	Interval time.Duration
+	Anchor   time.Time
+}
+
+// Every returns a schedule matching every d, aligned to the Unix epoch. d is truncated to whole seconds, and
+// intervals shorter than one second are treated as one second.
+func Every(d time.Duration) *EveryDuration {
+	return &EveryDuration{Interval: d.Truncate(time.Second)}
+}
+
+// seconds returns the interval and anchor as whole seconds.
+func (e *EveryDuration) seconds() (int64, int64) {
+	interval := int64(e.Interval / time.Second)
+	if interval < 1 {
+		interval = 1
+	}
+	if e.Anchor.IsZero() {
+		return interval, 0
+	}
+	return interval, e.Anchor.Unix()
+}
+
+// NextAfter returns the next occurrence after the given time, in the location of the given time.
+func (e *EveryDuration) NextAfter(from time.Time) time.Time {
+	interval, anchor := e.seconds()
+	elapsed := from.Unix() - anchor
+	k := elapsed / interval
+	if elapsed < 0 && elapsed%interval != 0 {
+		k--
+	}
+	return time.Unix(anchor+(k+1)*interval, 0).In(from.Location())
+}
+
+// Matches returns whether the given time falls within the second of an occurrence.
+func (e *EveryDuration) Matches(t time.Time) bool {
+	interval, anchor := e.seconds()
+	return (t.Unix()-anchor)%interval == 0
+}
+
+// String returns the schedule in the form accepted by ParseSchedule, such as "@every 10m30s". The interval is
+// shown as it is used, in whole seconds and at least one second.
+func (e *EveryDuration) String() string {
+	interval, _ := e.seconds()
+	return "@every " + (time.Duration(interval) * time.Second).String()
+}
+
+// AtTime is a schedule that matches exactly once, for deferred one-off jobs.
+type AtTime struct {
+	Time time.Time
+}
+
+// At returns a schedule matching only the given time.
+func At(t time.Time) *AtTime {
+	return &AtTime{Time: t}
+}
+
+// NextAfter returns the time of the schedule if it is after from, otherwise a time far in the future.
+func (a *AtTime) NextAfter(from time.Time) time.Time {
+	if a.Time.After(from) {
+		return a.Time
+	}
+	return farFuture
+}
+
+// Matches returns whether the given time falls within the same second as the time of the schedule.
+func (a *AtTime) Matches(t time.Time) bool {
+	return t.Unix() == a.Time.Unix()
+}
+
+// String returns the schedule in the form accepted by ParseSchedule, such as "@at 2026-01-01T00:00:00Z".
+func (a *AtTime) String() string {
+	return "@at " + a.Time.Format(time.RFC3339)
+}
@@ -0,0 +1,138 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	_ Schedule = new(Rule)
+	_ Schedule = RuleSet{}
+	_ Schedule = new(EveryDuration)
+	_ Schedule = new(AtTime)
+)
+
+func TestRuleSet(t *testing.T) {
+	s := RuleSet{
+		MustParseRule("0 9 * * 1/5"),
+		MustParseRule("30 17 * * 1/5"),
+	}
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+
+	n := s.NextAfter(from)
+	e := time.Date(2000, 4, 28, 17, 30, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = s.NextAfter(n)
+	e = time.Date(2000, 5, 1, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	if !s.Matches(e) {
+		t.Errorf("%s should match", e)
+	}
+	if s.Matches(from) {
+		t.Errorf("%s should not match", from)
+	}
+}
+
+func TestRuleSetEmpty(t *testing.T) {
+	if n := (RuleSet{}).NextAfter(time.Now()); n.Year() < 3000 {
+		t.Errorf("%s should be far in the future", n)
+	}
+}
+
+func TestEveryDuration(t *testing.T) {
+	e := Every(10*time.Minute + 30*time.Second)
+	from := time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC)
+
+	n := e.NextAfter(from)
+	if !n.After(from) || n.Sub(from) > e.Interval || !e.Matches(n) {
+		t.Errorf("%s is not the next occurrence after %s", n, from)
+	}
+	if n2 := e.NextAfter(n); n2.Sub(n) != e.Interval {
+		t.Errorf("%s should be %s after %s", n2, e.Interval, n)
+	}
+	if e.Matches(n.Add(time.Second)) {
+		t.Errorf("%s should not match", n.Add(time.Second))
+	}
+
+	// intervals are reported as they are used, in whole seconds
+	for d, expected := range map[time.Duration]string{
+		1500 * time.Millisecond: "@every 1s",
+		500 * time.Millisecond:  "@every 1s",
+		90 * time.Second:        "@every 1m30s",
+	} {
+		if s := Every(d).String(); s != expected {
+			t.Errorf("'%s' Did not match! '%s'", s, expected)
+		}
+	}
+	if s := (&EveryDuration{Interval: 2500 * time.Millisecond}).String(); s != "@every 2s" {
+		t.Errorf("'%s' Did not match! '@every 2s'", s)
+	}
+}
+
+func TestEveryDurationAnchor(t *testing.T) {
+	anchor := time.Date(2000, 4, 28, 0, 0, 7, 0, time.UTC)
+	e := &EveryDuration{Interval: time.Hour, Anchor: anchor}
+
+	n := e.NextAfter(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	x := time.Date(2000, 4, 28, 15, 0, 7, 0, time.UTC)
+	if n != x {
+		t.Errorf("1) %s != %s", n, x)
+	}
+	n = e.NextAfter(time.Date(2000, 4, 27, 23, 30, 0, 0, time.UTC))
+	x = time.Date(2000, 4, 28, 0, 0, 7, 0, time.UTC)
+	if n != x {
+		t.Errorf("2) %s != %s", n, x)
+	}
+}
+
+func TestParseScheduleEvery(t *testing.T) {
+	s, err := ParseSchedule("@every 10m30s")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	if e, ok := s.(*EveryDuration); !ok || e.Interval != 10*time.Minute+30*time.Second {
+		t.Errorf("%v Did not match!", s)
+	}
+
+	s, err = ParseSchedule("*/5 * * * *")
+	if _, ok := s.(*Rule); err != nil || !ok {
+		t.Errorf("%v should be a rule: %v", s, err)
+	}
+
+	for _, expr := range []string{"@every", "@every 10x", "@every 10ms", "@every -1h"} {
+		if _, err = ParseSchedule(expr); err == nil {
+			t.Errorf("'%s' should have failed", expr)
+		}
+	}
+}
+
+func TestAtTime(t *testing.T) {
+	s, err := ParseSchedule("@at 2026-01-01T00:00:00Z")
+	if err != nil {
+		t.Error(err.Error())
+		return
+	}
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if n := s.NextAfter(at.Add(-time.Hour)); n != at {
+		t.Errorf("%s != %s", n, at)
+	}
+	if n := s.NextAfter(at); n.Year() < 3000 {
+		t.Errorf("%s should be far in the future", n)
+	}
+	if !s.Matches(at) {
+		t.Errorf("%s should match", at)
+	}
+	if s.Matches(at.Add(time.Minute)) {
+		t.Errorf("%s should not match", at.Add(time.Minute))
+	}
+
+	if _, err = ParseSchedule("@at tomorrow"); err == nil {
+		t.Error("should have failed")
+	}
+}
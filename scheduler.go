@@ -0,0 +1,672 @@
+package ticktickrules
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is the work run by a Scheduler for each occurrence of its schedule. occurrence is the time the job was
+// scheduled for, which may be slightly earlier than the time it actually started.
+type Job func(ctx context.Context, occurrence time.Time) error
+
+// DefaultMaxSleep is how long a Scheduler sleeps at most before checking the wall clock again.
+const DefaultMaxSleep = time.Minute
+
+// Scheduler runs jobs on their schedules. Wake ups are always recomputed against the wall clock, so the scheduler
+// copes with the clock being stepped by NTP or the machine being suspended: occurrences missed while the clock
+// jumped forward fire once, and a clock stepped backwards never repeats an occurrence that already fired.
+type Scheduler struct {
+	clock    Clock
+	maxSleep time.Duration
+	metrics  Metrics
+	logger   Logger
+	store    Store
+	locker   Locker
+	isLeader func() bool
+	onError  func(id EntryID, occurrence time.Time, err error)
+
+	// wg tracks runs in progress, and stop is closed to stop triggering new runs
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+	stopped  bool
+
+	mu      sync.Mutex
+	entries []*entry
+	lastID  EntryID
+	// lastRuns are the last occurrences loaded from the store, used to catch up jobs when they are first scheduled
+	lastRuns map[string]time.Time
+	// wake interrupts the sleep of a running scheduler when its entries change
+	wake chan struct{}
+}
+
+// EntryID identifies a job added to a Scheduler.
+type EntryID int
+
+// entry is a job registered with a scheduler.
+type entry struct {
+	id          EntryID
+	name        string
+	schedule    Schedule
+	job         Job
+	concurrency ConcurrencyPolicy
+	timeout     time.Duration
+	onTimeout   func(id EntryID, occurrence time.Time)
+	retry       RetryPolicy
+	next        time.Time
+	prev        time.Time
+	saved       time.Time
+	paused      bool
+	running     int
+	stats       RunStats
+
+	// inflight holds each running occurrence, keyed by a run number
+	inflight map[int]*dueJob
+	runs     int
+}
+
+// ConcurrencyPolicy controls what happens when an occurrence of a job is due while a previous run of the job is
+// still going. The policies mirror those of a Kubernetes CronJob.
+type ConcurrencyPolicy int
+
+const (
+	// Allow starts the new run alongside the previous one. This is the default.
+	Allow ConcurrencyPolicy = iota
+	// Forbid skips the new occurrence.
+	Forbid
+	// Replace cancels the context of the previous run and starts the new one.
+	Replace
+)
+
+// JobOption configures a single job added to a Scheduler.
+type JobOption func(*entry)
+
+// WithConcurrencyPolicy sets what the scheduler does when the job is due while still running.
+func WithConcurrencyPolicy(p ConcurrencyPolicy) JobOption {
+	return func(e *entry) {
+		e.concurrency = p
+	}
+}
+
+// WithTimeout limits how long each run of the job may take. When the timeout is exceeded the job's context is
+// cancelled and onTimeout, if not nil, is called with the id of the entry. Jobs must watch their context for the
+// timeout to stop them.
+func WithTimeout(d time.Duration, onTimeout func(id EntryID, occurrence time.Time)) JobOption {
+	return func(e *entry) {
+		e.timeout = d
+		e.onTimeout = onTimeout
+	}
+}
+
+// RetryPolicy controls how a failed run of a job is retried before waiting for the next occurrence.
+type RetryPolicy struct {
+	// MaxAttempts is the most times the job is run per occurrence, including the first. Values below 2 disable
+	// retries.
+	MaxAttempts int
+	// Backoff returns how long to wait before the given retry, starting from 1. A nil Backoff retries immediately.
+	Backoff func(retry int) time.Duration
+	// RetryIf returns whether an error should be retried. A nil RetryIf retries every error.
+	RetryIf func(err error) bool
+}
+
+// ExponentialBackoff returns a Backoff waiting base before the first retry and doubling for each retry after
+// that, up to max.
+func ExponentialBackoff(base, max time.Duration) func(retry int) time.Duration {
+	return func(retry int) time.Duration {
+		d := base
+		for i := 1; i < retry && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			return max
+		}
+		return d
+	}
+}
+
+// WithRetry makes the scheduler retry failed runs of the job according to p. Any timeout applies to all of the
+// attempts together.
+func WithRetry(p RetryPolicy) JobOption {
+	return func(e *entry) {
+		e.retry = p
+	}
+}
+
+// SchedulerOption configures a Scheduler.
+type SchedulerOption func(*Scheduler)
+
+// WithClock makes the scheduler use the given clock instead of SystemClock.
+func WithClock(c Clock) SchedulerOption {
+	return func(s *Scheduler) {
+		s.clock = c
+	}
+}
+
+// WithMaxSleep sets how long the scheduler sleeps at most before checking the wall clock again. Timers do not
+// notice the wall clock being stepped, so this bounds how late a job can be after a clock jump.
+func WithMaxSleep(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		s.maxSleep = d
+	}
+}
+
+// WithMetrics makes the scheduler report instrumentation events to m.
+func WithMetrics(m Metrics) SchedulerOption {
+	return func(s *Scheduler) {
+		s.metrics = m
+	}
+}
+
+// WithLogger makes the scheduler log its decisions, such as when each job will next run, to l.
+func WithLogger(l Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.logger = l
+	}
+}
+
+// WithStore makes the scheduler record the last occurrence run by each job in st. When the scheduler starts, any
+// job whose next occurrence after its last run has already passed is run once straight away to catch up, and the
+// other occurrences it missed are reported to the metrics, up to a limit. The missed occurrences are found with the
+// NextAfter method of the job's schedule, so this works for any Schedule and not only a Rule.
+func WithStore(st Store) SchedulerOption {
+	return func(s *Scheduler) {
+		s.store = st
+	}
+}
+
+// WithLocker makes the scheduler take a lock from l before each run, and skip the run if another instance already
+// holds it. Locks are keyed by job name and occurrence, and held until the job's next occurrence.
+func WithLocker(l Locker) SchedulerOption {
+	return func(s *Scheduler) {
+		s.locker = l
+	}
+}
+
+// WithLeaderCheck makes the scheduler only run jobs while isLeader returns true, for running redundant replicas
+// where one is elected to do the work. isLeader is checked on every wake up, at least every max sleep. Occurrences
+// while not the leader are skipped, and next occurrences are recomputed on becoming the leader. Runs already in
+// progress when leadership is lost are left to finish.
+func WithLeaderCheck(isLeader func() bool) SchedulerOption {
+	return func(s *Scheduler) {
+		s.isLeader = isLeader
+	}
+}
+
+// WithErrorHandler makes the scheduler call fn whenever a run of a job fails, after any retries. Panics in jobs are
+// recovered and reported to fn as a *PanicError, so that one broken job does not stop the others.
+func WithErrorHandler(fn func(id EntryID, occurrence time.Time, err error)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onError = fn
+	}
+}
+
+// NewScheduler returns a Scheduler with no jobs.
+func NewScheduler(opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{clock: SystemClock, maxSleep: DefaultMaxSleep, metrics: NopMetrics{}, logger: nopLogger{}}
+	s.wake = make(chan struct{}, 1)
+	s.stop = make(chan struct{})
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add registers a job to run on the schedule and returns its ID. Names must be unique. Jobs can be added while
+// the scheduler is running.
+func (s *Scheduler) Add(name string, schedule Schedule, job Job, opts ...JobOption) (EntryID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.name == name {
+			return 0, fmt.Errorf("Job '%s' already exists", name)
+		}
+	}
+	s.lastID++
+	e := &entry{id: s.lastID, name: name, schedule: schedule, job: job, inflight: make(map[int]*dueJob)}
+	for _, opt := range opts {
+		opt(e)
+	}
+	s.entries = append(s.entries, e)
+	s.notify()
+	return e.id, nil
+}
+
+// Remove removes a job from the scheduler. Runs of the job that have already started are left to finish.
+func (s *Scheduler) Remove(id EntryID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, e := range s.entries {
+		if e.id == id {
+			s.entries = append(s.entries[:i], s.entries[i+1:]...)
+			s.notify()
+			return nil
+		}
+	}
+	return fmt.Errorf("Entry %d does not exist", id)
+}
+
+// Pause stops a job from running until it is resumed. Occurrences while paused are not caught up.
+func (s *Scheduler) Pause(id EntryID) error {
+	return s.update(id, func(e *entry) {
+		e.paused = true
+		e.next = time.Time{}
+	})
+}
+
+// Resume starts running a paused job again from its next occurrence.
+func (s *Scheduler) Resume(id EntryID) error {
+	return s.update(id, func(e *entry) {
+		e.paused = false
+	})
+}
+
+// update calls fn on the entry with the given id and wakes the scheduler to recompute its next wake up.
+func (s *Scheduler) update(id EntryID, fn func(e *entry)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if e.id == id {
+			fn(e)
+			s.notify()
+			return nil
+		}
+	}
+	return fmt.Errorf("Entry %d does not exist", id)
+}
+
+// RunStats counts the runs of a job since it was added to the scheduler.
+type RunStats struct {
+	Started  int
+	Finished int
+	Failed   int
+	// LastDuration and LastError describe the most recently finished run
+	LastDuration time.Duration
+	LastError    error
+}
+
+// EntryInfo describes a job registered with a Scheduler.
+type EntryInfo struct {
+	ID       EntryID
+	Name     string
+	Schedule Schedule
+	// Next is the next occurrence the job will run for, or the zero time if it has not been computed yet or the
+	// job is paused
+	Next time.Time
+	// Prev is the most recent occurrence the job was started for, or the zero time if it has not run
+	Prev    time.Time
+	Paused  bool
+	Running int
+	Stats   RunStats
+}
+
+// Entries returns a snapshot of every job in the order they were added.
+func (s *Scheduler) Entries() []EntryInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]EntryInfo, len(s.entries))
+	for i, e := range s.entries {
+		out[i] = EntryInfo{
+			ID:       e.id,
+			Name:     e.name,
+			Schedule: e.schedule,
+			Next:     e.next,
+			Prev:     e.prev,
+			Paused:   e.paused,
+			Running:  e.running,
+			Stats:    e.stats,
+		}
+	}
+	return out
+}
+
+// notify wakes a running scheduler without blocking.
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs the jobs until ctx is cancelled, then waits for any running jobs to return. It returns the error from
+// the context, or an error if the store could not be loaded.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.store != nil {
+		lastRuns, err := s.store.Load()
+		if err != nil {
+			return fmt.Errorf("Failed to load scheduler state: %s", err.Error())
+		}
+		s.mu.Lock()
+		s.lastRuns = lastRuns
+		s.mu.Unlock()
+	}
+
+	defer s.wg.Wait()
+
+	last := wallClock(s.clock.Now())
+	leader := true
+	for {
+		now := wallClock(s.clock.Now())
+
+		// a clock stepped far backwards would otherwise leave jobs waiting for times computed from the future
+		if now.Before(last.Add(-s.maxSleep)) {
+			s.logger.Warn("clock stepped backwards, recomputing schedules", "from", last, "to", now)
+			s.reset()
+		}
+		last = now
+
+		sleep := s.maxSleep
+		if s.isLeader == nil || s.isLeader() {
+			if !leader {
+				s.logger.Info("became leader, recomputing schedules")
+				s.reset()
+				leader = true
+			}
+			for _, d := range s.due(ctx, now) {
+				go func(d *dueJob) {
+					defer s.wg.Done()
+					s.run(d)
+				}(d)
+			}
+			if next := s.nextWake().Sub(now); next < sleep {
+				sleep = next
+			}
+		} else if leader {
+			s.logger.Info("lost leadership, pausing jobs")
+			leader = false
+		}
+
+		timer := s.clock.NewTimer(sleep)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.stop:
+			timer.Stop()
+			return nil
+		case <-timer.C():
+		case <-s.wake:
+			timer.Stop()
+		}
+	}
+}
+
+// AbandonedRun is a run that was still going when the scheduler was stopped. It is not recorded in the store, so
+// it is caught up when the scheduler next starts.
+type AbandonedRun struct {
+	ID         EntryID
+	Name       string
+	Occurrence time.Time
+}
+
+// Stop stops the scheduler from starting any new runs and waits for the runs in progress to finish, or for ctx to
+// be done. Runs still going when ctx is done have their contexts cancelled and are returned as abandoned, along
+// with the error from ctx. Run returns nil once the scheduler is stopped.
+func (s *Scheduler) Stop(ctx context.Context) ([]AbandonedRun, error) {
+	s.stopOnce.Do(func() {
+		s.mu.Lock()
+		s.stopped = true
+		s.mu.Unlock()
+		close(s.stop)
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	var out []AbandonedRun
+	for _, e := range s.entries {
+		for _, d := range e.inflight {
+			d.abandoned = true
+			d.cancel()
+			out = append(out, AbandonedRun{ID: e.id, Name: e.name, Occurrence: d.occurrence})
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Occurrence.Before(out[j].Occurrence)
+	})
+	s.logger.Warn("abandoned runs on stop", "count", len(out))
+	return out, ctx.Err()
+}
+
+// dueJob is a job to be started for an occurrence.
+type dueJob struct {
+	entry      *entry
+	occurrence time.Time
+	ctx        context.Context
+	cancel     context.CancelFunc
+	run        int
+	// until is when the lock for the run expires
+	until time.Time
+	// abandoned is set when the run was cancelled by Stop, and is guarded by the scheduler's mutex
+	abandoned bool
+}
+
+// run runs a single occurrence of a job and reports it to the metrics and logger.
+func (s *Scheduler) run(d *dueJob) {
+	if s.locker != nil {
+		key := fmt.Sprintf("%s/%d", d.entry.name, d.occurrence.Unix())
+		if !s.locker.TryLock(key, d.until) {
+			s.logger.Info("occurrence locked by another instance", "job", d.entry.name, "occurrence", d.occurrence)
+			d.cancel()
+			s.mu.Lock()
+			d.entry.running--
+			delete(d.entry.inflight, d.run)
+			s.mu.Unlock()
+			return
+		}
+	}
+
+	s.mu.Lock()
+	d.entry.prev = d.occurrence
+	d.entry.stats.Started++
+	s.mu.Unlock()
+
+	s.metrics.JobStarted(d.entry.name, d.occurrence)
+	start := s.clock.Now()
+	if d.entry.timeout > 0 {
+		timer := s.clock.NewTimer(d.entry.timeout)
+		defer timer.Stop()
+		go s.watchTimeout(d, timer)
+	}
+	err := s.attempt(d)
+	d.cancel()
+	duration := s.clock.Now().Sub(start)
+
+	s.mu.Lock()
+	d.entry.running--
+	delete(d.entry.inflight, d.run)
+	d.entry.stats.Finished++
+	d.entry.stats.LastDuration = duration
+	d.entry.stats.LastError = err
+	if err != nil {
+		d.entry.stats.Failed++
+	}
+	// runs may finish out of order, but the store should only move forwards
+	save := s.store != nil && !d.abandoned && d.occurrence.After(d.entry.saved)
+	if save {
+		d.entry.saved = d.occurrence
+	}
+	s.mu.Unlock()
+
+	if save {
+		if saveErr := s.store.Save(d.entry.name, d.occurrence); saveErr != nil {
+			s.logger.Error("failed to save last run", "job", d.entry.name, "occurrence", d.occurrence, "error", saveErr)
+		}
+	}
+	s.metrics.JobFinished(d.entry.name, d.occurrence, duration, err)
+	if err != nil {
+		s.logger.Error("job failed", "job", d.entry.name, "occurrence", d.occurrence, "error", err)
+		if s.onError != nil {
+			s.onError(d.entry.id, d.occurrence, err)
+		}
+	}
+}
+
+// attempt runs the job, retrying it according to its retry policy, and returns the error from the last attempt.
+func (s *Scheduler) attempt(d *dueJob) error {
+	p := d.entry.retry
+	err := s.call(d)
+	for retry := 1; retry < p.MaxAttempts && err != nil; retry++ {
+		if p.RetryIf != nil && !p.RetryIf(err) {
+			return err
+		}
+		s.logger.Info("retrying job", "job", d.entry.name, "occurrence", d.occurrence, "retry", retry, "error", err)
+		if p.Backoff != nil {
+			timer := s.clock.NewTimer(p.Backoff(retry))
+			select {
+			case <-d.ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C():
+			}
+		}
+		if d.ctx.Err() != nil {
+			return err
+		}
+		err = s.call(d)
+	}
+	return err
+}
+
+// call runs the job once, recovering any panic as a *PanicError.
+func (s *Scheduler) call(d *dueJob) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return d.entry.job(d.ctx, d.occurrence)
+}
+
+// watchTimeout cancels the run when the timer fires, unless the run finished first.
+func (s *Scheduler) watchTimeout(d *dueJob, timer Timer) {
+	select {
+	case <-d.ctx.Done():
+	case <-timer.C():
+		s.logger.Warn("job timed out", "job", d.entry.name, "occurrence", d.occurrence, "timeout", d.entry.timeout)
+		d.cancel()
+		if d.entry.onTimeout != nil {
+			d.entry.onTimeout(d.entry.id, d.occurrence)
+		}
+	}
+}
+
+// due returns the jobs whose next occurrence is at or before now and advances them past now. Occurrences missed
+// while the clock jumped forward are collapsed into one. Each job is given a context derived from ctx, and is
+// counted in s.wg until it finishes.
+func (s *Scheduler) due(ctx context.Context, now time.Time) []*dueJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil
+	}
+	var out []*dueJob
+	for _, e := range s.entries {
+		if e.paused {
+			continue
+		}
+		if e.next.IsZero() {
+			lastRun, ok := s.lastRuns[e.name]
+			delete(s.lastRuns, e.name)
+			if !ok || !lastRun.Before(now) {
+				s.schedule(e, now)
+				continue
+			}
+			// catch up from the last run recorded in the store
+			s.schedule(e, lastRun)
+			if e.next.After(now) {
+				continue
+			}
+			s.logger.Info("catching up missed occurrences", "job", e.name, "last", lastRun)
+		}
+		if e.next.After(now) {
+			continue
+		}
+
+		missed := e.schedule.NextAfter(e.next)
+		for i := 0; i < maxMissedReported && !missed.After(now); i++ {
+			s.metrics.JobMissed(e.name, missed)
+			s.logger.Info("occurrence missed", "job", e.name, "occurrence", missed)
+			missed = e.schedule.NextAfter(missed)
+		}
+		occurrence := e.next
+		skip := false
+		if e.running > 0 {
+			s.metrics.JobOverrun(e.name, occurrence)
+			switch e.concurrency {
+			case Forbid:
+				s.logger.Info("occurrence skipped, previous run still going", "job", e.name, "occurrence", occurrence)
+				skip = true
+			case Replace:
+				s.logger.Warn("cancelling previous run", "job", e.name, "occurrence", occurrence)
+				for run, previous := range e.inflight {
+					previous.cancel()
+					delete(e.inflight, run)
+				}
+			default:
+				s.logger.Warn("occurrence overlaps a previous run", "job", e.name, "occurrence", occurrence)
+			}
+		}
+		s.schedule(e, now)
+		if skip {
+			continue
+		}
+
+		e.running++
+		e.runs++
+		runCtx, cancel := context.WithCancel(ctx)
+		d := &dueJob{entry: e, occurrence: occurrence, ctx: runCtx, cancel: cancel, run: e.runs, until: e.next}
+		e.inflight[e.runs] = d
+		out = append(out, d)
+	}
+	s.wg.Add(len(out))
+	return out
+}
+
+// schedule computes the next occurrence of the entry after from. The caller must hold s.mu.
+func (s *Scheduler) schedule(e *entry, from time.Time) {
+	e.next = e.schedule.NextAfter(from)
+	s.metrics.JobScheduled(e.name, e.next)
+	s.logger.Debug("next occurrence computed", "job", e.name, "next", e.next)
+}
+
+// nextWake returns the earliest next occurrence of any job.
+func (s *Scheduler) nextWake() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	next := farFuture
+	for _, e := range s.entries {
+		if !e.paused && e.next.Before(next) {
+			next = e.next
+		}
+	}
+	return next
+}
+
+// reset forgets the next occurrence of every job so that they are recomputed from the current time.
+func (s *Scheduler) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		e.next = time.Time{}
+	}
+}
+
+// wallClock strips the monotonic clock reading from t so that comparisons use the wall clock. The monotonic clock
+// does not follow NTP steps or advance while suspended, but occurrences are defined on the wall clock.
+func wallClock(t time.Time) time.Time {
+	return t.Round(0)
+}
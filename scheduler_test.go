@@ -0,0 +1,504 @@
+package ticktickrules
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// runScheduler starts the scheduler and returns a channel receiving each occurrence run by the named job.
+func runScheduler(t *testing.T, s *Scheduler, c *FakeClock, schedule Schedule) (chan time.Time, func()) {
+	ran := make(chan time.Time, 100)
+	if _, err := s.Add("job", schedule, func(ctx context.Context, occurrence time.Time) error {
+		ran <- occurrence
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		_ = s.Run(ctx)
+		close(done)
+	}()
+	c.BlockUntil(1)
+	return ran, func() {
+		cancel()
+		<-done
+	}
+}
+
+func expectRun(t *testing.T, ran chan time.Time, e time.Time) {
+	select {
+	case o := <-ran:
+		if o != e {
+			t.Errorf("%s != %s", o, e)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("%s did not run", e)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	s := NewScheduler(WithClock(c), WithMaxSleep(time.Hour))
+	ran, stop := runScheduler(t, s, c, MustParseRule("*/15 * * * *"))
+	defer stop()
+
+	c.Advance(78 * time.Second)
+	expectRun(t, ran, time.Date(2000, 4, 28, 14, 30, 0, 0, time.UTC))
+	c.BlockUntil(1)
+	c.Advance(15*time.Minute - time.Second)
+	select {
+	case o := <-ran:
+		t.Errorf("%s should not have run yet", o)
+	case <-time.After(10 * time.Millisecond):
+	}
+	c.Advance(time.Second)
+	expectRun(t, ran, time.Date(2000, 4, 28, 14, 45, 0, 0, time.UTC))
+}
+
+func TestSchedulerAddDuplicate(t *testing.T) {
+	s := NewScheduler()
+	job := func(ctx context.Context, occurrence time.Time) error {
+		return nil
+	}
+	if _, err := s.Add("a", MustParseRule("* * * * *"), job); err != nil {
+		t.Error(err.Error())
+	}
+	if _, err := s.Add("a", MustParseRule("* * * * *"), job); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestSchedulerClockJumpForward(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	s := NewScheduler(WithClock(c), WithMaxSleep(time.Minute))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+
+	// the machine is suspended for three hours, so the monotonic timer has not fired
+	c.Step(3 * time.Hour)
+	c.Advance(time.Minute)
+	expectRun(t, ran, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	c.BlockUntil(1)
+
+	// missed occurrences are collapsed into one
+	select {
+	case o := <-ran:
+		t.Errorf("%s should not have run", o)
+	default:
+	}
+	c.Advance(time.Hour)
+	expectRun(t, ran, time.Date(2000, 4, 28, 18, 0, 0, 0, time.UTC))
+}
+
+func TestSchedulerClockJumpBackward(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	s := NewScheduler(WithClock(c), WithMaxSleep(time.Minute))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+
+	// the clock was a day fast and gets corrected
+	c.Step(-24 * time.Hour)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	c.Advance(31 * time.Minute)
+	expectRun(t, ran, time.Date(2000, 4, 27, 15, 0, 0, 0, time.UTC))
+}
+
+func TestSchedulerForbid(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m))
+	started := make(chan time.Time, 10)
+	release := make(chan struct{})
+	if _, err := s.Add("job", MustParseRule("* * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		started <- occurrence
+		<-release
+		return nil
+	}, WithConcurrencyPolicy(Forbid)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	expectRun(t, started, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	select {
+	case o := <-started:
+		t.Errorf("%s should have been skipped", o)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	release <- struct{}{}
+	m.waitFor(t, 6)
+	c.Advance(time.Minute)
+	expectRun(t, started, time.Date(2000, 4, 28, 15, 2, 0, 0, time.UTC))
+	close(release)
+}
+
+func TestSchedulerReplace(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	s := NewScheduler(WithClock(c))
+	started := make(chan time.Time, 10)
+	cancelled := make(chan time.Time, 10)
+	if _, err := s.Add("job", MustParseRule("* * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		started <- occurrence
+		<-ctx.Done()
+		cancelled <- occurrence
+		return ctx.Err()
+	}, WithConcurrencyPolicy(Replace)); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	expectRun(t, started, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	expectRun(t, cancelled, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	expectRun(t, started, time.Date(2000, 4, 28, 15, 1, 0, 0, time.UTC))
+}
+
+func TestSchedulerTimeout(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	s := NewScheduler(WithClock(c))
+	cancelled := make(chan time.Time, 10)
+	timedOut := make(chan time.Time, 10)
+	var timedOutID EntryID
+	id, err := s.Add("job", MustParseRule("* * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		<-ctx.Done()
+		cancelled <- occurrence
+		return ctx.Err()
+	}, WithTimeout(30*time.Second, func(id EntryID, occurrence time.Time) {
+		timedOutID = id
+		timedOut <- occurrence
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	// the scheduler and the timeout are both waiting
+	c.BlockUntil(2)
+	c.Advance(30 * time.Second)
+	expectRun(t, timedOut, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	expectRun(t, cancelled, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	if timedOutID != id {
+		t.Errorf("%v != %v", timedOutID, id)
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff(time.Second, 10*time.Second)
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second}
+	for i, e := range expected {
+		if d := b(i + 1); d != e {
+			t.Errorf("%d) %s != %s", i+1, d, e)
+		}
+	}
+}
+
+func TestSchedulerRetry(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m))
+	attempts := make(chan time.Time, 10)
+	errTransient := errors.New("transient")
+	if _, err := s.Add("job", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		attempts <- c.Now()
+		return errTransient
+	}, WithRetry(RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     ExponentialBackoff(time.Second, time.Minute),
+		RetryIf: func(err error) bool {
+			return err == errTransient
+		},
+	})); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	expectRun(t, attempts, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	c.BlockUntil(2)
+	c.Advance(time.Second)
+	expectRun(t, attempts, time.Date(2000, 4, 28, 15, 0, 1, 0, time.UTC))
+	c.BlockUntil(2)
+	c.Advance(2 * time.Second)
+	expectRun(t, attempts, time.Date(2000, 4, 28, 15, 0, 3, 0, time.UTC))
+
+	events := m.waitFor(t, 4)
+	if events[3] != "finished job 15:00 transient" {
+		t.Errorf("'%s' Did not match!", events[3])
+	}
+	c.BlockUntil(1)
+	select {
+	case a := <-attempts:
+		t.Errorf("%s should not have been attempted", a)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestSchedulerAddRemoveWhileRunning(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMaxSleep(time.Hour), WithMetrics(m))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+
+	// a job added while sleeping until 15:00 is picked up straight away
+	other := make(chan time.Time, 10)
+	id, err := s.Add("other", MustParseRule("30 14 * * *"), func(ctx context.Context, occurrence time.Time) error {
+		other <- occurrence
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.waitFor(t, 2)
+	c.BlockUntil(1)
+	c.Advance(78 * time.Second)
+	expectRun(t, other, time.Date(2000, 4, 28, 14, 30, 0, 0, time.UTC))
+
+	if err := s.Remove(id); err != nil {
+		t.Error(err.Error())
+	}
+	if err := s.Remove(id); err == nil {
+		t.Error("should have failed")
+	}
+	c.BlockUntil(1)
+	c.Advance(30 * time.Minute)
+	expectRun(t, ran, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+}
+
+func TestSchedulerPauseResume(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMaxSleep(time.Hour), WithMetrics(m))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+
+	if err := s.Pause(1); err != nil {
+		t.Error(err.Error())
+	}
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	c.BlockUntil(1)
+	select {
+	case o := <-ran:
+		t.Errorf("%s should not have run while paused", o)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if err := s.Resume(1); err != nil {
+		t.Error(err.Error())
+	}
+	m.waitFor(t, 2)
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	expectRun(t, ran, time.Date(2000, 4, 28, 16, 0, 0, 0, time.UTC))
+
+	if err := s.Pause(2); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestSchedulerEntries(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m))
+	rule := MustParseRule("0 * * * *")
+	if _, err := s.Add("job", rule, func(ctx context.Context, occurrence time.Time) error {
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	id, _ := s.Add("paused", rule, func(ctx context.Context, occurrence time.Time) error {
+		return nil
+	})
+	if err := s.Pause(id); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Run(ctx)
+
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	m.waitFor(t, 4)
+
+	entries := s.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("%d != 2", len(entries))
+	}
+	e := entries[0]
+	if e.ID != 1 || e.Name != "job" || e.Schedule != rule || e.Paused {
+		t.Errorf("%+v", e)
+	}
+	if e.Prev != time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC) || e.Next != time.Date(2000, 4, 28, 16, 0, 0, 0, time.UTC) {
+		t.Errorf("%s %s", e.Prev, e.Next)
+	}
+	if e.Stats.Started != 1 || e.Stats.Finished != 1 || e.Stats.Failed != 1 || e.Stats.LastError == nil {
+		t.Errorf("%+v", e.Stats)
+	}
+	if e = entries[1]; !e.Paused || !e.Next.IsZero() || e.Stats.Started != 0 {
+		t.Errorf("%+v", e)
+	}
+}
+
+func TestSchedulerLeaderCheck(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	var mu sync.Mutex
+	isLeader := false
+	setLeader := func(l bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		isLeader = l
+	}
+	s := NewScheduler(WithClock(c), WithLeaderCheck(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return isLeader
+	}))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+
+	// not the leader over 15:00
+	c.Advance(35 * time.Minute)
+	c.BlockUntil(1)
+	select {
+	case o := <-ran:
+		t.Errorf("%s should not have run", o)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	// becoming leader does not catch up 15:00
+	setLeader(true)
+	c.Advance(time.Minute)
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	expectRun(t, ran, time.Date(2000, 4, 28, 16, 0, 0, 0, time.UTC))
+}
+
+func TestSchedulerStopDrain(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	st := NewMemoryStore()
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithStore(st), WithMetrics(m))
+	release := make(chan struct{})
+	if _, err := s.Add("quick", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		<-release
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("slow", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		done <- s.Run(context.Background())
+	}()
+	c.BlockUntil(1)
+	c.Advance(time.Minute)
+	m.waitFor(t, 6)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	type result struct {
+		abandoned []AbandonedRun
+		err       error
+	}
+	stopped := make(chan result)
+	go func() {
+		abandoned, err := s.Stop(ctx)
+		stopped <- result{abandoned, err}
+	}()
+
+	// the quick job is allowed to finish, but the slow one is abandoned
+	close(release)
+	m.waitFor(t, 7)
+	cancel()
+	r := <-stopped
+	if r.err != context.Canceled {
+		t.Errorf("%v != %v", r.err, context.Canceled)
+	}
+	e := AbandonedRun{ID: 2, Name: "slow", Occurrence: time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC)}
+	if len(r.abandoned) != 1 || r.abandoned[0] != e {
+		t.Errorf("%v != %v", r.abandoned, e)
+	}
+	if err := <-done; err != nil {
+		t.Error(err.Error())
+	}
+
+	lastRuns, _ := st.Load()
+	if len(lastRuns) != 1 || lastRuns["quick"] != e.Occurrence {
+		t.Errorf("%v", lastRuns)
+	}
+}
+
+func TestSchedulerStopIdle(t *testing.T) {
+	s := NewScheduler()
+	abandoned, err := s.Stop(context.Background())
+	if len(abandoned) != 0 || err != nil {
+		t.Errorf("%v %v", abandoned, err)
+	}
+	if err = s.Run(context.Background()); err != nil {
+		t.Error(err.Error())
+	}
+}
+
+func TestSchedulerPanicRecovery(t *testing.T) {
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 59, 0, 0, time.UTC))
+	errs := make(chan error, 10)
+	s := NewScheduler(WithClock(c), WithErrorHandler(func(id EntryID, occurrence time.Time, err error) {
+		if id != 1 || occurrence != time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC) {
+			t.Errorf("%d %s", id, occurrence)
+		}
+		errs <- err
+	}))
+	if _, err := s.Add("broken", MustParseRule("0 * * * *"), func(ctx context.Context, occurrence time.Time) error {
+		panic("oh no")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+	defer stop()
+	c.Advance(time.Minute)
+
+	// the other job keeps running
+	expectRun(t, ran, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	select {
+	case err := <-errs:
+		p, ok := err.(*PanicError)
+		if !ok || p.Value != "oh no" || len(p.Stack) == 0 {
+			t.Errorf("%#v", err)
+		}
+		if err.Error() != "Job panicked: oh no" {
+			t.Errorf("'%s' Did not match!", err.Error())
+		}
+	case <-time.After(time.Second):
+		t.Error("panic was not reported")
+	}
+}
@@ -0,0 +1,58 @@
+package ticktickrules
+
+// WithMinute returns a copy of the rule with the minute field replaced by the given item, which takes the same
+// forms as the arguments to NewRule. The location, policies, and other settings of the rule are kept, except that
+// replacing the minute or hour field drops a TimeOfDay set with WithTimeOfDay. An error is returned if the item is
+// invalid.
+func (r *Rule) WithMinute(item string) (*Rule, error) {
+	return r.withField(0, item)
+}
+
+// WithHour is like WithMinute but replaces the hour field.
+func (r *Rule) WithHour(item string) (*Rule, error) {
+	return r.withField(1, item)
+}
+
+// WithDayOfMonth is like WithMinute but replaces the day of month field.
+func (r *Rule) WithDayOfMonth(item string) (*Rule, error) {
+	return r.withField(2, item)
+}
+
+// WithMonth is like WithMinute but replaces the month field.
+func (r *Rule) WithMonth(item string) (*Rule, error) {
+	return r.withField(3, item)
+}
+
+// WithDayOfWeek is like WithMinute but replaces the day of week field.
+func (r *Rule) WithDayOfWeek(item string) (*Rule, error) {
+	return r.withField(4, item)
+}
+
+// withField returns a copy of the rule with the i-th field, in the order of ruleFields, replaced.
+func (r *Rule) withField(i int, item string) (*Rule, error) {
+	items := []string{r.minuteRule, r.hourRule, r.dayOfMonthRule, r.monthRule, r.dayOfWeekRule}
+	items[i] = item
+	parsed, err := NewRule(items[0], items[1], items[2], items[3], items[4])
+	if err != nil {
+		return nil, err
+	}
+	out := r.withFields(parsed)
+	if i <= 1 {
+		out.timeOfDay, out.timeOfDayName = nil, ""
+	}
+	return out, nil
+}
+
+// withFields returns a copy of the rule with all five fields taken from parsed and the other settings kept.
+func (r *Rule) withFields(parsed *Rule) *Rule {
+	out := *r
+	out.minute, out.minuteRule = parsed.minute, parsed.minuteRule
+	out.hour, out.hourRule = parsed.hour, parsed.hourRule
+	out.dayOfMonth, out.dayOfMonthRule = parsed.dayOfMonth, parsed.dayOfMonthRule
+	out.dayOfMonthLast, out.dayOfMonthOffset = parsed.dayOfMonthLast, parsed.dayOfMonthOffset
+	out.month, out.monthRule = parsed.month, parsed.monthRule
+	out.dayOfWeek, out.dayOfWeekRule = parsed.dayOfWeek, parsed.dayOfWeekRule
+	out.dayOfWeekNth, out.dayOfWeekLast = parsed.dayOfWeekNth, parsed.dayOfWeekLast
+	out.buildMasks()
+	return &out
+}
@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWithField(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+	r := MustParseRule("0 9 * * *").In(loc).WithFallBackPolicy(FireTwice)
+
+	setters := []struct {
+		fn       func(string) (*Rule, error)
+		item     string
+		expected string
+	}{
+		{r.WithMinute, "*/30", "CRON_TZ=Europe/London */30 9 * * *"},
+		{r.WithHour, "9/17", "CRON_TZ=Europe/London 0 9/17 * * *"},
+		{r.WithDayOfMonth, "1", "CRON_TZ=Europe/London 0 9 1 * *"},
+		{r.WithMonth, "JAN", "CRON_TZ=Europe/London 0 9 * JAN *"},
+		{r.WithDayOfWeek, "5#3", "CRON_TZ=Europe/London 0 9 * * 5#3"},
+	}
+	for _, s := range setters {
+		out, err := s.fn(s.item)
+		if err != nil {
+			t.Errorf("%s: %s", s.item, err)
+			continue
+		}
+		if out.String() != s.expected || out.fallBack != FireTwice {
+			t.Errorf("'%s' Did not match! '%s'", out.String(), s.expected)
+		}
+		if out.Fingerprint() != MustParseRule(s.expected).WithFallBackPolicy(FireTwice).Fingerprint() {
+			t.Errorf("%s: unexpected fingerprint", s.expected)
+		}
+	}
+	if r.String() != "CRON_TZ=Europe/London 0 9 * * *" {
+		t.Errorf("'%s' was modified", r)
+	}
+
+	out, _ := r.WithMinute("30")
+	if n, e := out.NextAfter(time.Date(2026, time.March, 4, 0, 0, 0, 0, loc)), time.Date(2026, time.March, 4, 9, 30, 0, 0, loc); !n.Equal(e) {
+		t.Errorf("%s != %s", n, e)
+	}
+
+	for _, fn := range []func(string) (*Rule, error){r.WithMinute, r.WithHour, r.WithDayOfMonth, r.WithMonth, r.WithDayOfWeek} {
+		if _, err := fn("99"); err == nil {
+			t.Error("should have failed")
+		}
+	}
+}
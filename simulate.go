@@ -0,0 +1,64 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// Event is a single occurrence of a named schedule during a simulation.
+type Event struct {
+	Name string
+	Time time.Time
+}
+
+// Simulator replays the occurrences of a set of schedules over a virtual time range without waiting for them,
+// so that a whole configuration can be checked against a simulated year in milliseconds.
+type Simulator struct {
+	entries []simulatorEntry
+}
+
+type simulatorEntry struct {
+	name     string
+	schedule Schedule
+	callback func(Event)
+}
+
+// NewSimulator returns a Simulator with no schedules.
+func NewSimulator() *Simulator {
+	return &Simulator{}
+}
+
+// Add registers a schedule with the simulator. callback, if not nil, is called for each of its events as they are
+// replayed.
+func (s *Simulator) Add(name string, schedule Schedule, callback func(Event)) {
+	s.entries = append(s.entries, simulatorEntry{name: name, schedule: schedule, callback: callback})
+}
+
+// Run replays every occurrence of the schedules within [start, end) in time order and returns them. Occurrences
+// at the same instant are ordered by the order their schedules were added.
+func (s *Simulator) Run(start, end time.Time) []Event {
+	next := make([]time.Time, len(s.entries))
+	for i, e := range s.entries {
+		next[i] = e.schedule.NextAfter(start.Add(-time.Nanosecond))
+	}
+
+	var out []Event
+	for {
+		earliest := -1
+		for i, n := range next {
+			if n.Before(end) && (earliest < 0 || n.Before(next[earliest])) {
+				earliest = i
+			}
+		}
+		if earliest < 0 {
+			return out
+		}
+
+		e := s.entries[earliest]
+		event := Event{Name: e.name, Time: next[earliest]}
+		out = append(out, event)
+		if e.callback != nil {
+			e.callback(event)
+		}
+		next[earliest] = e.schedule.NextAfter(event.Time)
+	}
+}
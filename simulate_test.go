@@ -0,0 +1,45 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulator(t *testing.T) {
+	var backups int
+	s := NewSimulator()
+	s.Add("hourly", MustParseRule("0 * * * *"), nil)
+	s.Add("backup", MustParseRule("0 2 * * *"), func(e Event) {
+		backups++
+	})
+	s.Add("launch", At(time.Date(2000, 4, 28, 3, 30, 0, 0, time.UTC)), nil)
+
+	events := s.Run(time.Date(2000, 4, 28, 2, 0, 0, 0, time.UTC), time.Date(2000, 4, 28, 4, 0, 0, 0, time.UTC))
+	expected := []Event{
+		{"hourly", time.Date(2000, 4, 28, 2, 0, 0, 0, time.UTC)},
+		{"backup", time.Date(2000, 4, 28, 2, 0, 0, 0, time.UTC)},
+		{"hourly", time.Date(2000, 4, 28, 3, 0, 0, 0, time.UTC)},
+		{"launch", time.Date(2000, 4, 28, 3, 30, 0, 0, time.UTC)},
+	}
+	if len(events) != len(expected) {
+		t.Errorf("%d != %d", len(events), len(expected))
+		return
+	}
+	for i, e := range expected {
+		if events[i] != e {
+			t.Errorf("%d) %v != %v", i, events[i], e)
+		}
+	}
+	if backups != 1 {
+		t.Errorf("%d != 1", backups)
+	}
+}
+
+func TestSimulatorYear(t *testing.T) {
+	s := NewSimulator()
+	s.Add("weekly", MustParseRule("0 9 * * 1"), nil)
+	events := s.Run(time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2002, 1, 1, 0, 0, 0, 0, time.UTC))
+	if len(events) != 53 {
+		t.Errorf("%d != 53", len(events))
+	}
+}
@@ -0,0 +1,66 @@
+package ticktickrules
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ToStandardCron renders the rule using only POSIX crontab syntax, with comma separated lists, ranges, and steps,
+// for example "0/15/30/45 9/10/11/12 * * 1/2/3/4/5" becomes "*/15 9-12 * * 1-5". This is the form expected by
+// systems such as Kubernetes CronJobs.
+//
+// Some rules cannot be represented exactly. A "N#K" or "NL" day of week and a "L-N" day of month are kept as they
+// are, and the location, calendar, and other restrictions are dropped. Standard cron fires when either the day of
+// month or the day of week matches if both are restricted, whereas rules from ParseRule require both to match. Use
+// Format with the Standard dialect to get an error for these rules instead.
+func (r *Rule) ToStandardCron() string {
+	dow := compactField(r.DaysOfWeek(), dayOfWeekField.min, dayOfWeekField.max) + r.occurrenceSuffix()
+	return strings.Join([]string{
+		compactField(r.Minutes(), minuteField.min, minuteField.max),
+		compactField(r.Hours(), hourField.min, hourField.max),
+		r.dayOfMonthString(compactField(r.DaysOfMonth(), dayOfMonthField.min, dayOfMonthField.max)),
+		compactField(r.Months(), monthField.min, monthField.max),
+		dow,
+	}, " ")
+}
+
+// compactField renders normalized values as briefly as possible using steps, ranges, and commas.
+func compactField(values FieldValues, min, max int) string {
+	if len(values) == 0 {
+		return "*"
+	}
+
+	// evenly spaced values are written as a step
+	if len(values) >= 3 {
+		step := values[1] - values[0]
+		even := step > 1
+		for i := 2; i < len(values) && even; i++ {
+			even = values[i]-values[i-1] == step
+		}
+		if even {
+			last := values[len(values)-1]
+			if values[0] == min && last+step > max {
+				return "*/" + strconv.Itoa(step)
+			}
+			return strconv.Itoa(values[0]) + "-" + strconv.Itoa(last) + "/" + strconv.Itoa(step)
+		}
+	}
+
+	// otherwise runs of three or more consecutive values are written as ranges
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, strconv.Itoa(values[i])+"-"+strconv.Itoa(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, strconv.Itoa(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,40 @@
+package ticktickrules
+
+import (
+	"testing"
+)
+
+func TestToStandardCron(t *testing.T) {
+	cases := map[string]string{
+		"* * * * *":                           "* * * * *",
+		"0/15/30/45 9/10/11/12 * * 1/2/3/4/5": "*/15 9-12 * * 1-5",
+		"*/20 */2 1/15 JAN/JUL 7":             "*/20 */2 1,15 1,7 0",
+		"5/25/45 1/2/3/5/6 * * *":             "5-45/20 1-3,5,6 * * *",
+		"0 0 * * 5#3":                         "0 0 * * 5#3",
+		"CRON_TZ=UTC 0 12 */10 * *":           "0 12 */10 * *",
+	}
+	for expr, e := range cases {
+		if s := MustParseRule(expr).ToStandardCron(); s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+	}
+}
+
+func TestCompactField(t *testing.T) {
+	cases := []struct {
+		values   FieldValues
+		expected string
+	}{
+		{nil, "*"},
+		{FieldValues{3}, "3"},
+		{FieldValues{1, 2}, "1,2"},
+		{FieldValues{0, 30}, "0,30"},
+		{FieldValues{0, 10, 20, 30, 40, 50}, "*/10"},
+		{FieldValues{1, 2, 3, 7, 8, 9, 10}, "1-3,7-10"},
+	}
+	for _, c := range cases {
+		if s := compactField(c.values, 0, 59); s != c.expected {
+			t.Errorf("%v: '%s' Did not match! '%s'", c.values, s, c.expected)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package ticktickrules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Store persists the last occurrence run by each job of a Scheduler, keyed by job name, so that a restarted
+// scheduler can catch up on the occurrences it missed while it was down. Only these times are stored: the jobs
+// themselves must be added to the scheduler again on start up, and are matched to their last runs by name. See
+// WithStore for how missed occurrences are caught up.
+type Store interface {
+	// Load returns the last occurrence run by each job, keyed by job name.
+	Load() (map[string]time.Time, error)
+	// Save records the last occurrence run by the named job.
+	Save(name string, occurrence time.Time) error
+}
+
+// MemoryStore is a Store that keeps the last runs in memory. It is mostly useful for tests.
+type MemoryStore struct {
+	mu       sync.Mutex
+	lastRuns map[string]time.Time
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{lastRuns: make(map[string]time.Time)}
+}
+
+// Load returns a copy of the last runs.
+func (m *MemoryStore) Load() (map[string]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]time.Time, len(m.lastRuns))
+	for k, v := range m.lastRuns {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// Save records the last run of the named job.
+func (m *MemoryStore) Save(name string, occurrence time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastRuns[name] = occurrence
+	return nil
+}
+
+// FileStore is a Store that keeps the last runs in a JSON file. The file is replaced atomically on every save.
+type FileStore struct {
+	path string
+
+	mu       sync.Mutex
+	lastRuns map[string]time.Time
+}
+
+// NewFileStore returns a FileStore using the file at path, which is created on the first save if it does not exist.
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+// Load reads the last runs from the file. A missing file has no last runs.
+func (f *FileStore) Load() (map[string]time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	out := make(map[string]time.Time, len(f.lastRuns))
+	for k, v := range f.lastRuns {
+		out[k] = v
+	}
+	return out, nil
+}
+
+// Save records the last run of the named job and writes the file.
+func (f *FileStore) Save(name string, occurrence time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.load(); err != nil {
+		return err
+	}
+	f.lastRuns[name] = occurrence
+
+	data, err := json.MarshalIndent(f.lastRuns, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), f.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// load reads the file the first time it is needed. The caller must hold f.mu.
+func (f *FileStore) load() error {
+	if f.lastRuns != nil {
+		return nil
+	}
+	lastRuns := make(map[string]time.Time)
+	data, err := os.ReadFile(f.path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err = json.Unmarshal(data, &lastRuns); err != nil {
+			return fmt.Errorf("Store file '%s' is invalid: %s", f.path, err.Error())
+		}
+	}
+	f.lastRuns = lastRuns
+	return nil
+}
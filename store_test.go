@@ -0,0 +1,86 @@
+package ticktickrules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var (
+	_ Store = NewMemoryStore()
+	_ Store = NewFileStore("")
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := os.MkdirTemp("", "ticktickrules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	f := NewFileStore(path)
+	lastRuns, err := f.Load()
+	if err != nil || len(lastRuns) != 0 {
+		t.Errorf("%v %v", lastRuns, err)
+	}
+	occurrence := time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC)
+	if err = f.Save("job", occurrence); err != nil {
+		t.Fatal(err)
+	}
+
+	// a new store reads the file written by the first
+	lastRuns, err = NewFileStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lastRuns["job"].Equal(occurrence) {
+		t.Errorf("%s != %s", lastRuns["job"], occurrence)
+	}
+
+	if err = os.WriteFile(path, []byte("nope"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = NewFileStore(path).Load(); err == nil {
+		t.Error("should have failed")
+	}
+}
+
+func TestSchedulerStoreCatchUp(t *testing.T) {
+	st := NewMemoryStore()
+	if err := st.Save("job", time.Date(2000, 4, 28, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewFakeClock(time.Date(2000, 4, 28, 14, 28, 42, 0, time.UTC))
+	m := new(recordingMetrics)
+	s := NewScheduler(WithClock(c), WithMetrics(m), WithStore(st))
+	ran, stop := runScheduler(t, s, c, MustParseRule("0 * * * *"))
+
+	// the scheduler was down for the 13:00 and 14:00 runs, so 13:00 is caught up and 14:00 reported missed
+	expectRun(t, ran, time.Date(2000, 4, 28, 13, 0, 0, 0, time.UTC))
+	expected := []string{
+		"scheduled job 13:00",
+		"missed job 14:00",
+		"scheduled job 15:00",
+		"started job 13:00",
+		"finished job 13:00 <nil>",
+	}
+	events := m.waitFor(t, len(expected))
+	for i, e := range expected {
+		if events[i] != e {
+			t.Errorf("%d) '%s' Did not match! '%s'", i, events[i], e)
+		}
+	}
+
+	c.Advance(time.Hour)
+	expectRun(t, ran, time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC))
+	m.waitFor(t, len(expected)+3)
+	stop()
+
+	lastRuns, _ := st.Load()
+	if e := time.Date(2000, 4, 28, 15, 0, 0, 0, time.UTC); lastRuns["job"] != e {
+		t.Errorf("%s != %s", lastRuns["job"], e)
+	}
+}
@@ -0,0 +1,150 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// macros maps the common cron shorthands to their equivalent expressions.
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ruleFields lists the field specs in the order they appear in an expression.
+var ruleFields = []field{minuteField, hourField, dayOfMonthField, monthField, dayOfWeekField}
+
+// SuggestCorrections proposes fixes for common mistakes in an expression that ParseRule rejects, such as including
+// a seconds field or writing names in lower case. Each suggestion describes one fix followed by the expression with
+// that fix and all of the previous ones applied, so the last suggestion is the most complete. Nil is returned if
+// the expression is already valid or no fixes are known.
+func SuggestCorrections(expr string) []string {
+	if _, err := ParseRule(expr); err == nil {
+		return nil
+	}
+
+	var out []string
+	var prefix string
+	fields := strings.Fields(expr)
+	suggest := func(reason string) {
+		out = append(out, fmt.Sprintf("%s: '%s'", reason, prefix+strings.Join(fields, " ")))
+	}
+
+	if strings.Join(fields, " ") != expr {
+		suggest("remove the extra whitespace")
+	}
+	if len(fields) > 0 {
+		if _, ok := cutZonePrefix(fields[0]); ok {
+			prefix = fields[0] + " "
+			fields = fields[1:]
+		}
+	}
+	if len(fields) == 1 {
+		if m, ok := macros[strings.ToLower(fields[0])]; ok {
+			original := fields[0]
+			fields = strings.Fields(m)
+			suggest(fmt.Sprintf("'%s' is not supported, write it out in full", original))
+		}
+	}
+	switch len(fields) {
+	case 6:
+		fields = fields[1:]
+		suggest("6 fields given, did you mean to drop the seconds field")
+	case 7:
+		fields = fields[1:6]
+		suggest("7 fields given, did you mean to drop the seconds and year fields")
+	}
+	if len(fields) != 5 {
+		return out
+	}
+
+	fixes := []struct {
+		reason string
+		fix    func(item string, f field) string
+	}{
+		{"'?' is only supported in the day fields, use '*'", fixQuestionMark},
+		{"names must be upper case three letter abbreviations such as 'MON' or 'JAN'", fixNames},
+		{"values are out of range", fixOutOfRange},
+	}
+	for _, fx := range fixes {
+		changed := false
+		for i, f := range ruleFields {
+			if fixed := fx.fix(fields[i], f); fixed != fields[i] {
+				fields[i] = fixed
+				changed = true
+			}
+		}
+		if changed {
+			suggest(fx.reason)
+		}
+	}
+	return out
+}
+
+// fixQuestionMark replaces the Quartz "no specific value" marker with "*" in fields that do not accept it.
+func fixQuestionMark(item string, f field) string {
+	if item == "?" && !f.question {
+		return "*"
+	}
+	return item
+}
+
+// fixNames upper cases names and shortens full names like "Monday" to their abbreviation.
+func fixNames(item string, f field) string {
+	if f.names == nil {
+		return item
+	}
+	return mapValues(item, func(v string) string {
+		// either end of a range may be a name
+		bounds := strings.Split(v, "-")
+		for i, b := range bounds {
+			upper := strings.ToUpper(b)
+			for _, n := range f.names {
+				if len(upper) >= 3 && strings.HasPrefix(upper, n) {
+					bounds[i] = n
+					break
+				}
+			}
+		}
+		return strings.Join(bounds, "-")
+	})
+}
+
+// fixOutOfRange wraps values one past the end of the minute and hour fields, such as hour 24, around to 0.
+func fixOutOfRange(item string, f field) string {
+	if f.min != 0 {
+		return item
+	}
+	return mapValues(item, func(v string) string {
+		if n, err := strconv.Atoi(v); err == nil && n == f.max+1 && f.alias != n {
+			return "0"
+		}
+		return v
+	})
+}
+
+// mapValues applies fn to each of the values in a "," or "/" separated item, leaving the step of "*/N" and "N-M/S"
+// alone.
+func mapValues(item string, fn func(v string) string) string {
+	parts := strings.Split(item, ",")
+	for i, p := range parts {
+		if j := strings.Index(p, "/"); j >= 0 && (strings.HasPrefix(p, "*") || strings.Contains(p[:j], "-")) {
+			if p[:j] != "*" {
+				parts[i] = fn(p[:j]) + p[j:]
+			}
+			continue
+		}
+		values := strings.Split(p, "/")
+		for k, v := range values {
+			values[k] = fn(v)
+		}
+		parts[i] = strings.Join(values, "/")
+	}
+	return strings.Join(parts, ",")
+}
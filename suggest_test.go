@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+)
+
+func TestSuggestCorrections(t *testing.T) {
+	cases := []struct {
+		expr     string
+		expected []string
+	}{
+		{"0 9 * * 1", nil},
+		{"0 0 9 * * MON", []string{
+			"6 fields given, did you mean to drop the seconds field: '0 9 * * MON'",
+		}},
+		{"0 0 9 ? * MON 2020", []string{
+			"7 fields given, did you mean to drop the seconds and year fields: '0 9 ? * MON'",
+		}},
+		{"0 ? * * *", []string{
+			"'?' is only supported in the day fields, use '*': '0 * * * *'",
+		}},
+		{"0,30  9 * * mon-fri", []string{
+			"remove the extra whitespace: '0,30 9 * * mon-fri'",
+			"names must be upper case three letter abbreviations such as 'MON' or 'JAN': '0,30 9 * * MON-FRI'",
+		}},
+		{"CRON_TZ=UTC 0 24 * January *", []string{
+			"names must be upper case three letter abbreviations such as 'MON' or 'JAN': 'CRON_TZ=UTC 0 24 * JAN *'",
+			"values are out of range: 'CRON_TZ=UTC 0 0 * JAN *'",
+		}},
+		{"0 9 * * mon,wed-fri", []string{
+			"names must be upper case three letter abbreviations such as 'MON' or 'JAN': '0 9 * * MON,WED-FRI'",
+		}},
+		{"0 24 * * *", []string{
+			"values are out of range: '0 0 * * *'",
+		}},
+		{"@daily", []string{
+			"'@daily' is not supported, write it out in full: '0 0 * * *'",
+		}},
+		{"nonsense", nil},
+	}
+	for _, c := range cases {
+		out := SuggestCorrections(c.expr)
+		if len(out) != len(c.expected) {
+			t.Errorf("%s: %d != %d %v", c.expr, len(out), len(c.expected), out)
+			continue
+		}
+		for i, e := range c.expected {
+			if out[i] != e {
+				t.Errorf("%s: '%s' Did not match!", c.expr, out[i])
+			}
+		}
+	}
+}
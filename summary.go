@@ -0,0 +1,167 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// summaryNextTimes is the number of upcoming occurrences included in a Summary.
+const summaryNextTimes = 5
+
+// FieldSummary describes a single field of a rule.
+type FieldSummary struct {
+	// Name is the name of the field, such as "Minute".
+	Name string
+	// Rule is the item as it was given.
+	Rule string
+	// Values are the values matched, which is empty if any value is matched.
+	Values FieldValues
+}
+
+// Summary is a plain description of a rule that can be rendered directly by templates or encoded as JSON.
+type Summary struct {
+	Expression  string
+	Fields      []FieldSummary
+	NextTimes   []time.Time
+	Description string
+	// Satisfiable is false if the rule can never fire, such as "0 0 30 2 *".
+	Satisfiable bool
+}
+
+// Summary describes the rule and its next few occurrences from now.
+func (r *Rule) Summary() Summary {
+	return r.SummaryAt(SystemClock.Now())
+}
+
+// SummaryAt is like Summary but lists the occurrences after the given time.
+func (r *Rule) SummaryAt(from time.Time) Summary {
+	out := Summary{
+		Expression: r.String(),
+		Fields: []FieldSummary{
+			{minuteField.name, r.minuteRule, r.Minutes()},
+			{hourField.name, r.hourRule, r.Hours()},
+			{dayOfMonthField.name, r.dayOfMonthRule, r.DaysOfMonth()},
+			{monthField.name, r.monthRule, r.Months()},
+			{dayOfWeekField.name, r.dayOfWeekRule, r.DaysOfWeek()},
+		},
+		Description: r.Describe(),
+	}
+	for t := from; len(out.NextTimes) < summaryNextTimes; {
+		if t = r.NextAfter(t); t.Equal(farFuture) {
+			break
+		}
+		out.NextTimes = append(out.NextTimes, t)
+	}
+	out.Satisfiable = len(out.NextTimes) > 0
+	return out
+}
+
+// Describe renders the rule as an English sentence, for example "30 9 * * 1/2/3/4/5" is described as
+// "at 09:30 on Monday, Tuesday, Wednesday, Thursday and Friday".
+func (r *Rule) Describe() string {
+	minutes, hours := r.Minutes(), r.Hours()
+	var parts []string
+	switch {
+	case r.timeOfDay != nil:
+		parts = append(parts, "at @"+r.timeOfDayName)
+	case len(minutes) == 0 && len(hours) == 0:
+		parts = append(parts, "every minute")
+	case len(minutes) == 0:
+		parts = append(parts, "every minute during "+plural("hour", hours)+" "+joinAnd(numbers(hours)))
+	case len(hours) == 0:
+		parts = append(parts, "at "+plural("minute", minutes)+" "+joinAnd(numbers(minutes))+" of every hour")
+	case len(minutes)*len(hours) <= 6:
+		var times []string
+		for _, h := range hours {
+			for _, m := range minutes {
+				times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+			}
+		}
+		parts = append(parts, "at "+joinAnd(times))
+	default:
+		parts = append(parts, "at "+plural("minute", minutes)+" "+joinAnd(numbers(minutes))+
+			" of "+plural("hour", hours)+" "+joinAnd(numbers(hours)))
+	}
+
+	var dayParts []string
+	if days := r.DaysOfMonth(); len(days) > 0 {
+		dayParts = append(dayParts, "on "+plural("day", days)+" "+joinAnd(numbers(days))+" of the month")
+	} else if r.dayOfMonthLast && r.dayOfMonthOffset > 0 {
+		dayParts = append(dayParts, "on the "+ordinal(r.dayOfMonthOffset+1)+" to last day of the month")
+	} else if r.dayOfMonthLast {
+		dayParts = append(dayParts, "on the last day of the month")
+	}
+	if days := r.DaysOfWeek(); len(days) > 0 {
+		var names []string
+		for _, d := range days {
+			names = append(names, time.Weekday(d).String())
+		}
+		if r.dayOfWeekLast {
+			dayParts = append(dayParts, "on the last "+joinAnd(names)+" of the month")
+		} else if r.dayOfWeekNth > 0 {
+			dayParts = append(dayParts, "on the "+ordinal(r.dayOfWeekNth)+" "+joinAnd(names)+" of the month")
+		} else {
+			dayParts = append(dayParts, "on "+joinAnd(names))
+		}
+	}
+	if r.eitherDay && len(dayParts) == 2 {
+		// rules from ParseKubernetes fire when either day field matches
+		parts = append(parts, dayParts[0]+" or "+dayParts[1])
+	} else {
+		parts = append(parts, dayParts...)
+	}
+	if months := r.Months(); len(months) > 0 {
+		var names []string
+		for _, m := range months {
+			names = append(names, time.Month(m).String())
+		}
+		parts = append(parts, "in "+joinAnd(names))
+	}
+	if len(r.isoWeeks) > 0 || len(r.weeksOfMonth) > 0 || r.calendar != nil || r.businessDay != 0 {
+		parts = append(parts, "with further date restrictions")
+	}
+	if r.location != nil {
+		parts = append(parts, "in "+r.location.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// plural returns the singular or plural form of a noun for the given values.
+func plural(noun string, values FieldValues) string {
+	if len(values) == 1 {
+		return noun
+	}
+	return noun + "s"
+}
+
+// numbers renders each of the values as a number.
+func numbers(values FieldValues) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = strconv.Itoa(v)
+	}
+	return out
+}
+
+// joinAnd joins the items as an English list, such as "a, b and c".
+func joinAnd(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
+
+// ordinal renders 1 to 5 as "1st" to "5th".
+func ordinal(n int) string {
+	switch n {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	}
+	return strconv.Itoa(n) + "th"
+}
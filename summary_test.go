@@ -0,0 +1,61 @@
+package ticktickrules
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDescribe(t *testing.T) {
+	cases := map[string]string{
+		"* * * * *":                 "every minute",
+		"* 9/10 * * *":              "every minute during hours 9 and 10",
+		"0/30 * * * *":              "at minutes 0 and 30 of every hour",
+		"30 9 * * 1/2/3/4/5":        "at 09:30 on Monday, Tuesday, Wednesday, Thursday and Friday",
+		"0 9/17 1 JAN/JUL *":        "at 09:00 and 17:00 on day 1 of the month in January and July",
+		"*/15 */2 * * *":            "at minutes 0, 15, 30 and 45 of hours 0, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20 and 22",
+		"0 0 * * 5#3":               "at 00:00 on the 3rd Friday of the month",
+		"CRON_TZ=UTC 0 12 1/15 * *": "at 12:00 on days 1 and 15 of the month in UTC",
+	}
+	for expr, e := range cases {
+		if s := MustParseRule(expr).Describe(); s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+	}
+	if s := mustRule(MustParseRule("0 0 * * *").WithISOWeeks(1)).Describe(); s != "at 00:00 with further date restrictions" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+
+	k, err := ParseKubernetes("0 0 13 * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := k.Describe(); s != "at 00:00 on day 13 of the month or on Friday" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	if s := MustParseRule("0 0 13 * 5").Describe(); s != "at 00:00 on day 13 of the month on Friday" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	from := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s := MustParseRule("0 12 * * 1").SummaryAt(from)
+	if s.Expression != "0 12 * * 1" || !s.Satisfiable || s.Description != "at 12:00 on Monday" {
+		t.Errorf("%+v Did not match!", s)
+	}
+	if len(s.NextTimes) != 5 || !s.NextTimes[0].Equal(time.Date(2026, time.January, 5, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("%v Did not match!", s.NextTimes)
+	}
+	if len(s.Fields) != 5 || s.Fields[4].Name != "Day of Week" || s.Fields[4].Values.String() != "1" || s.Fields[0].Rule != "0" {
+		t.Errorf("%+v Did not match!", s.Fields)
+	}
+	if _, err := json.Marshal(s); err != nil {
+		t.Error(err)
+	}
+
+	s = MustParseRule("0 0 30 2 *").SummaryAt(from)
+	if s.Satisfiable || len(s.NextTimes) != 0 {
+		t.Errorf("%+v Did not match!", s)
+	}
+}
@@ -0,0 +1,260 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// systemdShorthands maps the systemd calendar shorthands onto their normalized form.
+var systemdShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// ParseOnCalendar parses a systemd calendar expression such as "Mon..Fri *-*-* 09:00:00" as used by the
+// OnCalendar setting of timer units. The day of week, date, and time may each be omitted, and a trailing time zone
+// such as "UTC" or "Europe/London" binds the rule to that location. The shorthands such as "daily" and "weekly" are
+// also accepted.
+//
+// Only expressions that can be represented by a Rule are supported, so the year must be "*", the seconds must be
+// 0 or "*", and the "~" last day of month syntax is not allowed. Seconds of "*" fire every second and are parsed as
+// SecondGranularity.
+func ParseOnCalendar(expr string) (*Rule, error) {
+	parts := strings.Fields(expr)
+	if len(parts) > 0 {
+		if s, ok := systemdShorthands[strings.ToLower(parts[0])]; ok {
+			parts = append(strings.Fields(s), parts[1:]...)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("Expression '%s' is empty", expr)
+	}
+
+	dow, date, clock := "*", "*-*-*", "00:00:00"
+	var loc *time.Location
+	if p := parts[0]; unicode.IsLetter(rune(p[0])) && !strings.Contains(p, "/") {
+		dow = p
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && strings.Contains(parts[0], "-") {
+		date = parts[0]
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && strings.Contains(parts[0], ":") {
+		clock = parts[0]
+		parts = parts[1:]
+	}
+	if len(parts) == 1 {
+		l, err := time.LoadLocation(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("Expression '%s' has invalid time zone: %s", expr, err.Error())
+		}
+		loc = l
+	} else if len(parts) > 1 {
+		return nil, fmt.Errorf("Expression '%s' has unexpected component '%s'", expr, parts[0])
+	}
+
+	dateParts := strings.Split(date, "-")
+	if len(dateParts) == 2 {
+		dateParts = append([]string{"*"}, dateParts...)
+	}
+	if len(dateParts) != 3 {
+		return nil, fmt.Errorf("Expression '%s' has invalid date '%s'", expr, date)
+	}
+	if dateParts[0] != "*" {
+		return nil, fmt.Errorf("Expression '%s' must have * as the year", expr)
+	}
+	clockParts := strings.Split(clock, ":")
+	granularity := MinuteGranularity
+	if len(clockParts) == 3 {
+		if s := clockParts[2]; s == "*" {
+			granularity = SecondGranularity
+		} else if s != "0" && s != "00" {
+			return nil, fmt.Errorf("Expression '%s' must have 00 or * as the seconds", expr)
+		}
+		clockParts = clockParts[:2]
+	}
+	if len(clockParts) != 2 {
+		return nil, fmt.Errorf("Expression '%s' has invalid time '%s'", expr, clock)
+	}
+
+	items := []string{clockParts[1], clockParts[0], dateParts[2], dateParts[1], dow}
+	for i, f := range ruleFields {
+		values, err := systemdValues(items[i], f)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = normalizeField(values, f.min, f.max).ruleItem()
+	}
+	r, err := NewRule(items[0], items[1], items[2], items[3], items[4])
+	if err != nil {
+		return nil, err
+	}
+	r.location = loc
+	return r.WithGranularity(granularity), nil
+}
+
+// systemdValues expands one systemd calendar component, which may be a comma separated list of values, ranges
+// written as "a..b", and repetitions written as "a/n".
+func systemdValues(item string, f field) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(item, ",") {
+		base, step := part, 0
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, &SyntaxError{Item: item, Reason: "has an invalid repetition"}
+			}
+			base, step = part[:i], s
+		}
+
+		lo, hi := f.min, f.max
+		if i := strings.Index(base, ".."); i >= 0 {
+			var err error
+			if lo, err = systemdValue(base[:i], item, f); err != nil {
+				return nil, err
+			}
+			if hi, err = systemdValue(base[i+2:], item, f); err != nil {
+				return nil, err
+			}
+			// systemd weeks start on Monday, so Sat..Sun and the like wrap around
+			if hi < lo && f.name == dayOfWeekField.name {
+				hi += 7
+			}
+			if hi < lo {
+				return nil, &SyntaxError{Item: item, Reason: "has a range that wraps around"}
+			}
+		} else if base != "*" {
+			v, err := systemdValue(base, item, f)
+			if err != nil {
+				return nil, err
+			}
+			lo, hi = v, v
+			if step > 0 {
+				hi = f.max
+			}
+		}
+		if step == 0 {
+			step = 1
+		}
+		for v := lo; v <= hi; v += step {
+			out = append(out, v%(f.max+1))
+		}
+	}
+	return out, nil
+}
+
+// systemdValue parses a single number, or for days of the week an English day name in any case.
+func systemdValue(s, item string, f field) (int, error) {
+	if f.name == dayOfWeekField.name {
+		if len(s) >= 3 {
+			prefix := strings.ToUpper(s[:3])
+			for i, n := range dayOfWeekNames[:7] {
+				if prefix == n {
+					return i, nil
+				}
+			}
+		}
+		return 0, &SyntaxError{Item: item, Reason: "has an unknown day name"}
+	}
+	return parseValue(s, item, f, f.min, f.max)
+}
+
+// OnCalendar renders the rule as a systemd calendar expression, for example "0 9 * * 1/2/3/4/5" becomes
+// "Mon..Fri *-*-* 09:00:00". Rules bound to a location have the zone name appended, and rules with
+// SecondGranularity have "*" as the seconds.
+//
+// An error is returned for a "N#K" or "NL" day of week, and for rules that fire when either the day of month or the
+// day of week matches, such as those from ParseKubernetes, since systemd requires both to match. The calendar and
+// other restrictions are dropped.
+func (r *Rule) OnCalendar() (string, error) {
+	if r.hasOccurrence() {
+		return "", fmt.Errorf("Rule '%s' cannot be expressed as a systemd calendar expression", r)
+	}
+	if r.eitherDay && r.restrictsBothDays() {
+		return "", fmt.Errorf("Rule '%s' fires when either day field matches, which systemd cannot express", r)
+	}
+	out := ""
+	if days := r.DaysOfWeek(); len(days) > 0 {
+		// systemd weeks start on Monday so Sunday is listed last
+		monday := make([]int, len(days))
+		for i, d := range days {
+			monday[i] = (d + 6) % 7
+		}
+		out = systemdField(normalizeField(monday, 0, 6), 0, 6, func(v int) string {
+			n := dayOfWeekNames[(v+1)%7]
+			return n[:1] + strings.ToLower(n[1:])
+		}, false) + " "
+	}
+	// "~" counts the days back from the end of the month, where "~01" is the last day
+	days := "-" + systemdField(r.DaysOfMonth(), dayOfMonthField.min, dayOfMonthField.max, pad2, true)
+	if r.dayOfMonthLast {
+		days = "~" + pad2(r.dayOfMonthOffset+1)
+	}
+	seconds := "00"
+	if r.granularity == SecondGranularity {
+		seconds = "*"
+	}
+	out += fmt.Sprintf("*-%s%s %s:%s:%s",
+		systemdField(r.Months(), monthField.min, monthField.max, pad2, true),
+		days,
+		systemdField(r.Hours(), hourField.min, hourField.max, pad2, true),
+		systemdField(r.Minutes(), minuteField.min, minuteField.max, pad2, true),
+		seconds,
+	)
+	if r.location != nil {
+		out += " " + r.location.String()
+	}
+	return out, nil
+}
+
+// pad2 renders a number with at least two digits.
+func pad2(v int) string {
+	return fmt.Sprintf("%02d", v)
+}
+
+// systemdField renders normalized values as a systemd calendar component, using repetitions where allowed and
+// ".." ranges for runs of three or more values.
+func systemdField(values FieldValues, min, max int, render func(int) string, repeat bool) string {
+	if len(values) == 0 {
+		return "*"
+	}
+
+	if repeat && len(values) >= 3 {
+		step := values[1] - values[0]
+		even := step > 1
+		for i := 2; i < len(values) && even; i++ {
+			even = values[i]-values[i-1] == step
+		}
+		if even && values[len(values)-1]+step > max {
+			return render(values[0]) + "/" + strconv.Itoa(step)
+		}
+	}
+
+	var parts []string
+	for i := 0; i < len(values); {
+		j := i
+		for j+1 < len(values) && values[j+1] == values[j]+1 {
+			j++
+		}
+		if j-i >= 2 {
+			parts = append(parts, render(values[i])+".."+render(values[j]))
+		} else {
+			for k := i; k <= j; k++ {
+				parts = append(parts, render(values[k]))
+			}
+		}
+		i = j + 1
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,101 @@
+package ticktickrules
+
+import (
+	"testing"
+)
+
+func TestParseOnCalendar(t *testing.T) {
+	cases := map[string]string{
+		"Mon..Fri *-*-* 09:00:00":   "0 9 * * 1,2,3,4,5",
+		"Sat,Sun 10:30":             "30 10 * * 0,6",
+		"Fri..Mon *-*-* 00:00":      "0 0 * * 0,1,5,6",
+		"*-*-01 00:00:00":           "0 0 1 * *",
+		"*-1,7-1 12:00":             "0 12 1 1,7 *",
+		"*-*-1..5 *:0/15":           "0,15,30,45 * 1,2,3,4,5 * *",
+		"*:*":                       "* * * * *",
+		"daily":                     "0 0 * * *",
+		"weekly":                    "0 0 * * 1",
+		"quarterly":                 "0 0 1 1,4,7,10 *",
+		"hourly UTC":                "CRON_TZ=UTC 0 * * * *",
+		"monday *-12-25 06:00 UTC":  "CRON_TZ=UTC 0 6 25 12 1",
+		"*-*-* 08/4:00":             "0 8,12,16,20 * * *",
+		"Thu 2026-*-* 00:00:00":     "",
+		"*-*-* 00:00:30":            "",
+		"*-*~1 00:00":               "",
+		"Funday *-*-* 00:00":        "",
+		"*-*-* 25:00":               "",
+		"*-*-* 00:00 Nowhere/Nohow": "",
+		"*-*-* 00:00:00 UTC extra":  "",
+		"":                          "",
+	}
+	for expr, e := range cases {
+		r, err := ParseOnCalendar(expr)
+		if e == "" {
+			if err == nil {
+				t.Errorf("%s: expected an error but got '%s'", expr, r.StringNormalized())
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+		} else if s := r.StringNormalized(); s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+	}
+}
+
+func TestOnCalendar(t *testing.T) {
+	cases := map[string]string{
+		"0 9 * * 1/2/3/4/5":               "Mon..Fri *-*-* 09:00:00",
+		"30 10 * * 0/6":                   "Sat,Sun *-*-* 10:30:00",
+		"*/15 * 1 JAN/JUL *":              "*-01,07-01 *:00/15:00",
+		"0 8/12/16/20 * * *":              "*-*-* 08/4:00:00",
+		"CRON_TZ=Europe/London 0 0 * * *": "*-*-* 00:00:00 Europe/London",
+	}
+	for expr, e := range cases {
+		r := MustParseRule(expr)
+		s, err := r.OnCalendar()
+		if err != nil {
+			t.Errorf("%s: %s", expr, err)
+			continue
+		}
+		if s != e {
+			t.Errorf("%s: '%s' Did not match! '%s'", expr, s, e)
+		}
+		back, err := ParseOnCalendar(s)
+		if err != nil {
+			t.Errorf("%s: %s", s, err)
+		} else if back.StringNormalized() != r.StringNormalized() {
+			t.Errorf("%s: '%s' Did not match! '%s'", s, back.StringNormalized(), r.StringNormalized())
+		}
+	}
+
+	if s, _ := MustParseRule("0 9 L-3 * *").OnCalendar(); s != "*-*~04 09:00:00" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+	secs := MustParseRule("30 9 * * *").WithGranularity(SecondGranularity)
+	if s, err := secs.OnCalendar(); err != nil {
+		t.Error(err)
+	} else if s != "*-*-* 09:30:*" {
+		t.Errorf("'%s' Did not match!", s)
+	} else if back, err := ParseOnCalendar(s); err != nil {
+		t.Error(err)
+	} else if back.Fingerprint() != secs.Fingerprint() {
+		t.Errorf("%s did not round trip through '%s'", secs, s)
+	}
+	if _, err := MustParseRule("0 0 * * 1#2").OnCalendar(); err == nil {
+		t.Error("expected an error for an nth day of week")
+	}
+	k, err := ParseKubernetes("0 0 13 * 5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := k.OnCalendar(); err == nil {
+		t.Errorf("expected an error when either day matches, got '%s'", s)
+	}
+	if s, err := MustParseRule("0 0 13 * 5").OnCalendar(); err != nil {
+		t.Error(err)
+	} else if s != "Fri *-*-13 00:00:00" {
+		t.Errorf("'%s' Did not match!", s)
+	}
+}
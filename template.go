@@ -0,0 +1,95 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// templateValueFunc is the function ParseTemplate appends to the pipeline of every action in a template, so that
+// each substituted value is checked before it reaches the expression.
+const templateValueFunc = "ticktickValue"
+
+// ParseTemplate substitutes data into a text/template of an expression, such as "0 {{.Hour}} * * {{.Weekday}}",
+// and parses the result with ParseRule. Every substituted value must be a single item made of digits, names, and
+// the "*,-/#?" characters, so a value cannot add fields, comments, or a time zone prefix of its own. This gives
+// systems that generate schedules from user data one safe way to do so, rather than building expressions with
+// fmt.Sprintf.
+func ParseTemplate(text string, data interface{}, opts ...ParseOption) (*Rule, error) {
+	t, err := template.New("rule").Funcs(template.FuncMap{templateValueFunc: templateValue}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("Template '%s' could not be parsed: %s", text, err.Error())
+	}
+	for _, tt := range t.Templates() {
+		if tt.Tree != nil {
+			checkActions(tt.Tree.Root)
+		}
+	}
+
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return nil, fmt.Errorf("Template '%s' could not be executed: %s", text, err.Error())
+	}
+	return ParseRule(sb.String(), opts...)
+}
+
+// MustParseTemplate is like ParseTemplate but panics if there is an error.
+func MustParseTemplate(text string, data interface{}, opts ...ParseOption) *Rule {
+	r, err := ParseTemplate(text, data, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// checkActions appends templateValueFunc to every action under node that writes to the output.
+func checkActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			checkActions(c)
+		}
+	case *parse.ActionNode:
+		// actions declaring variables do not write anything
+		if len(n.Pipe.Decl) == 0 {
+			ident := parse.NewIdentifier(templateValueFunc).SetTree(nil).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: n.Pos, Args: []parse.Node{ident}})
+		}
+	case *parse.IfNode:
+		checkActions(n.List)
+		checkActions(n.ElseList)
+	case *parse.RangeNode:
+		checkActions(n.List)
+		checkActions(n.ElseList)
+	case *parse.WithNode:
+		checkActions(n.List)
+		checkActions(n.ElseList)
+	}
+}
+
+// templateValue formats a value substituted into a template, returning an error if it is not a single item.
+func templateValue(v interface{}) (string, error) {
+	s := fmt.Sprint(v)
+	if s == "" {
+		return "", fmt.Errorf("value is empty")
+	}
+	for _, c := range s {
+		if !isTemplateValueRune(c) {
+			return "", fmt.Errorf("value '%s' contains %q, which is not allowed in an item", s, c)
+		}
+	}
+	return s, nil
+}
+
+// isTemplateValueRune returns whether c may appear in a substituted value.
+func isTemplateValueRune(c rune) bool {
+	switch {
+	case c >= '0' && c <= '9', c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z':
+		return true
+	}
+	return strings.ContainsRune("*,-/#?", c)
+}
@@ -0,0 +1,63 @@
+package ticktickrules
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	type tenant struct {
+		Hour    int
+		Weekday string
+		Days    []int
+	}
+	cases := []struct {
+		text     string
+		data     interface{}
+		expected string
+	}{
+		{"0 {{.Hour}} * * {{.Weekday}}", tenant{Hour: 9, Weekday: "MON-FRI"}, "0 9 * * MON-FRI"},
+		{"30 {{.Hour}} {{range $i, $d := .Days}}{{if $i}},{{end}}{{$d}}{{end}} * *", tenant{Hour: 2, Days: []int{1, 15}}, "30 2 1,15 * *"},
+		{"{{.minute}} {{.hour}} * * *", map[string]interface{}{"minute": "*/5", "hour": 7}, "*/5 7 * * *"},
+		{"{{with .Weekday}}0 0 * * {{.}}{{else}}0 0 1 * *{{end}}", tenant{}, "0 0 1 * *"},
+	}
+	for _, c := range cases {
+		r, err := ParseTemplate(c.text, c.data)
+		if err != nil {
+			t.Errorf("%s: %v", c.text, err)
+		} else if r.String() != c.expected {
+			t.Errorf("'%s' Did not match! '%s'", r.String(), c.expected)
+		}
+	}
+
+	if r := MustParseTemplate("0 {{.}} * * *", 3, QuartzSteps()); r.String() != "0 3 * * *" {
+		t.Errorf("'%s' Did not match!", r.String())
+	}
+}
+
+func TestParseTemplateInjection(t *testing.T) {
+	cases := []struct {
+		text    string
+		data    interface{}
+		message string
+	}{
+		// values cannot add fields, comments, or a time zone of their own
+		{"0 {{.}} * * *", "9 * * * *", "not allowed"},
+		{"{{.}} 0 9 * * *", "CRON_TZ=Europe/London", "not allowed"},
+		{"0 9 * * {{.}}", "1 # comment", "not allowed"},
+		{"0 9 * * {{.}}", "", "empty"},
+		{"0 9 * * {{printf \"%s\" .}}", "1\n", "not allowed"},
+		// values are checked, but the result must still be a valid rule
+		{"0 {{.}} * * *", 24, "outside of 0-23"},
+		{"0 {{.Missing}} * * *", struct{}{}, "could not be executed"},
+		{"0 {{.Hour * * *", nil, "could not be parsed"},
+	}
+	for _, c := range cases {
+		_, err := ParseTemplate(c.text, c.data, AllowComments())
+		if err == nil {
+			t.Errorf("%s with %v should have failed", c.text, c.data)
+		} else if !strings.Contains(err.Error(), c.message) {
+			t.Errorf("%s: '%s' Did not match! '%s'", c.text, err, c.message)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package ticktickrules
+
+import (
+	"container/heap"
+	"sort"
+	"time"
+)
+
+// TimelineEvent is an instant at which one or more of the rules in a Timeline fire.
+type TimelineEvent struct {
+	Time time.Time
+	// Rules are the indexes of the rules firing at Time, in ascending order.
+	Rules []int
+}
+
+// Timeline produces the occurrences of many rules as a single ordered stream, so that a dispatcher can drive all of
+// them from one goroutine. It is not safe for concurrent use.
+type Timeline struct {
+	rules []*Rule
+	queue timelineQueue
+}
+
+// MergeTimelines returns a Timeline of the occurrences of the rules after from. Rules firing at the same instant
+// are reported together in a single event, so each instant is only produced once.
+func MergeTimelines(from time.Time, rules ...*Rule) *Timeline {
+	t := &Timeline{rules: rules}
+	for i, r := range rules {
+		if n := r.NextAfter(from); !n.Equal(farFuture) {
+			t.queue = append(t.queue, timelineItem{n, i})
+		}
+	}
+	heap.Init(&t.queue)
+	return t
+}
+
+// Next returns the next event in the timeline. Every event is strictly later than the one before it. False is
+// returned once none of the rules will fire again.
+func (t *Timeline) Next() (TimelineEvent, bool) {
+	if len(t.queue) == 0 {
+		return TimelineEvent{}, false
+	}
+	out := TimelineEvent{Time: t.queue[0].next}
+	for len(t.queue) > 0 && t.queue[0].next.Equal(out.Time) {
+		item := heap.Pop(&t.queue).(timelineItem)
+		out.Rules = append(out.Rules, item.index)
+	}
+	sort.Ints(out.Rules)
+	for _, i := range out.Rules {
+		if n := t.rules[i].NextAfter(out.Time); !n.Equal(farFuture) {
+			heap.Push(&t.queue, timelineItem{n, i})
+		}
+	}
+	return out, true
+}
+
+// timelineItem is the next occurrence of one of the rules of a Timeline.
+type timelineItem struct {
+	next  time.Time
+	index int
+}
+
+// timelineQueue is a heap of the next occurrence of each rule, earliest first.
+type timelineQueue []timelineItem
+
+func (q timelineQueue) Len() int { return len(q) }
+
+func (q timelineQueue) Less(i, j int) bool {
+	if q[i].next.Equal(q[j].next) {
+		return q[i].index < q[j].index
+	}
+	return q[i].next.Before(q[j].next)
+}
+
+func (q timelineQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *timelineQueue) Push(x interface{}) { *q = append(*q, x.(timelineItem)) }
+
+func (q *timelineQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,52 @@
+package ticktickrules
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMergeTimelines(t *testing.T) {
+	from := time.Date(2026, time.March, 4, 0, 0, 0, 0, time.UTC)
+	tl := MergeTimelines(from,
+		MustParseRule("*/20 * * * *"),
+		MustParseRule("0/30 * * * *"),
+		MustParseRule("0 0 30 2 *"),
+		MustParseRule("20 0 * * *"),
+	)
+	expected := []string{
+		"00:20 [0 3]",
+		"00:30 [1]",
+		"00:40 [0]",
+		"01:00 [0 1]",
+		"01:20 [0]",
+	}
+	for i, e := range expected {
+		ev, ok := tl.Next()
+		if !ok {
+			t.Fatalf("%d) timeline ended early", i)
+		}
+		if s := fmt.Sprintf("%s %v", ev.Time.Format("15:04"), ev.Rules); s != e {
+			t.Errorf("%d) '%s' Did not match! '%s'", i, s, e)
+		}
+	}
+
+	var last time.Time
+	for i := 0; i < 1000; i++ {
+		ev, _ := tl.Next()
+		if !ev.Time.After(last) {
+			t.Fatalf("%s is not after %s", ev.Time, last)
+		}
+		last = ev.Time
+	}
+}
+
+func TestMergeTimelinesEmpty(t *testing.T) {
+	tl := MergeTimelines(time.Now(), MustParseRule("0 0 30 2 *"))
+	if _, ok := tl.Next(); ok {
+		t.Error("expected no events")
+	}
+	if _, ok := MergeTimelines(time.Now()).Next(); ok {
+		t.Error("expected no events")
+	}
+}
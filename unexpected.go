@@ -0,0 +1,39 @@
+package ticktickrules
+
+import (
+	"sort"
+	"time"
+)
+
+// Unexpected audits the times a job actually ran against the rule. It returns the events that do not correspond
+// to any occurrence, and the occurrences that have no event, both earliest first. An event corresponds to an
+// occurrence if it is within tolerance of it either side, and each event accounts for at most one occurrence.
+//
+// Only occurrences from tolerance before the earliest event up to the latest event are considered, since the
+// history says nothing about the times around it.
+func (r *Rule) Unexpected(events []time.Time, tolerance time.Duration) ([]time.Time, []time.Time) {
+	if len(events) == 0 {
+		return nil, nil
+	}
+	sorted := make([]time.Time, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Before(sorted[j])
+	})
+	last := sorted[len(sorted)-1]
+
+	var unexpected, missing []time.Time
+	i := 0
+	for o := r.Ceil(sorted[0].Add(-tolerance)); !o.After(last); o = r.NextAfter(o) {
+		for i < len(sorted) && sorted[i].Before(o.Add(-tolerance)) {
+			unexpected = append(unexpected, sorted[i])
+			i++
+		}
+		if i < len(sorted) && !sorted[i].After(o.Add(tolerance)) {
+			i++
+		} else {
+			missing = append(missing, o)
+		}
+	}
+	return append(unexpected, sorted[i:]...), missing
+}
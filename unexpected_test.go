@@ -0,0 +1,53 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnexpected(t *testing.T) {
+	r := MustParseRule("0 * * * *")
+	at := func(h, m, s int) time.Time {
+		return time.Date(2026, time.March, 4, h, m, s, 0, time.UTC)
+	}
+	events := []time.Time{
+		at(3, 0, 40),
+		at(1, 0, 5),
+		at(2, 30, 0),
+		at(5, 1, 0),
+		at(5, 2, 0),
+		at(6, 0, 0),
+	}
+	unexpected, missing := r.Unexpected(events, 2*time.Minute)
+
+	expected := []time.Time{at(2, 30, 0), at(5, 2, 0)}
+	if len(unexpected) != len(expected) {
+		t.Fatalf("%v Did not match! %v", unexpected, expected)
+	}
+	for i := range expected {
+		if !unexpected[i].Equal(expected[i]) {
+			t.Errorf("%d) %s != %s", i, unexpected[i], expected[i])
+		}
+	}
+
+	expected = []time.Time{at(2, 0, 0), at(4, 0, 0)}
+	if len(missing) != len(expected) {
+		t.Fatalf("%v Did not match! %v", missing, expected)
+	}
+	for i := range expected {
+		if !missing[i].Equal(expected[i]) {
+			t.Errorf("%d) %s != %s", i, missing[i], expected[i])
+		}
+	}
+
+	// events before their occurrence but within the tolerance count
+	unexpected, missing = r.Unexpected([]time.Time{at(0, 59, 30), at(2, 0, 0)}, time.Minute)
+	if len(unexpected) != 0 || len(missing) != 0 {
+		t.Errorf("%v %v Did not match!", unexpected, missing)
+	}
+
+	unexpected, missing = r.Unexpected(nil, time.Minute)
+	if unexpected != nil || missing != nil {
+		t.Errorf("%v %v Did not match!", unexpected, missing)
+	}
+}
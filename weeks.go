@@ -0,0 +1,68 @@
+package ticktickrules
+
+import (
+	"fmt"
+)
+
+// WithISOWeeks returns a copy of the rule that only fires during the given ISO 8601 week numbers (1-53), for
+// example WithISOWeeks(EvenWeeks()...) for a fortnightly job. Calling it with no weeks removes the restriction.
+// Like the calendar, the restriction is included in the Fingerprint but not the String form of the rule. An error is
+// returned if any of the weeks are out of range.
+func (r *Rule) WithISOWeeks(weeks ...int) (*Rule, error) {
+	if err := checkWeeks("ISO week", weeks, 1, 53); err != nil {
+		return nil, err
+	}
+	out := *r
+	out.isoWeeks = normalizeField(weeks, 1, 53)
+	return &out, nil
+}
+
+// WithWeeksOfMonth returns a copy of the rule that only fires during the given weeks of the month (1-5), where
+// days 1-7 are the first week, days 8-14 the second, and so on. Calling it with no weeks removes the restriction. An
+// error is returned if any of the weeks are out of range.
+func (r *Rule) WithWeeksOfMonth(weeks ...int) (*Rule, error) {
+	if err := checkWeeks("Week of the month", weeks, 1, 5); err != nil {
+		return nil, err
+	}
+	out := *r
+	out.weeksOfMonth = normalizeField(weeks, 1, 5)
+	return &out, nil
+}
+
+// checkWeeks returns an error for the first week outside of min-max.
+func checkWeeks(name string, weeks []int, min, max int) error {
+	for _, w := range weeks {
+		if w < min || w > max {
+			return fmt.Errorf("%s %d is out of range %d-%d", name, w, min, max)
+		}
+	}
+	return nil
+}
+
+// ISOWeeks returns the ISO week numbers the rule is restricted to, or "*" if it is not restricted.
+func (r *Rule) ISOWeeks() FieldValues {
+	return r.isoWeeks
+}
+
+// WeeksOfMonth returns the weeks of the month the rule is restricted to, or "*" if it is not restricted.
+func (r *Rule) WeeksOfMonth() FieldValues {
+	return r.weeksOfMonth
+}
+
+// EvenWeeks returns the even ISO week numbers.
+func EvenWeeks() []int {
+	return weekNumbers(2)
+}
+
+// OddWeeks returns the odd ISO week numbers.
+func OddWeeks() []int {
+	return weekNumbers(1)
+}
+
+func weekNumbers(start int) []int {
+	var out []int
+	for w := start; w <= 53; w += 2 {
+		out = append(out, w)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+// mustRule returns the rule from a method that validates its arguments, panicking on an error.
+func mustRule(r *Rule, err error) *Rule {
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+func TestWithISOWeeks(t *testing.T) {
+	// 2000-04-28 is a Friday in ISO week 17
+	r := mustRule(MustParseRule("0 9 * * 5").WithISOWeeks(EvenWeeks()...))
+	from := time.Date(2000, 4, 28, 10, 0, 0, 0, time.UTC)
+
+	n := r.NextAfter(from)
+	e := time.Date(2000, 5, 5, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("1) %s != %s", n, e)
+	}
+	n = r.NextAfter(n)
+	e = time.Date(2000, 5, 19, 9, 0, 0, 0, time.UTC)
+	if n != e {
+		t.Errorf("2) %s != %s", n, e)
+	}
+	if r.Matches(time.Date(2000, 5, 12, 9, 0, 0, 0, time.UTC)) {
+		t.Error("Odd week should not match")
+	}
+	if r.ISOWeeks().String() == "*" {
+		t.Error("ISO weeks should be restricted")
+	}
+	if mustRule(r.WithISOWeeks()).ISOWeeks().String() != "*" {
+		t.Error("ISO weeks should not be restricted")
+	}
+}
+
+func TestWithWeeksOfMonth(t *testing.T) {
+	// first and third week of the month
+	r := mustRule(MustParseRule("0 9 * * 2").WithWeeksOfMonth(1, 3))
+	from := time.Date(2000, 4, 28, 10, 0, 0, 0, time.UTC)
+
+	expected := []time.Time{
+		time.Date(2000, 5, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2000, 5, 16, 9, 0, 0, 0, time.UTC),
+		time.Date(2000, 6, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(2000, 6, 20, 9, 0, 0, 0, time.UTC),
+	}
+	for i, e := range expected {
+		from = r.NextAfter(from)
+		if from != e {
+			t.Errorf("%d) %s != %s", i, from, e)
+		}
+	}
+}
+
+func TestWeeksOutOfRange(t *testing.T) {
+	r := MustParseRule("0 9 * * *")
+	for _, w := range []int{0, 54, -1} {
+		if _, err := r.WithISOWeeks(1, w); err == nil {
+			t.Errorf("ISO week %d should have been rejected", w)
+		}
+	}
+	for _, w := range []int{0, 6} {
+		if _, err := r.WithWeeksOfMonth(w); err == nil {
+			t.Errorf("week of the month %d should have been rejected", w)
+		}
+	}
+	if _, err := r.WithISOWeeks(1, 53); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,110 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// TimeRange is the half open interval [Start, End).
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains returns whether t is within the range.
+func (tr TimeRange) Contains(t time.Time) bool {
+	return !t.Before(tr.Start) && t.Before(tr.End)
+}
+
+// Duration returns the length of the range.
+func (tr TimeRange) Duration() time.Duration {
+	return tr.End.Sub(tr.Start)
+}
+
+// WindowsBetween coalesces the consecutive minutes matched by the rule between start and end into ranges. This
+// turns a wide rule such as "* 2/3 * * 6" into the maintenance windows it describes. Ranges are clipped to start
+// and end.
+func (r *Rule) WindowsBetween(start, end time.Time) []TimeRange {
+	var out []TimeRange
+	from := start
+	if !r.Matches(start) {
+		from = r.NextAfter(start)
+	}
+	for from.Before(end) {
+		to := r.NextNonMatch(from)
+		if to.After(end) {
+			to = end
+		}
+		out = append(out, TimeRange{Start: from, End: to})
+		from = r.NextAfter(to)
+	}
+	return out
+}
+
+// Window is a rule whose occurrences each last for a duration, such as a maintenance window starting at 02:00
+// every Saturday and lasting two hours.
+type Window struct {
+	Rule     *Rule
+	Duration time.Duration
+}
+
+// Current returns the window containing t, if any. If windows overlap, the one that started most recently is
+// returned.
+func (w Window) Current(t time.Time) (TimeRange, bool) {
+	start := w.Rule.Floor(t)
+	if start == farPast || !t.Before(start.Add(w.Duration)) {
+		return TimeRange{}, false
+	}
+	return TimeRange{Start: start, End: start.Add(w.Duration)}, true
+}
+
+// Contains returns whether t is inside one of the windows.
+func (w Window) Contains(t time.Time) bool {
+	_, ok := w.Current(t)
+	return ok
+}
+
+// NextWindow returns the next window starting after t.
+func (w Window) NextWindow(t time.Time) TimeRange {
+	start := w.Rule.NextAfter(t)
+	return TimeRange{Start: start, End: start.Add(w.Duration)}
+}
+
+// OverlapsRange returns whether any of the windows overlap the given range.
+func (w Window) OverlapsRange(tr TimeRange) bool {
+	start := w.Rule.Floor(tr.End.Add(-time.Nanosecond))
+	return start != farPast && start.Add(w.Duration).After(tr.Start)
+}
+
+// NextOverlap returns the first time range after from during which both w and o are open, or false if none could
+// be found.
+func (w Window) NextOverlap(o Window, from time.Time) (TimeRange, bool) {
+	// start with any windows that are still open at from
+	x := w.Rule.NextAfter(from.Add(-w.Duration))
+	y := o.Rule.NextAfter(from.Add(-o.Duration))
+	for numIterations := 0; numIterations < combineMaxIterations; numIterations++ {
+		if x == farFuture || y == farFuture {
+			break
+		}
+		xEnd, yEnd := x.Add(w.Duration), y.Add(o.Duration)
+		if !xEnd.After(y) {
+			x = w.Rule.NextAfter(y.Add(-w.Duration))
+			continue
+		}
+		if !yEnd.After(x) {
+			y = o.Rule.NextAfter(x.Add(-o.Duration))
+			continue
+		}
+		out := TimeRange{Start: x, End: xEnd}
+		if y.After(out.Start) {
+			out.Start = y
+		}
+		if yEnd.Before(out.End) {
+			out.End = yEnd
+		}
+		if out.Start.Before(from) {
+			out.Start = from
+		}
+		return out, true
+	}
+	return TimeRange{}, false
+}
@@ -0,0 +1,97 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindowsBetween(t *testing.T) {
+	r := MustParseRule("* 2/3 * * 6")
+	windows := r.WindowsBetween(
+		time.Date(2000, 4, 22, 2, 30, 0, 0, time.UTC),
+		time.Date(2000, 5, 7, 0, 0, 0, 0, time.UTC),
+	)
+	expected := []TimeRange{
+		{time.Date(2000, 4, 22, 2, 30, 0, 0, time.UTC), time.Date(2000, 4, 22, 4, 0, 0, 0, time.UTC)},
+		{time.Date(2000, 4, 29, 2, 0, 0, 0, time.UTC), time.Date(2000, 4, 29, 4, 0, 0, 0, time.UTC)},
+		{time.Date(2000, 5, 6, 2, 0, 0, 0, time.UTC), time.Date(2000, 5, 6, 4, 0, 0, 0, time.UTC)},
+	}
+	if len(windows) != len(expected) {
+		t.Errorf("%d != %d", len(windows), len(expected))
+		return
+	}
+	for i, e := range expected {
+		if windows[i] != e {
+			t.Errorf("%d) %v != %v", i, windows[i], e)
+		}
+	}
+	if windows[1].Duration() != 2*time.Hour {
+		t.Errorf("%s != 2h", windows[1].Duration())
+	}
+	if !windows[1].Contains(time.Date(2000, 4, 29, 3, 59, 0, 0, time.UTC)) {
+		t.Error("should contain 03:59")
+	}
+}
+
+func TestWindowsBetweenClipped(t *testing.T) {
+	r := MustParseRule("* * * * *")
+	start := time.Date(2000, 4, 28, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	windows := r.WindowsBetween(start, end)
+	if len(windows) != 1 || windows[0] != (TimeRange{start, end}) {
+		t.Errorf("%v", windows)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w := Window{Rule: MustParseRule("0 2 * * 6"), Duration: 2 * time.Hour}
+
+	if !w.Contains(time.Date(2000, 4, 29, 3, 59, 0, 0, time.UTC)) {
+		t.Error("should contain 03:59")
+	}
+	if w.Contains(time.Date(2000, 4, 29, 4, 0, 0, 0, time.UTC)) {
+		t.Error("should not contain 04:00")
+	}
+	c, ok := w.Current(time.Date(2000, 4, 29, 2, 0, 0, 0, time.UTC))
+	if !ok || c.Start != time.Date(2000, 4, 29, 2, 0, 0, 0, time.UTC) {
+		t.Errorf("%v %v", c, ok)
+	}
+
+	n := w.NextWindow(time.Date(2000, 4, 29, 2, 0, 0, 0, time.UTC))
+	e := TimeRange{time.Date(2000, 5, 6, 2, 0, 0, 0, time.UTC), time.Date(2000, 5, 6, 4, 0, 0, 0, time.UTC)}
+	if n != e {
+		t.Errorf("%v != %v", n, e)
+	}
+
+	if !w.OverlapsRange(TimeRange{time.Date(2000, 4, 29, 3, 0, 0, 0, time.UTC), time.Date(2000, 4, 29, 5, 0, 0, 0, time.UTC)}) {
+		t.Error("should overlap")
+	}
+	if w.OverlapsRange(TimeRange{time.Date(2000, 4, 29, 4, 0, 0, 0, time.UTC), time.Date(2000, 4, 29, 5, 0, 0, 0, time.UTC)}) {
+		t.Error("should not overlap")
+	}
+}
+
+func TestWindowNextOverlap(t *testing.T) {
+	// weekly saturday window against a daily backup at 03:30 lasting an hour
+	w := Window{Rule: MustParseRule("0 2 * * 6"), Duration: 2 * time.Hour}
+	o := Window{Rule: MustParseRule("30 3 * * *"), Duration: time.Hour}
+
+	tr, ok := w.NextOverlap(o, time.Date(2000, 4, 24, 0, 0, 0, 0, time.UTC))
+	e := TimeRange{time.Date(2000, 4, 29, 3, 30, 0, 0, time.UTC), time.Date(2000, 4, 29, 4, 0, 0, 0, time.UTC)}
+	if !ok || tr != e {
+		t.Errorf("%v %v != %v", tr, ok, e)
+	}
+
+	// starting inside an overlap
+	tr, ok = o.NextOverlap(w, time.Date(2000, 4, 29, 3, 45, 0, 0, time.UTC))
+	e.Start = time.Date(2000, 4, 29, 3, 45, 0, 0, time.UTC)
+	if !ok || tr != e {
+		t.Errorf("%v %v != %v", tr, ok, e)
+	}
+
+	// never overlapping
+	o = Window{Rule: MustParseRule("0 12 * * *"), Duration: time.Hour}
+	if tr, ok = w.NextOverlap(o, time.Date(2000, 4, 24, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("%v should not overlap", tr)
+	}
+}
@@ -0,0 +1,58 @@
+package ticktickrules
+
+import (
+	"time"
+)
+
+// MatchesIn is like Matches but evaluates the rule in loc, overriding any location the rule is bound to. This lets
+// a single rule be checked against several regions without binding a copy of it to each one. A nil loc is the same
+// as calling Matches.
+func (r *Rule) MatchesIn(t time.Time, loc *time.Location) bool {
+	if loc == nil {
+		return r.Matches(t)
+	}
+	t = t.In(loc)
+	m := newMoment(t)
+	return r.matchesMoment(&m, isSecondPass(t))
+}
+
+// NextFromIn is like NextAfter but evaluates the rule in loc, overriding any location the rule is bound to. The
+// result is in loc. A nil loc is the same as calling NextAfter.
+func (r *Rule) NextFromIn(from time.Time, loc *time.Location) time.Time {
+	if loc == nil {
+		return r.NextAfter(from)
+	}
+	return r.In(loc).NextAfter(from)
+}
+
+type zonedSchedule struct {
+	rule  *Rule
+	zones []*time.Location
+}
+
+// ExpandAcrossZones returns a schedule that fires at the local times of the rule in each of the given zones, for
+// example "0 9 * * MON-FRI" at 09:00 on weekdays in every region a service is deployed to. Zones that share an
+// offset fire at the same instant, which is only returned once. Times are returned in the first zone listed that
+// fires at that instant. The location the rule is bound to, if any, is ignored.
+func ExpandAcrossZones(rule *Rule, zones []*time.Location) Schedule {
+	return zonedSchedule{rule: rule, zones: zones}
+}
+
+func (z zonedSchedule) NextAfter(from time.Time) time.Time {
+	next := farFuture
+	for _, loc := range z.zones {
+		if n := z.rule.NextFromIn(from, loc); n.Before(next) {
+			next = n
+		}
+	}
+	return next
+}
+
+func (z zonedSchedule) Matches(t time.Time) bool {
+	for _, loc := range z.zones {
+		if z.rule.MatchesIn(t, loc) {
+			return true
+		}
+	}
+	return false
+}
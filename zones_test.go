@@ -0,0 +1,136 @@
+package ticktickrules
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMatchesIn(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	// 09:00 in Tokyo is 00:00 UTC, and 09:00 in London during the summer is 08:00 UTC
+	r := MustParseRule("0 9 * * *")
+	midnight := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	if !r.MatchesIn(midnight, tokyo) || r.MatchesIn(midnight, london) || r.MatchesIn(midnight, nil) {
+		t.Errorf("%s did not match as expected at %s", r, midnight)
+	}
+	if !r.MatchesIn(midnight.Add(8*time.Hour), london) || !r.MatchesIn(midnight.Add(9*time.Hour), nil) {
+		t.Errorf("%s did not match as expected in London", r)
+	}
+
+	// the override replaces the location the rule is bound to
+	bound := r.In(london)
+	if !bound.MatchesIn(midnight, tokyo) || bound.Matches(midnight) {
+		t.Errorf("%s did not match as expected at %s", bound, midnight)
+	}
+
+	// the daylight saving policies of the rule still apply
+	twice := MustParseRule("30 1 * * *").WithFallBackPolicy(FireTwice)
+	first := time.Date(2026, 10, 25, 0, 30, 0, 0, time.UTC)
+	if !twice.MatchesIn(first, london) || !twice.MatchesIn(first.Add(time.Hour), london) {
+		t.Error("30 1 * * * should have matched both 01:30s in London")
+	}
+	if MustParseRule("30 1 * * *").MatchesIn(first.Add(time.Hour), london) {
+		t.Error("30 1 * * * should have only matched the first 01:30 in London")
+	}
+}
+
+func TestNextFromIn(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skip(err)
+	}
+
+	r := MustParseRule("0 9 * * MON")
+	from := time.Date(2026, 3, 27, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		loc      *time.Location
+		expected time.Time
+	}{
+		{tokyo, time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)},
+		// the clocks go forward in London on the Sunday before
+		{london, time.Date(2026, 3, 30, 8, 0, 0, 0, time.UTC)},
+		{nil, time.Date(2026, 3, 30, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		n := r.NextFromIn(from, c.loc)
+		if !n.Equal(c.expected) {
+			t.Errorf("%v: %s != %s", c.loc, n, c.expected)
+		}
+		if c.loc != nil && n.Location() != c.loc {
+			t.Errorf("%s != %s", n.Location(), c.loc)
+		}
+	}
+	if n := r.In(tokyo).NextFromIn(from, london); !n.Equal(cases[1].expected) {
+		t.Errorf("%s != %s", n, cases[1].expected)
+	}
+}
+
+func TestMatchesInAllocations(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip(err)
+	}
+	r := MustParseRule("*/5 9-17 * * MON-FRI")
+	now := time.Date(2026, 3, 4, 10, 15, 0, 0, time.UTC)
+	if allocs := testing.AllocsPerRun(100, func() { r.MatchesIn(now, tokyo) }); allocs != 0 {
+		t.Errorf("%v != 0", allocs)
+	}
+}
+
+func TestExpandAcrossZones(t *testing.T) {
+	var zones []*time.Location
+	for _, name := range []string{"Europe/London", "Europe/Lisbon", "Asia/Tokyo", "America/New_York"} {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skip(err)
+		}
+		zones = append(zones, loc)
+	}
+
+	s := ExpandAcrossZones(MustParseRule("0 9 * * *"), zones)
+	from := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	// London and Lisbon share an offset, so 08:00 UTC is only returned once
+	expected := []time.Time{
+		time.Date(2026, 7, 1, 8, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 1, 13, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 7, 2, 8, 0, 0, 0, time.UTC),
+	}
+	for _, e := range expected {
+		from = s.NextAfter(from)
+		if !from.Equal(e) {
+			t.Errorf("%s != %s", from, e)
+		}
+		if !s.Matches(from) {
+			t.Errorf("%s should have matched", from)
+		}
+	}
+	if from.Location() != zones[0] {
+		t.Errorf("%s != %s", from.Location(), zones[0])
+	}
+	if s.Matches(time.Date(2026, 7, 1, 9, 0, 0, 0, time.UTC)) {
+		t.Error("09:00 UTC is not 09:00 in any of the zones")
+	}
+
+	// the rule's own location is ignored
+	bound := ExpandAcrossZones(MustParseRule("0 9 * * *").In(zones[2]), zones[:1])
+	if n := bound.NextAfter(time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)); !n.Equal(expected[0]) {
+		t.Errorf("%s != %s", n, expected[0])
+	}
+
+	if n := ExpandAcrossZones(MustParseRule("0 9 * * *"), nil).NextAfter(from); !n.Equal(farFuture) {
+		t.Errorf("%s != %s", n, farFuture)
+	}
+}